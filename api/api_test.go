@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"microservice/api/handler"
+	"microservice/config"
+	"microservice/pkg/audit"
+	"microservice/pkg/events"
+	"microservice/pkg/grpc_client"
+	"microservice/pkg/idempotency"
+	"microservice/pkg/jobs"
+	"microservice/pkg/logger"
+	"microservice/pkg/saga"
+	"microservice/pkg/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	gin.DefaultWriter = io.Discard
+	os.Exit(m.Run())
+}
+
+// testServer is the gateway, with every route api.New registers, in front
+// of a fake user service.
+type testServer struct {
+	*userService
+	t         *testing.T
+	router    *gin.Engine
+	cfg       config.Config
+	jobs      *jobs.Manager
+	sagas     *saga.Coordinator
+	sagaStore *saga.MemoryStore
+	audit     *audit.FileSink
+	webhooks  *webhook.Dispatcher
+	events    *events.Hub
+}
+
+// newTestServer starts a gateway; configure may change its config first.
+func newTestServer(t *testing.T, configure ...func(*config.Config)) *testServer {
+	t.Helper()
+
+	s := &testServer{t: t, userService: startUserService(t)}
+
+	s.cfg = config.Load()
+	s.cfg.UserServiceHost = "127.0.0.1"
+	s.cfg.UserServicePort = fmt.Sprint(s.addr.Port)
+	s.cfg.SagaStepTimeout = time.Second
+	s.cfg.WebhookBackoff = 10 * time.Millisecond
+	s.cfg.WebhookMaxBackoff = 20 * time.Millisecond
+	s.cfg.WebhookTimeout = time.Second
+	for _, fn := range configure {
+		fn(&s.cfg)
+	}
+
+	log := logger.New(logger.LevelPanic, "test")
+	client, err := grpc_client.New(s.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.audit, err = audit.NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.jobs = jobs.NewManager(jobs.NewMemoryStore(), log, jobs.Options{Workers: 2, QueueSize: 10, FileDir: t.TempDir()})
+	s.sagaStore = saga.NewMemoryStore()
+	s.sagas = saga.NewCoordinator(s.sagaStore, log, saga.Options{StepTimeout: s.cfg.SagaStepTimeout})
+	s.webhooks = webhook.NewDispatcher(webhook.NewMemoryStore(), log, webhook.Options{
+		Workers:     2,
+		QueueSize:   10,
+		Timeout:     s.cfg.WebhookTimeout,
+		MaxAttempts: s.cfg.WebhookMaxAttempts,
+		Backoff:     s.cfg.WebhookBackoff,
+		MaxBackoff:  s.cfg.WebhookMaxBackoff,
+	})
+	s.events = events.NewHub(s.cfg.EventBufferSize)
+
+	s.router = New(Config{
+		Logger:      log,
+		GrpcClient:  client,
+		Cfg:         s.cfg,
+		Jobs:        s.jobs,
+		Idempotency: idempotency.NewMemoryStore(),
+		Sagas:       s.sagas,
+		Audit:       s.audit,
+		Webhooks:    s.webhooks,
+		Events:      s.events,
+	})
+	return s
+}
+
+// do serves a request; headers are name, value pairs.
+func (s *testServer) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals the JSON body of w into v.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", w.Body.String(), err)
+	}
+}
+
+// eventually retries cond until it holds or a few seconds pass.
+func eventually(t *testing.T, cond func() bool, format string, args ...interface{}) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf(format, args...)
+}
+
+// bearer returns an Authorization header value for a token of subject with
+// role, signed like the auth service signs them.
+func bearer(subject, role string) string {
+	enc := base64.RawURLEncoding.EncodeToString
+	claims, _ := json.Marshal(map[string]string{"sub": subject, "role": role})
+	unsigned := enc([]byte(`{"alg":"HS256","typ":"JWT"}`)) + "." + enc(claims)
+
+	mac := hmac.New(sha256.New, handler.SigningKey)
+	mac.Write([]byte(unsigned))
+	return "Bearer " + unsigned + "." + enc(mac.Sum(nil))
+}
+
+var (
+	admin  = bearer("admin-1", "admin")
+	seller = bearer("seller-1", "seller")
+)
+
+// auditEntries returns the audit log, newest first.
+func (s *testServer) auditEntries() []audit.Entry {
+	s.t.Helper()
+
+	entries, err := s.audit.Query(context.Background(), audit.Filter{Limit: 1000})
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return entries
+}
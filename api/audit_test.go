@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/api/models"
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/audit"
+)
+
+func TestAudit(t *testing.T) {
+	s := newTestServer(t)
+	s.branches.Add(&pb.Branch{Name: "old", Phone: "+998901234567"})
+
+	w := s.do("PUT", "/updateBranch/1", `{"name":"new","phone":"+998901234567"}`, "Authorization", admin, "X-Request-ID", "req-1")
+	if w.Code != http.StatusOK || w.Header().Get("X-Request-ID") != "req-1" {
+		t.Fatalf("updateBranch = %d with X-Request-ID %q", w.Code, w.Header().Get("X-Request-ID"))
+	}
+	s.do("POST", "/createBranch", `{"name":"B","phone":"+998901234567"}`, "Authorization", seller)
+	w = s.do("DELETE", "/deleteBranch/9", "", "Authorization", admin)
+	s.do("GET", "/getbyidbranch/1", "", "Authorization", admin)
+
+	entries := s.auditEntries()
+	if len(entries) != 3 {
+		t.Fatalf("audit log has %d entries, want 3 (reads are not audited)", len(entries))
+	}
+	del, create, update := entries[0], entries[1], entries[2]
+
+	if update.Actor != "user:admin-1" || update.Role != "admin" || update.Method != "PUT" || update.Route != "/updateBranch/:id" ||
+		update.ResourceType != "branch" || update.ResourceID != "1" || update.RequestID != "req-1" || update.Status != http.StatusOK ||
+		update.Outcome != audit.OutcomeSuccess || !strings.Contains(string(update.Before), `"name":"old"`) || !strings.Contains(string(update.After), `"name":"new"`) {
+		t.Errorf("update entry = %+v", update)
+	}
+	if create.Actor != "user:seller-1" || create.ResourceID != "2" || len(create.Before) != 0 || !strings.Contains(string(create.After), `"name":"B"`) || create.RequestID == "" {
+		t.Errorf("create entry = %+v, want the created branch as after", create)
+	}
+	if del.Outcome != audit.OutcomeFailure || del.Status != w.Code || del.Error == "" || len(del.After) != 0 {
+		t.Errorf("failed delete entry = %+v", del)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"by request", "request_id=req-1", []string{update.ID}},
+		{"by actor", "actor=user:admin-1", []string{del.ID, update.ID}},
+		{"by outcome", "outcome=failure", []string{del.ID}},
+		{"by resource", "resource_type=branch&resource_id=2", []string{create.ID}},
+		{"paged", "limit=1&page=2", []string{create.ID}},
+	}
+	for _, tt := range tests {
+		var log models.AuditLog
+		w := s.do("GET", "/admin/audit?"+tt.query, "", "Authorization", admin)
+		decode(t, w, &log)
+		var ids []string
+		for _, e := range log.Entries {
+			ids = append(ids, e.ID)
+		}
+		if w.Code != http.StatusOK || strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: GET /admin/audit?%s = %d %v, want %v", tt.name, tt.query, w.Code, ids, tt.want)
+		}
+	}
+
+	for path, want := range map[string]int{
+		"/admin/audit?outcome=maybe":  http.StatusBadRequest,
+		"/admin/audit?from=yesterday": http.StatusBadRequest,
+	} {
+		if w := s.do("GET", path, "", "Authorization", admin); w.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, w.Code, want)
+		}
+	}
+	if w := s.do("GET", "/admin/audit", "", "Authorization", seller); w.Code != http.StatusForbidden {
+		t.Errorf("GET /admin/audit as a seller = %d, want 403", w.Code)
+	}
+	if w := s.do("GET", "/admin/audit", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /admin/audit anonymously = %d, want 401", w.Code)
+	}
+}
+
+func TestAuditNonJSONCreate(t *testing.T) {
+	s := newTestServer(t)
+
+	w := s.do("POST", "/createBranch", `{"name":"B","phone":"+998901234567"}`, "Authorization", admin, "Accept", "application/x-protobuf")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("createBranch = %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if e := s.auditEntries()[0]; e.ResourceID != "1" || !strings.Contains(string(e.After), `"name":"B"`) {
+		t.Errorf("create entry = %+v, want the created branch although the response is protobuf", e)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"microservice/api/models"
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/jobs"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBulk(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.BulkMaxItems = 3 })
+	s.shops.Add(&pb.Shop{Slug: "a"})
+
+	const (
+		valid   = `{"phone":"+998901234567","email":"a@example.com"}`
+		invalid = `{"phone":"123","email":"a@example.com"}`
+	)
+
+	var resp models.BulkResponse
+	w := s.do("POST", "/bulkCreateCustomer", `{"customers":[`+valid+`,`+invalid+`,`+valid+`]}`, "Authorization", admin)
+	if w.Code != http.StatusMultiStatus {
+		t.Fatalf("bulk create with an invalid item = %d %s, want 207", w.Code, w.Body)
+	}
+	decode(t, w, &resp)
+	if resp.Succeeded != 2 || resp.Failed != 1 || resp.Results[1].Success || resp.Results[1].Error.Code != http.StatusBadRequest ||
+		resp.Results[0].ID == "" || resp.Results[2].ID == "" || s.customers.Len() != 2 {
+		t.Errorf("bulk create = %+v with %d customers, want items 0 and 2 created", resp, s.customers.Len())
+	}
+
+	// Atomic requests apply nothing when an item is invalid.
+	if w := s.do("POST", "/bulkCreateCustomer?atomic=true", `{"customers":[`+valid+`,`+invalid+`]}`, "Authorization", admin); w.Code != http.StatusBadRequest || s.customers.Len() != 2 {
+		t.Errorf("atomic bulk create with an invalid item = %d %s with %d customers, want 400 and none created", w.Code, w.Body, s.customers.Len())
+	}
+	w = s.do("POST", "/bulkCreateCustomer?atomic=true", `{"customers":[`+valid+`,`+valid+`]}`, "Authorization", admin)
+	resp = models.BulkResponse{}
+	decode(t, w, &resp)
+	if w.Code != http.StatusOK || !resp.Atomic || resp.Succeeded != 2 || s.customers.Len() != 4 {
+		t.Errorf("atomic bulk create = %d %s, want both created", w.Code, w.Body)
+	}
+
+	// A failing upstream fails its items only.
+	s.customers.Fail("Delete", status.Error(codes.Unavailable, "down"))
+	w = s.do("DELETE", "/bulkDeleteCustomer", `{"ids":["1",""]}`, "Authorization", admin)
+	resp = models.BulkResponse{}
+	decode(t, w, &resp)
+	if w.Code != http.StatusMultiStatus || resp.Results[0].Error.Code != http.StatusInternalServerError || resp.Results[1].Error.Code != http.StatusBadRequest {
+		t.Errorf("bulk delete with the service down = %d %s", w.Code, w.Body)
+	}
+	s.customers.Fail("Delete", nil)
+
+	tests := []struct {
+		name, method, path, body string
+		want                     int
+	}{
+		{"no items", "POST", "/bulkCreateCustomer", `{"customers":[]}`, http.StatusBadRequest},
+		{"too many items", "POST", "/bulkCreateCustomer", `{"customers":[` + valid + `,` + valid + `,` + valid + `,` + valid + `]}`, http.StatusRequestEntityTooLarge},
+		{"atomic unsupported", "POST", "/BulkCreateSeller?atomic=true", `{"sellers":[{"name":"A","phone":"+998901234567","email":"a@example.com","shop_id":"1"}]}`, http.StatusNotImplemented},
+		{"invalid atomic", "PUT", "/bulkUpdateBranch?atomic=maybe", `{"branches":[{"id":"1"}]}`, http.StatusBadRequest},
+		{"update without id", "PUT", "/bulkUpdateCustomer", `{"customers":[` + valid + `]}`, http.StatusMultiStatus},
+		{"delete", "DELETE", "/bulkDeleteCustomer", `{"ids":["1","2"]}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := s.do(tt.method, tt.path, tt.body, "Authorization", admin); w.Code != tt.want {
+			t.Errorf("%s: %s %s = %d %s, want %d", tt.name, tt.method, tt.path, w.Code, w.Body, tt.want)
+		}
+	}
+
+	// Asynchronous requests report through their job.
+	w = s.do("POST", "/bulkCreateCustomer?async=true", `{"customers":[`+valid+`,`+invalid+`]}`, "Authorization", admin)
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusAccepted || location == "" {
+		t.Fatalf("async bulk create = %d %s, want 202", w.Code, w.Body)
+	}
+	var job jobs.Job
+	eventually(t, func() bool {
+		decode(t, s.do("GET", location, "", "Authorization", admin), &job)
+		return job.Status.Finished()
+	}, "bulk job did not finish")
+	resp = models.BulkResponse{}
+	if err := json.Unmarshal(job.Result, &resp); err != nil || job.Status != jobs.StatusSucceeded || resp.Succeeded != 1 || resp.Failed != 1 {
+		t.Errorf("async bulk job = %+v with result %s, want one item created and one failed", job, job.Result)
+	}
+}
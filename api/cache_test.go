@@ -0,0 +1,103 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+)
+
+func TestShopCache(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.CoalesceReads = false })
+	s.shops.Add(&pb.Shop{Slug: "a", NameEn: "A", NameUz: "A uz"})
+
+	w := s.do("GET", "/GetByIdShop/1", "")
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Cache-Control"), "public, max-age=") || w.Header().Get("Vary") == "" {
+		t.Fatalf("GET = %d Cache-Control=%q Vary=%q", w.Code, w.Header().Get("Cache-Control"), w.Header().Values("Vary"))
+	}
+	if !strings.Contains(strings.Join(w.Header().Values("Vary"), ","), "Accept") {
+		t.Errorf("Vary = %q, want Accept", w.Header().Values("Vary"))
+	}
+	etag := w.Header().Get("ETag")
+
+	s.do("GET", "/GetByIdShop/1", "")
+	if n := s.shops.Calls("GetByID"); n != 1 {
+		t.Errorf("GetByID called %d times for two reads, want 1", n)
+	}
+	s.do("GET", "/GetByIdShop/1", "", "Cache-Control", "no-cache")
+	if n := s.shops.Calls("GetByID"); n != 2 {
+		t.Errorf("GetByID called %d times after no-cache, want 2", n)
+	}
+
+	// Representations of the same version have their own ETags, so a copy
+	// of one does not revalidate another.
+	variants := map[string][]string{
+		"protobuf": {"/GetByIdShop/1", "Accept", "application/x-protobuf"},
+		"fields":   {"/GetByIdShop/1?fields=id"},
+		"lang":     {"/GetByIdShop/1?lang=uz"},
+	}
+	seen := map[string]string{etag: "json"}
+	for name, req := range variants {
+		w := s.do("GET", req[0], "", req[1:]...)
+		tag := w.Header().Get("ETag")
+		if other, ok := seen[tag]; ok || tag == "" {
+			t.Errorf("%s ETag %q, same as %s", name, tag, other)
+		}
+		seen[tag] = name
+
+		if w := s.do("GET", req[0], "", append(req[1:], "If-None-Match", etag)...); w.Code != http.StatusOK {
+			t.Errorf("%s with the JSON ETag = %d, want 200", name, w.Code)
+		}
+		if w := s.do("GET", req[0], "", append(req[1:], "If-None-Match", tag)...); w.Code != http.StatusNotModified {
+			t.Errorf("%s with its own ETag = %d, want 304", name, w.Code)
+		}
+	}
+
+	// Any representation's ETag works in If-Match.
+	fieldsTag := s.do("GET", "/GetByIdShop/1?fields=id", "").Header().Get("ETag")
+	w = s.do("PUT", "/UpdateShop/1", `{"slug":"a","name_en":"B","phone":"+998901234567"}`, "Authorization", admin, "If-Match", fieldsTag)
+	if w.Code != http.StatusOK {
+		t.Errorf("PUT with a fields= ETag = %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "a", NameEn: "A"})
+
+	for _, tc := range []struct {
+		path    string
+		headers []string
+		want    string
+	}{
+		{"/GetByIdShop/1", nil, "public, max-age="},
+		{"/GetByIdShop/1", []string{"Authorization", seller}, "private, max-age="},
+		{"/GetListShop", []string{"Authorization", admin}, "private, max-age="},
+		{"/GetListShop?include_deleted=true", []string{"Authorization", admin}, "no-store"},
+		{"/GetListShop?only_deleted=1", []string{"Authorization", admin}, "no-store"},
+	} {
+		w := s.do("GET", tc.path, "", tc.headers...)
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Cache-Control"), tc.want) {
+			t.Errorf("GET %s %v = %d Cache-Control=%q, want %q", tc.path, tc.headers, w.Code, w.Header().Get("Cache-Control"), tc.want)
+		}
+		if vary := strings.Join(w.Header().Values("Vary"), ","); !strings.Contains(vary, "Authorization") {
+			t.Errorf("GET %s Vary = %q, want Authorization", tc.path, vary)
+		}
+	}
+}
+
+func TestDebugVars(t *testing.T) {
+	s := newTestServer(t)
+
+	for auth, want := range map[string]int{"": http.StatusUnauthorized, seller: http.StatusForbidden, admin: http.StatusOK} {
+		var headers []string
+		if auth != "" {
+			headers = []string{"Authorization", auth}
+		}
+		if w := s.do("GET", "/debug/vars", "", headers...); w.Code != want {
+			t.Errorf("GET /debug/vars = %d, want %d", w.Code, want)
+		}
+	}
+}
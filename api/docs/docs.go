@@ -15,16 +15,18 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/CreateSeller": {
+        "/BulkCreateSeller": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for creating seller",
+                "description": "API for creating many sellers; with atomic=true all are created or none",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
                     "application/json"
@@ -32,27 +34,51 @@ const docTemplate = `{
                 "tags": [
                     "seller"
                 ],
-                "summary": "Create seller",
+                "summary": "Bulk create sellers",
                 "parameters": [
                     {
-                        "description": "seller",
-                        "name": "seller",
+                        "description": "sellers",
+                        "name": "sellers",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user_service.CreateSeller"
+                            "$ref": "#/definitions/user_service.BulkCreateSellerRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.CreateSeller"
+                            "$ref": "#/definitions/models.BulkResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ResponseError"
                         }
@@ -66,44 +92,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/CreateShop": {
-            "post": {
+        "/BulkDeleteSeller": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for creating shop",
+                "description": "API for deleting many sellers by ID; with atomic=true all are deleted or none",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "shop"
+                    "seller"
                 ],
-                "summary": "Create shop",
+                "summary": "Bulk delete sellers",
                 "parameters": [
                     {
-                        "description": "shop",
-                        "name": "shop",
+                        "description": "seller IDs",
+                        "name": "ids",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user_service.CreateShop"
+                            "$ref": "#/definitions/user_service.BulkDeleteSellerRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Shop"
+                            "$ref": "#/definitions/models.BulkResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ResponseError"
                         }
@@ -117,44 +169,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/CreateUser": {
-            "post": {
+        "/BulkUpdateSeller": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for creating user",
+                "description": "API for updating many sellers by ID; with atomic=true all are updated or none",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "seller"
                 ],
-                "summary": "Create user",
+                "summary": "Bulk update sellers",
                 "parameters": [
                     {
-                        "description": "user",
-                        "name": "order",
+                        "description": "sellers",
+                        "name": "sellers",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user_service.CreateUs"
+                            "$ref": "#/definitions/user_service.BulkUpdateSellerRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.CreateUs"
+                            "$ref": "#/definitions/models.BulkResponse"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ResponseError"
                         }
@@ -168,38 +246,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/DeleteSeller/{id}": {
-            "delete": {
+        "/CreateSeller": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for deleting a seller by ID",
+                "description": "API for creating seller",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "seller"
                 ],
-                "summary": "Delete a seller by ID",
+                "summary": "Create seller",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "seller ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "seller",
+                        "name": "seller",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateSeller"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Empty2"
+                            "$ref": "#/definitions/user_service.CreateSeller"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
                         }
                     },
                     "404": {
@@ -217,38 +307,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/DeleteShop/{id}": {
-            "delete": {
+        "/CreateShop": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for deleting a shop by ID",
+                "description": "API for creating shop. Without a slug one is generated from name_en (or name_uz); a slug that is taken is rejected with suggestions",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "shop"
                 ],
-                "summary": "Delete a shop by ID",
+                "summary": "Create shop",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "shop ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "shop",
+                        "name": "shop",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateShop"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Empty4"
+                            "$ref": "#/definitions/user_service.Shop"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
                         }
                     },
                     "404": {
@@ -257,6 +359,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.SlugAvailability"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -266,38 +374,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/DeleteUser/{id}": {
-            "delete": {
+        "/CreateUser": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for deleting a user by ID",
+                "description": "API for creating user",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Delete a user by ID",
+                "summary": "Create user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "user ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "user",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateUs"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Empty"
+                            "$ref": "#/definitions/user_service.CreateUs"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
                         }
                     },
                     "404": {
@@ -315,24 +435,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/GetByIdSeller/{id}": {
-            "get": {
+        "/DeleteSeller/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting a single seller by ID",
+                "description": "API for deleting a seller by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "seller"
                 ],
-                "summary": "Get a single seller by ID",
+                "summary": "Delete a seller by ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -340,13 +464,19 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the seller must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Seller"
+                            "$ref": "#/definitions/user_service.Empty2"
                         }
                     },
                     "404": {
@@ -355,6 +485,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -364,24 +500,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/GetByIdShop/{id}": {
-            "get": {
+        "/DeleteShop/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting a single shop by ID",
+                "description": "API for deleting a shop by ID. A shop that still has sellers or branches is not deleted (409 lists them) unless cascade=true, which deletes them after the shop (restoring it if they cannot be deleted); SHOP_DELETE_CASCADE sets the default",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "shop"
                 ],
-                "summary": "Get a single shop by ID",
+                "summary": "Delete a shop by ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -389,13 +529,31 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also delete the shop's sellers and branches",
+                        "name": "cascade",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the shop must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Shop"
+                            "$ref": "#/definitions/user_service.Empty4"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
                         }
                     },
                     "404": {
@@ -404,6 +562,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShopDependents"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -413,24 +583,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/GetByIdUser/{id}": {
-            "get": {
+        "/DeleteUser/{id}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting a single user by ID",
+                "description": "API for deleting a user by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "user"
                 ],
-                "summary": "Get a single user by ID",
+                "summary": "Delete a user by ID",
                 "parameters": [
                     {
                         "type": "string",
@@ -438,13 +612,19 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the user must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Us"
+                            "$ref": "#/definitions/user_service.Empty"
                         }
                     },
                     "404": {
@@ -453,6 +633,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -462,101 +648,87 @@ const docTemplate = `{
                 }
             }
         },
-        "/GetListBranch": {
+        "/ExportSeller": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting list branch",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "API for exporting sellers as CSV or XLSX, streamed page by page",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
-                    "branch"
+                    "seller"
                 ],
-                "summary": "Get List Branch",
+                "summary": "Export sellers",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branches",
-                        "name": "branch",
+                        "description": "csv (default) or xlsx",
+                        "name": "format",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "comma separated fields, default: id, name, phone, email, shop_id, created_at",
+                        "name": "columns",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "header language: uz, ru or en (default)",
+                        "name": "lang",
                         "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/user_service.GetListBranchResponse"
-                        }
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.ResponseError"
-                        }
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.ResponseError"
-                        }
-                    }
-                }
-            }
-        },
-        "/GetListSeller": {
-            "get": {
-                "security": [
                     {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "API for getting list seller",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "seller"
-                ],
-                "summary": "Get List seller",
-                "parameters": [
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "sellers",
-                        "name": "seller",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by shop ID",
+                        "name": "shop_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "build the file in a background job, which needs an access token",
+                        "name": "async",
                         "in": "query"
                     }
                 ],
@@ -564,11 +736,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.GetListSellerResponse"
+                            "type": "file"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ResponseError"
                         }
@@ -582,41 +760,87 @@ const docTemplate = `{
                 }
             }
         },
-        "/GetListShop": {
+        "/ExportShop": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting list of shops",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "API for exporting shops as CSV or XLSX, streamed page by page",
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
                 ],
                 "tags": [
                     "shop"
                 ],
-                "summary": "Get list of shops",
+                "summary": "Export shops",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "shops",
-                        "name": "shop",
+                        "description": "csv (default) or xlsx",
+                        "name": "format",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "comma separated fields, default: id, slug, name_uz, name_ru, name_en, phone, location, currency, payment_types, created_at",
+                        "name": "columns",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "header language: uz, ru or en (default)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "build the file in a background job, which needs an access token",
+                        "name": "async",
                         "in": "query"
                     }
                 ],
@@ -624,11 +848,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.GetListShopResponse"
+                            "type": "file"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/models.ResponseError"
                         }
@@ -642,41 +872,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/GetListUser": {
+        "/GetByIdSeller/{id}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting list user",
+                "description": "API for getting a single seller by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "user"
+                    "seller"
                 ],
-                "summary": "Get List user",
+                "summary": "Get a single seller by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "system_users",
-                        "name": "system_user",
-                        "in": "query"
+                        "description": "seller ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "etag of the cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
                         "in": "query"
                     }
                 ],
@@ -684,9 +925,12 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.GetListUsResponse"
+                            "$ref": "#/definitions/user_service.Seller"
                         }
                     },
+                    "304": {
+                        "description": "not modified"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -702,49 +946,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/UpdateSeller/{id}": {
-            "put": {
+        "/GetByIdShop/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for updating a seller by ID",
+                "description": "API for getting a single shop by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "seller"
+                    "shop"
                 ],
-                "summary": "Update a seller by ID",
+                "summary": "Get a single shop by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "seller ID",
+                        "description": "shop ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "seller",
-                        "name": "seller",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user_service.UpdateSeller"
-                        }
+                        "type": "string",
+                        "description": "etag of the cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.UpdateSeller"
+                            "$ref": "#/definitions/user_service.Shop"
                         }
                     },
+                    "304": {
+                        "description": "not modified"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -760,49 +1020,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/UpdateShop/{id}": {
-            "put": {
+        "/GetByIdUser/{id}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for updating a shop by ID",
+                "description": "API for getting a single user by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "shop"
+                    "user"
                 ],
-                "summary": "Update a shop by ID",
+                "summary": "Get a single user by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "shop ID",
+                        "description": "user ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "shop",
-                        "name": "shop",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user_service.UpdateShop"
-                        }
+                        "type": "string",
+                        "description": "etag of the cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Shop"
+                            "$ref": "#/definitions/user_service.Us"
                         }
                     },
+                    "304": {
+                        "description": "not modified"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -818,49 +1094,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/UpdateUser/{id}": {
-            "put": {
+        "/GetBySlugShop/{slug}": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for updating a user by ID",
+                "description": "API for resolving storefront URLs: getting a single shop by its slug",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "user"
+                    "shop"
                 ],
-                "summary": "Update a user by ID",
+                "summary": "Get a single shop by slug",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "user ID",
-                        "name": "id",
+                        "description": "shop slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "user",
-                        "name": "category",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user_service.UpdateUs"
-                        }
+                        "type": "string",
+                        "description": "etag of the cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Us"
+                            "$ref": "#/definitions/user_service.Shop"
                         }
                     },
+                    "304": {
+                        "description": "not modified"
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -876,91 +1168,119 @@ const docTemplate = `{
                 }
             }
         },
-        "/createBranch": {
-            "post": {
+        "/GetListBranch": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for creating branch",
+                "description": "API for getting list branch",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
                     "branch"
                 ],
-                "summary": "Create branch",
+                "summary": "Get List Branch",
                 "parameters": [
                     {
-                        "description": "branch",
-                        "name": "branch",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user_service.CreateBranch"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/user_service.CreateBranch"
-                        }
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.ResponseError"
-                        }
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/models.ResponseError"
-                        }
-                    }
-                }
-            }
-        },
-        "/createCustomer": {
-            "post": {
-                "security": [
                     {
-                        "ApiKeyAuth": []
-                    }
-                ],
-                "description": "API for creating customer",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "customer"
-                ],
-                "summary": "Create customer",
-                "parameters": [
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
                     {
-                        "description": "customer",
-                        "name": "order",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/user_service.CreateCustomer"
-                        }
+                        "type": "string",
+                        "description": "cursor from next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "filter by active flag",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by shop ID",
+                        "name": "shop_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only branches open (true) or closed (false) right now; cannot be combined with cursor, and refused with 400 when more than BRANCH_SCAN_LIMIT branches match the other filters",
+                        "name": "open_now",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.CreateCustomer"
+                            "$ref": "#/definitions/user_service.GetListBranchResponse"
                         }
                     },
                     "404": {
@@ -978,38 +1298,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/deleteBranch/{id}": {
-            "delete": {
+        "/GetListSeller": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for deleting a branch by ID",
+                "description": "API for getting list seller",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "branch"
+                    "seller"
                 ],
-                "summary": "Delete a branch by ID",
+                "summary": "Get List seller",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branch ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by shop ID",
+                        "name": "shop_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Empty3"
+                            "$ref": "#/definitions/user_service.GetListSellerResponse"
                         }
                     },
                     "404": {
@@ -1027,38 +1416,107 @@ const docTemplate = `{
                 }
             }
         },
-        "/deleteCustomer/{id}": {
-            "delete": {
+        "/GetListShop": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for deleting a customer by ID",
+                "description": "API for getting list of shops",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "customer"
+                    "shop"
                 ],
-                "summary": "Delete a customer by ID",
+                "summary": "Get list of shops",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "customer ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by currency",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Empty"
+                            "$ref": "#/definitions/user_service.GetListShopResponse"
                         }
                     },
                     "404": {
@@ -1076,47 +1534,116 @@ const docTemplate = `{
                 }
             }
         },
-        "/getbyidbranch/{id}": {
+        "/GetListUser": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting a single branch by ID",
+                "description": "API for getting list user",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "branch"
+                    "user"
                 ],
-                "summary": "Get a single branch by ID",
+                "summary": "Get List user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branch ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/user_service.Branch"
-                        }
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {
-                            "$ref": "#/definitions/models.ResponseError"
-                        }
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
                     },
-                    "500": {
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.GetListUsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ResponseError"
@@ -1125,44 +1652,56 @@ const docTemplate = `{
                 }
             }
         },
-        "/getbyidcustomer/{id}": {
-            "get": {
+        "/ImportSeller": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting a single category by ID",
+                "description": "API for importing sellers from a CSV file whose header names CreateSeller fields (or exported column titles). mode=dry_run returns a row-by-row validation report; mode=commit creates the rows in a background job polled at /jobs/{id}",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "customer"
+                    "seller"
                 ],
-                "summary": "Get a single customer by ID",
+                "summary": "Import sellers from CSV",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "customer ID",
-                        "name": "id",
-                        "in": "path",
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "dry_run (default) or commit, which runs in a background job and needs an access token",
+                        "name": "mode",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Customer"
+                            "$ref": "#/definitions/models.ImportReport"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/models.ResponseError"
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
                         }
                     },
                     "500": {
@@ -1174,41 +1713,38 @@ const docTemplate = `{
                 }
             }
         },
-        "/getlistcustomer": {
+        "/SuggestShopSlug": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for getting list customer",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "API for checking whether a slug (or the slug generated from name) is free; when it is taken, free variants are suggested",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "customer"
+                    "shop"
                 ],
-                "summary": "Get List Customer",
+                "summary": "Check a shop slug and suggest free ones",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "customers",
-                        "name": "customer",
+                        "description": "desired slug",
+                        "name": "slug",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "page",
-                        "name": "page",
+                        "type": "string",
+                        "description": "shop name to generate the slug from when slug is empty",
+                        "name": "name",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
+                        "type": "string",
+                        "description": "ID of the shop being edited, whose own slug counts as free",
+                        "name": "id",
                         "in": "query"
                     }
                 ],
@@ -1216,7 +1752,81 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.GetListCustomerResponse"
+                            "$ref": "#/definitions/models.SlugAvailability"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/UpdateSeller/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for updating a seller by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "seller"
+                ],
+                "summary": "Update a seller by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "seller ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "seller",
+                        "name": "seller",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.UpdateSeller"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the seller must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.UpdateSeller"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
                         }
                     },
                     "404": {
@@ -1225,6 +1835,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1234,47 +1850,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/updateBranch/{id}": {
+        "/UpdateShop/{id}": {
             "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for updating a branch by ID",
+                "description": "API for updating a shop by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "branch"
+                    "shop"
                 ],
-                "summary": "Update a branch by ID",
+                "summary": "Update a shop by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "branch ID",
+                        "description": "shop ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "branch",
-                        "name": "branch",
+                        "description": "shop",
+                        "name": "shop",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user_service.UpdateBranch"
+                            "$ref": "#/definitions/user_service.UpdateShop"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the shop must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Branch"
+                            "$ref": "#/definitions/user_service.Shop"
                         }
                     },
                     "404": {
@@ -1283,6 +1909,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.SlugAvailability"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1292,47 +1930,63 @@ const docTemplate = `{
                 }
             }
         },
-        "/updateCustomer/{id}": {
+        "/UpdateUser/{id}": {
             "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "API for updating a customer by ID",
+                "description": "API for updating a user by ID",
                 "consumes": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "produces": [
-                    "application/json"
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
                 ],
                 "tags": [
-                    "customer"
+                    "user"
                 ],
-                "summary": "Update a customer by ID",
+                "summary": "Update a user by ID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "customer ID",
+                        "description": "user ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "customer",
+                        "description": "user",
                         "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/user_service.UpdateCustomer"
+                            "$ref": "#/definitions/user_service.UpdateUs"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the user must still have",
+                        "name": "If-Match",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/user_service.Customer"
+                            "$ref": "#/definitions/user_service.Us"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
                         }
                     },
                     "404": {
@@ -1341,6 +1995,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ResponseError"
                         }
                     },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -1349,50 +2009,3973 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "models.ResponseError": {
-            "type": "object",
-            "properties": {
-                "error": {}
-            }
         },
-        "user_service.Branch": {
-            "type": "object",
-            "properties": {
-                "active": {
-                    "type": "boolean"
-                },
-                "address": {
+        "/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for reading recorded mutating requests, newest first; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "audit"
+                ],
+                "summary": "Query the audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user:\u003csubject\u003e, token:\u003chash\u003e or anonymous",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "customer, user, seller, branch, shop, onboarding or job",
+                        "name": "resource_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Request-ID of the request",
+                        "name": "request_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "success or failure",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "at or after, 2006-01-02 15:04:05 UTC",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "before, 2006-01-02 15:04:05 UTC",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AuditLog"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhook-deliveries/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for reading a delivery with its event and attempts; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get a webhook delivery",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhook-deliveries/{id}/redeliver": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for sending the event of a delivery again; the new delivery is returned and sent in the background. Admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Redeliver a webhook event",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "delivery ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Delivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for listing the registered webhook endpoints, without their secrets; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookList"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for registering an endpoint for change events (\u003cresource\u003e.\u003caction\u003e with resource customer, user, seller, branch or shop and action created, updated, deleted, restored or purged; \u003cresource\u003e.* or * for all). Deliveries are POSTed as JSON with X-Webhook-ID, X-Webhook-Event, X-Webhook-Timestamp and X-Webhook-Signature: sha256=\u003chex HMAC-SHA256 of \"\u003ctimestamp\u003e.\u003cbody\u003e\" with the secret\u003e. The secret is generated unless given and is only returned here; admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Register a webhook",
+                "parameters": [
+                    {
+                        "description": "endpoint",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Endpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for reading a webhook endpoint, without its secret; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Get a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Endpoint"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for replacing the url, events, description and active flag of a webhook endpoint; the secret is only changed when one is given. Admin only",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Update a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "endpoint",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/webhook.Endpoint"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for deleting a webhook endpoint and its deliveries; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "Delete a webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for listing the deliveries of a webhook endpoint with every attempt, newest first; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhook"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "webhook ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WebhookDeliveries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/branches/nearby": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for finding the branches within radius meters of lat/lng, nearest first, with their distance in meters. Branches without coordinates are never returned",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Find branches near a point",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "description": "latitude",
+                        "name": "lat",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "longitude",
+                        "name": "lng",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "search radius in meters, default 5000, at most 100000",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "filter by active flag",
+                        "name": "active",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.GetListBranchResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulkCreateBranch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for creating many branches; with atomic=true all are created or none",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Bulk create branches",
+                "parameters": [
+                    {
+                        "description": "branches",
+                        "name": "branches",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.BulkCreateBranchRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulkCreateCustomer": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for creating many customers; with atomic=true all are created or none",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Bulk create customers",
+                "parameters": [
+                    {
+                        "description": "customers",
+                        "name": "customers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.BulkCreateCustomerRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulkDeleteBranch": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for deleting many branches by ID; with atomic=true all are deleted or none",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Bulk delete branches",
+                "parameters": [
+                    {
+                        "description": "branch IDs",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.BulkDeleteBranchRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulkDeleteCustomer": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for deleting many customers by ID; with atomic=true all are deleted or none",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Bulk delete customers",
+                "parameters": [
+                    {
+                        "description": "customer IDs",
+                        "name": "ids",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.BulkDeleteCustomerRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulkUpdateBranch": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for updating many branches by ID; with atomic=true all are updated or none",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Bulk update branches",
+                "parameters": [
+                    {
+                        "description": "branches",
+                        "name": "branches",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.BulkUpdateBranchRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/bulkUpdateCustomer": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for updating many customers by ID; with atomic=true all are updated or none",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Bulk update customers",
+                "parameters": [
+                    {
+                        "description": "customers",
+                        "name": "customers",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.BulkUpdateCustomerRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "all-or-nothing mode",
+                        "name": "atomic",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "207": {
+                        "description": "Multi-Status",
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/createBranch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for creating branch",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Create branch",
+                "parameters": [
+                    {
+                        "description": "branch",
+                        "name": "branch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateBranch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateBranch"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/createCustomer": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for creating customer",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Create customer",
+                "parameters": [
+                    {
+                        "description": "customer",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateCustomer"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.CreateCustomer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/debug/vars": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for reading the expvar counters, such as memory statistics and the hit rates of the read caches; admin only",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "debug"
+                ],
+                "summary": "Read runtime and cache counters",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/deleteBranch/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for deleting a branch by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Delete a branch by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the branch must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty3"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/deleteCustomer/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for deleting a customer by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Delete a customer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the customer must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/exportCustomer": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for exporting customers as CSV or XLSX, streamed page by page",
+                "produces": [
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Export customers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "csv (default) or xlsx",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields, default: id, firstname, lastname, phone, email, gender, date_of_birth, languange, created_at",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "header language: uz, ru or en (default)",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by gender",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "build the file in a background job, which needs an access token",
+                        "name": "async",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/getbyidbranch/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for getting a single branch by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Get a single branch by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Branch"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/getbyidcustomer/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for getting a single category by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Get a single customer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag of the cached copy",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Customer"
+                        }
+                    },
+                    "304": {
+                        "description": "not modified"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/getlistcustomer": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for getting list customer",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Get List Customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "search",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "cursor from next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "field:asc|desc, comma separated",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created at or after, 2006-01-02 15:04:05",
+                        "name": "created_at_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created before, 2006-01-02 15:04:05",
+                        "name": "created_at_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "also list soft-deleted records; admin only",
+                        "name": "include_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "list only soft-deleted records; admin only",
+                        "name": "only_deleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter by gender",
+                        "name": "gender",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.GetListCustomerResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/graphql": {
+            "post": {
+                "description": "GraphQL over the customers, users, sellers, branches and shops, with their proto field names. Queries: customer(id), customers(page, limit, ...) and so on for user, seller, branch and shop; shops have sellers(limit) and branches(limit), which are read once per shop and level, and sellers and branches their shop. Mutations: create\u003cType\u003e(input), update\u003cType\u003e(input) and delete\u003cType\u003e(id, expected_updated_at), with cascade on deleteShop. Queries deeper than GRAPHQL_MAX_DEPTH or more complex than GRAPHQL_MAX_COMPLEXITY are rejected; every field costs 1 and the fields below a list count once per item its limit allows. GET /graphql takes ?query= for queries, and serves GraphiQL without a query in debug mode",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL",
+                "parameters": [
+                    {
+                        "description": "query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLResponse"
+                        }
+                    },
+                    "405": {
+                        "description": "Method Not Allowed",
+                        "schema": {
+                            "$ref": "#/definitions/models.GraphQLResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/importBranch": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for importing branches from a CSV file whose header names CreateBranch fields (or exported column titles). mode=dry_run returns a row-by-row validation report; mode=commit creates the rows in a background job polled at /jobs/{id}",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Import branches from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "dry_run (default) or commit, which runs in a background job and needs an access token",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/importCustomer": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for importing customers from a CSV file whose header names CreateCustomer fields (or exported column titles). mode=dry_run returns a row-by-row validation report; mode=commit creates the rows in a background job polled at /jobs/{id}",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Import customers from CSV",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "CSV file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "dry_run (default) or commit, which runs in a background job and needs an access token",
+                        "name": "mode",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for polling the status, progress and result of a background job. Jobs are visible to the caller that submitted them and to admins",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Get a background job by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for cancelling a pending or running background job. Items already processed are not rolled back",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Cancel a background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/jobs.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/download": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for downloading the file produced by a finished job, such as an asynchronous export",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "job"
+                ],
+                "summary": "Download the result of a background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/updateBranch/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for updating a branch by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Update a branch by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "branch",
+                        "name": "branch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.UpdateBranch"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the branch must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Branch"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/updateCustomer/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for updating a customer by ID",
+                "consumes": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Update a customer by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "customer",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/user_service.UpdateCustomer"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the customer must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Customer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/branches/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for permanently deleting a branch; admin only. The branch is gone for good and cannot be restored",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Purge a branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the branch ID again",
+                        "name": "confirm",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty3"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/customers/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for permanently deleting a customer; admin only. The customer is gone for good and cannot be restored",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Purge a customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the customer ID again",
+                        "name": "confirm",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/sellers/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for permanently deleting a seller; admin only. The seller is gone for good and cannot be restored",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "seller"
+                ],
+                "summary": "Purge a seller",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "seller ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the seller ID again",
+                        "name": "confirm",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty2"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/shops/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for permanently deleting a shop; admin only. The shop is gone for good and cannot be restored. A shop that still has sellers or branches, deleted ones included, is not purged (409)",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shop"
+                ],
+                "summary": "Purge a shop",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "shop ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the shop ID again",
+                        "name": "confirm",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty4"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShopDependents"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/admin/users/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for permanently deleting a user; admin only. The user is gone for good and cannot be restored",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Purge a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the user ID again",
+                        "name": "confirm",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Empty1"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/branches/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for undoing the soft delete of a branch",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "branch"
+                ],
+                "summary": "Restore a deleted branch",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "branch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the deleted branch must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Branch"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/customers/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for undoing the soft delete of a customer",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "customer"
+                ],
+                "summary": "Restore a deleted customer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "customer ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the deleted customer must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Customer"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/events": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Server-Sent Events stream of created, updated, deleted, restored and purged resources. Every event is sent as a message whose id is the event id and whose data is the event JSON. Clients resuming with Last-Event-ID (or ?last_event_id=) first get the buffered events after it; when some were already dropped from the buffer a \"reset\" event is sent so that the client reloads its data. A \": heartbeat\" comment is sent when the stream is idle. Admins see every resource, other callers shops, sellers and branches. The token may be passed as ?access_token= for EventSource clients",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "event"
+                ],
+                "summary": "Stream entity changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated event types, \u003cresource\u003e.* or *, e.g. shop.*,seller.created",
+                        "name": "events",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "resume after this event id, if the Last-Event-ID header is not set",
+                        "name": "last_event_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "access token, if the Authorization header is not set",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/events/ws": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "WebSocket carrying the events of /v1/events for the subscriptions the client makes at runtime. Client messages: {\"type\":\"subscribe\",\"id\":\"s1\",\"events\":[\"seller.*\"],\"shop_id\":\"42\"} (events as in /v1/events, all when empty; resource_id selects one resource, shop_id a shop and its sellers and branches), {\"type\":\"unsubscribe\",\"id\":\"s1\"} and {\"type\":\"ping\"}. Server messages: subscribed, unsubscribed, pong, error (with id and error) and {\"type\":\"event\",\"subscriptions\":[\"s1\"],\"event\":{...}}. Admins may subscribe to every resource, other callers to shops, sellers and branches. A client that falls behind is disconnected with close code 1013 and should reconnect and reload. The token may be passed as ?access_token=",
+                "tags": [
+                    "event"
+                ],
+                "summary": "Subscribe to entity changes over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "access token, if the Authorization header is not set",
+                        "name": "access_token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/onboarding": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for creating a shop, its seller and the seller's system user (role seller) in one go. The steps run as a saga: if one fails the records already created are deleted and the response is 422 (status compensated) or, when a deletion failed too, 500 (status failed, see steps for what is left). The saga state is kept and can be read at /v1/onboarding/{id}",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "Onboard a merchant",
+                "parameters": [
+                    {
+                        "description": "shop, seller and user",
+                        "name": "onboarding",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.OnboardingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/saga.Saga"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.SlugAvailability"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/saga.Saga"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/saga.Saga"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/onboarding/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for reading the step-by-step state of an onboarding saga",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "onboarding"
+                ],
+                "summary": "Get a merchant onboarding",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "onboarding ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/saga.Saga"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/sellers/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for undoing the soft delete of a seller whose shop still exists; restore the shop first otherwise",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "seller"
+                ],
+                "summary": "Restore a deleted seller",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "seller ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the deleted seller must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Seller"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/shops/{id}/full": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for getting a shop, its sellers and its branches in one document. The three are fetched in parallel; a section whose call failed carries an error instead of data and partial is set. Sellers and branches are the first MAX_PAGE_SIZE of each, count tells how many there are",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "shop"
+                ],
+                "summary": "Get a shop with its sellers and branches",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "shop ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "uz, ru or en; collapses name_* and description_*",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ShopDetail"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/shops/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for undoing the soft delete of a shop",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "shop"
+                ],
+                "summary": "Restore a deleted shop",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "shop ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the deleted shop must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Shop"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/users/{id}/restore": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "API for undoing the soft delete of a user",
+                "produces": [
+                    "application/json",
+                    "application/x-protobuf",
+                    "application/msgpack"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Restore a deleted user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "etag the deleted user must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/user_service.Us"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "412": {
+                        "description": "Precondition Failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ResponseError"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "audit.Entry": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "client_ip": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "outcome": {
+                    "$ref": "#/definitions/audit.Outcome"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "route": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
+        "audit.Outcome": {
+            "type": "string",
+            "enum": [
+                "success",
+                "failure"
+            ],
+            "x-enum-varnames": [
+                "OutcomeSuccess",
+                "OutcomeFailure"
+            ]
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "object": {
+                    "type": "object"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.Job": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "file": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "processed": {
+                    "type": "integer"
+                },
+                "result": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/jobs.Status"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "jobs.Status": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "succeeded",
+                "failed",
+                "cancelled"
+            ],
+            "x-enum-varnames": [
+                "StatusPending",
+                "StatusRunning",
+                "StatusSucceeded",
+                "StatusFailed",
+                "StatusCancelled"
+            ]
+        },
+        "models.AuditLog": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/audit.Entry"
+                    }
+                }
+            }
+        },
+        "models.BulkItemResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/models.ErrorWithDescription"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.BulkResponse": {
+            "type": "object",
+            "properties": {
+                "atomic": {
+                    "type": "boolean"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkItemResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ErrorWithDescription": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GraphQLError": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "models.GraphQLRequest": {
+            "type": "object",
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": true
+                }
+            }
+        },
+        "models.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GraphQLError"
+                    }
+                }
+            }
+        },
+        "models.ImportReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "job_id": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportRow"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ImportRow": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "valid": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.OnboardingRequest": {
+            "type": "object",
+            "properties": {
+                "seller": {
+                    "$ref": "#/definitions/user_service.CreateSeller"
+                },
+                "shop": {
+                    "$ref": "#/definitions/user_service.CreateShop"
+                },
+                "user": {
+                    "$ref": "#/definitions/user_service.CreateUs"
+                }
+            }
+        },
+        "models.ResponseError": {
+            "type": "object",
+            "properties": {
+                "error": {}
+            }
+        },
+        "models.ShopDependents": {
+            "type": "object",
+            "properties": {
+                "branch_count": {
+                    "type": "integer"
+                },
+                "branch_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "code": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "seller_count": {
+                    "type": "integer"
+                },
+                "seller_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "shop_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ShopDetail": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "$ref": "#/definitions/models.ShopDetailSection"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "partial": {
+                    "type": "boolean"
+                },
+                "sellers": {
+                    "$ref": "#/definitions/models.ShopDetailSection"
+                },
+                "shop": {
+                    "$ref": "#/definitions/models.ShopDetailSection"
+                }
+            }
+        },
+        "models.ShopDetailSection": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "object"
+                },
+                "error": {
+                    "$ref": "#/definitions/models.ErrorWithDescription"
+                }
+            }
+        },
+        "models.SlugAvailability": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean"
+                },
+                "slug": {
+                    "type": "string"
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.WebhookDeliveries": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Delivery"
+                    }
+                }
+            }
+        },
+        "models.WebhookList": {
+            "type": "object",
+            "properties": {
+                "endpoints": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Endpoint"
+                    }
+                }
+            }
+        },
+        "models.WebhookRequest": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "saga.Saga": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "input": {
+                    "type": "object"
+                },
+                "status": {
+                    "$ref": "#/definitions/saga.Status"
+                },
+                "steps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/saga.StepState"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "saga.Status": {
+            "type": "string",
+            "enum": [
+                "running",
+                "compensating",
+                "succeeded",
+                "compensated",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "StatusRunning",
+                "StatusCompensating",
+                "StatusSucceeded",
+                "StatusCompensated",
+                "StatusFailed"
+            ]
+        },
+        "saga.StepState": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/saga.StepStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "saga.StepStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "done",
+                "failed",
+                "compensated",
+                "compensation_failed"
+            ],
+            "x-enum-varnames": [
+                "StepPending",
+                "StepRunning",
+                "StepDone",
+                "StepFailed",
+                "StepCompensated",
+                "StepCompensationFailed"
+            ]
+        },
+        "user_service.Branch": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "address": {
+                    "type": "string"
+                },
+                "close_time": {
+                    "type": "string"
+                },
+                "coordinates": {
+                    "$ref": "#/definitions/user_service.GeoPoint"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted_at": {
+                    "type": "integer"
+                },
+                "distance": {
+                    "type": "number"
+                },
+                "holidays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.BranchHoliday"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_open_now": {
+                    "type": "boolean"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "next_open_at": {
+                    "type": "string"
+                },
+                "open_time": {
                     "type": "string"
                 },
-                "close_time": {
+                "phone": {
                     "type": "string"
                 },
-                "created_at": {
-                    "type": "string"
+                "schedule": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.BranchHours"
+                    }
                 },
-                "deleted_at": {
-                    "type": "integer"
+                "shop_id": {
+                    "type": "string"
                 },
-                "id": {
+                "timezone": {
                     "type": "string"
                 },
-                "location": {
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "user_service.BranchHoliday": {
+            "type": "object",
+            "properties": {
+                "close_time": {
                     "type": "string"
                 },
-                "name": {
+                "closed": {
+                    "type": "boolean"
+                },
+                "date": {
                     "type": "string"
                 },
                 "open_time": {
                     "type": "string"
-                },
-                "phone": {
+                }
+            }
+        },
+        "user_service.BranchHours": {
+            "type": "object",
+            "properties": {
+                "close_time": {
                     "type": "string"
                 },
-                "updated_at": {
+                "closed": {
+                    "type": "boolean"
+                },
+                "open_time": {
                     "type": "string"
+                },
+                "weekday": {
+                    "type": "integer"
+                }
+            }
+        },
+        "user_service.BulkCreateBranchRequest": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.CreateBranch"
+                    }
+                }
+            }
+        },
+        "user_service.BulkCreateCustomerRequest": {
+            "type": "object",
+            "properties": {
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.CreateCustomer"
+                    }
+                }
+            }
+        },
+        "user_service.BulkCreateSellerRequest": {
+            "type": "object",
+            "properties": {
+                "sellers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.CreateSeller"
+                    }
+                }
+            }
+        },
+        "user_service.BulkDeleteBranchRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "user_service.BulkDeleteCustomerRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "user_service.BulkDeleteSellerRequest": {
+            "type": "object",
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "user_service.BulkUpdateBranchRequest": {
+            "type": "object",
+            "properties": {
+                "branches": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.UpdateBranch"
+                    }
+                }
+            }
+        },
+        "user_service.BulkUpdateCustomerRequest": {
+            "type": "object",
+            "properties": {
+                "customers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.UpdateCustomer"
+                    }
+                }
+            }
+        },
+        "user_service.BulkUpdateSellerRequest": {
+            "type": "object",
+            "properties": {
+                "sellers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.UpdateSeller"
+                    }
                 }
             }
         },
@@ -1408,6 +5991,15 @@ const docTemplate = `{
                 "close_time": {
                     "type": "string"
                 },
+                "coordinates": {
+                    "$ref": "#/definitions/user_service.GeoPoint"
+                },
+                "holidays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.BranchHoliday"
+                    }
+                },
                 "location": {
                     "type": "string"
                 },
@@ -1419,6 +6011,18 @@ const docTemplate = `{
                 },
                 "phone": {
                     "type": "string"
+                },
+                "schedule": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.BranchHours"
+                    }
+                },
+                "shop_id": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
                 }
             }
         },
@@ -1564,6 +6168,9 @@ const docTemplate = `{
         "user_service.Empty": {
             "type": "object"
         },
+        "user_service.Empty1": {
+            "type": "object"
+        },
         "user_service.Empty2": {
             "type": "object"
         },
@@ -1573,6 +6180,17 @@ const docTemplate = `{
         "user_service.Empty4": {
             "type": "object"
         },
+        "user_service.GeoPoint": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
         "user_service.GetListBranchResponse": {
             "type": "object",
             "properties": {
@@ -1584,20 +6202,26 @@ const docTemplate = `{
                 },
                 "count": {
                     "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
                 }
             }
         },
         "user_service.GetListCustomerResponse": {
             "type": "object",
             "properties": {
-                "Count": {
+                "count": {
                     "type": "integer"
                 },
-                "Customers": {
+                "customers": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/user_service.Customer"
                     }
+                },
+                "next_cursor": {
+                    "type": "string"
                 }
             }
         },
@@ -1607,6 +6231,9 @@ const docTemplate = `{
                 "count": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "sellers": {
                     "type": "array",
                     "items": {
@@ -1621,6 +6248,9 @@ const docTemplate = `{
                 "count": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "shops": {
                     "type": "array",
                     "items": {
@@ -1635,6 +6265,9 @@ const docTemplate = `{
                 "count": {
                     "type": "integer"
                 },
+                "next_cursor": {
+                    "type": "string"
+                },
                 "users": {
                     "type": "array",
                     "items": {
@@ -1737,6 +6370,18 @@ const docTemplate = `{
                 "close_time": {
                     "type": "string"
                 },
+                "coordinates": {
+                    "$ref": "#/definitions/user_service.GeoPoint"
+                },
+                "expected_updated_at": {
+                    "type": "string"
+                },
+                "holidays": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.BranchHoliday"
+                    }
+                },
                 "id": {
                     "type": "string"
                 },
@@ -1751,6 +6396,18 @@ const docTemplate = `{
                 },
                 "phone": {
                     "type": "string"
+                },
+                "schedule": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/user_service.BranchHours"
+                    }
+                },
+                "shop_id": {
+                    "type": "string"
+                },
+                "timezone": {
+                    "type": "string"
                 }
             }
         },
@@ -1763,6 +6420,9 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "expected_updated_at": {
+                    "type": "string"
+                },
                 "firstname": {
                     "type": "string"
                 },
@@ -1786,6 +6446,9 @@ const docTemplate = `{
                 "email": {
                     "type": "string"
                 },
+                "expected_updated_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -1815,6 +6478,9 @@ const docTemplate = `{
                 "description_uz": {
                     "type": "string"
                 },
+                "expected_updated_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "string"
                 },
@@ -1847,6 +6513,9 @@ const docTemplate = `{
         "user_service.UpdateUs": {
             "type": "object",
             "properties": {
+                "expected_updated_at": {
+                    "type": "string"
+                },
                 "gmail": {
                     "type": "string"
                 },
@@ -1892,6 +6561,126 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "webhook.Attempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                }
+            }
+        },
+        "webhook.Delivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/webhook.Attempt"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "endpoint_id": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/webhook.Event"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "next_attempt_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/webhook.DeliveryStatus"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliverySucceeded",
+                "DeliveryFailed"
+            ]
+        },
+        "webhook.Endpoint": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "webhook.Event": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "object"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "string"
+                },
+                "resource_type": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -1910,7 +6699,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "",
 	Schemes:          []string{},
 	Title:            "",
-	Description:      "",
+	Description:      "Responses are protobuf messages encoded with protojson. Field names are the\nsnake_case proto names (lowerCamel when JSON_USE_PROTO_NAMES=false), unset\nfields are written with their zero values (JSON_EMIT_UNPOPULATED) and 64-bit\nintegers are numbers unless JSON_INT64_AS_STRING=true.\nAuthorized POST requests may send an Idempotency-Key header; retries with the same\nkey and body get the stored first response (marked with Idempotent-Replayed: true).\nuser_service RPCs with a google.api.http annotation in protos/user_service are\nalso served at the annotated paths, e.g. GET /v1/shops/{id}, without being listed here;\nthose with a route here get the same handler, checks and response at both paths.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
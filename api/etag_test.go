@@ -0,0 +1,74 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestETag(t *testing.T) {
+	s := newTestServer(t)
+	s.customers.Add(&pb.Customer{Phone: "+998901234567", Email: "a@example.com", Firstname: "Ann"})
+
+	w := s.do("GET", "/getbyidcustomer/1", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("GET = %d with ETag %q, want 200 with an ETag", w.Code, etag)
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		headers []string
+		want    int
+	}{
+		{"if-none-match hit", "GET", "/getbyidcustomer/1", "", []string{"If-None-Match", etag}, http.StatusNotModified},
+		{"if-none-match list", "GET", "/getbyidcustomer/1", "", []string{"If-None-Match", `"x", ` + etag}, http.StatusNotModified},
+		{"if-none-match star", "GET", "/getbyidcustomer/1", "", []string{"If-None-Match", "*"}, http.StatusNotModified},
+		{"if-none-match miss", "GET", "/getbyidcustomer/1", "", []string{"If-None-Match", `"other"`}, http.StatusOK},
+		{"weak if-match", "PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com"}`, []string{"If-Match", "W/" + etag}, http.StatusPreconditionFailed},
+		{"several if-match", "PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com"}`, []string{"If-Match", etag + ", " + etag}, http.StatusPreconditionFailed},
+		{"unquoted if-match", "PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com"}`, []string{"If-Match", "abc"}, http.StatusPreconditionFailed},
+		{"stale if-match", "DELETE", "/deleteCustomer/1", "", []string{"If-Match", `"MjAyMC0wMS0wMSAwMDowMDowMA"`}, http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := s.do(tt.method, tt.path, tt.body, tt.headers...); w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	w = s.do("PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com","firstname":"Bob"}`, "If-Match", etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag || w.Header().Get("ETag") == "" {
+		t.Fatalf("PUT with current If-Match = %d with ETag %q, want 200 with a new ETag", w.Code, w.Header().Get("ETag"))
+	}
+	if w := s.do("PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com"}`, "If-Match", etag); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with the old If-Match = %d, want 412", w.Code)
+	}
+	if customer, _ := s.customers.Get("1"); customer.Firstname != "Bob" {
+		t.Errorf("firstname = %q, want Bob", customer.Firstname)
+	}
+}
+
+func TestFailedPreconditionWithoutIfMatch(t *testing.T) {
+	s := newTestServer(t)
+	s.customers.Add(&pb.Customer{Phone: "+998901234567", Email: "a@example.com"})
+	s.customers.Fail("Update", status.Error(codes.FailedPrecondition, "customer is blocked"))
+
+	// Only a request that named a version was refused for a stale one.
+	for _, ifMatch := range []string{"", "*"} {
+		if w := s.do("PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com"}`, "If-Match", ifMatch); w.Code != http.StatusBadRequest {
+			t.Errorf("PUT with If-Match %q = %d, want 400", ifMatch, w.Code)
+		}
+	}
+	if w := s.do("PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"a@example.com"}`, "If-Match", `"MjAyNi0wMS0wMSAwMDowMDowMQ"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT with an If-Match version = %d, want 412", w.Code)
+	}
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessTokenIsNotLogged(t *testing.T) {
+	var log bytes.Buffer
+	gin.DefaultWriter = &log
+	defer func() { gin.DefaultWriter = io.Discard }()
+
+	s := newTestServer(t)
+	token := strings.TrimPrefix(bearer("admin-1", "admin"), "Bearer ")
+	if w := s.do("GET", "/v1/events?events=nope&access_token="+token, ""); w.Code != http.StatusBadRequest {
+		t.Fatalf("GET = %d, want 400", w.Code)
+	}
+
+	if !strings.Contains(log.String(), "/v1/events?events=nope&access_token=REDACTED") || strings.Contains(log.String(), token) {
+		t.Errorf("request log %q, want the access token redacted", log.String())
+	}
+}
+
+// sseStream is an open /v1/events response whose lines are read in the
+// background.
+type sseStream struct {
+	resp  *http.Response
+	lines chan string
+}
+
+func openStream(t *testing.T, url string, headers ...string) *sseStream {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	s := &sseStream{resp: resp, lines: make(chan string, 100)}
+	go func() {
+		defer close(s.lines)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			s.lines <- scanner.Text()
+		}
+	}()
+	return s
+}
+
+// next returns the next line starting with prefix, failing after a few
+// seconds; skipped are the lines it passed over.
+func (s *sseStream) next(t *testing.T, prefix string) (line string, skipped []string) {
+	t.Helper()
+
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				t.Fatalf("stream ended waiting for %q after %q", prefix, skipped)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return line, skipped
+			}
+			skipped = append(skipped, line)
+		case <-timeout:
+			t.Fatalf("no %q line after %q", prefix, skipped)
+		}
+	}
+}
+
+func (s *sseStream) event(t *testing.T) (events.Event, []string) {
+	t.Helper()
+
+	line, skipped := s.next(t, "data: ")
+	var ev events.Event
+	if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev, skipped
+}
+
+func TestStreamEvents(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.EventHeartbeat = 20 * time.Millisecond
+		cfg.EventBufferSize = 2
+	})
+	// Cleanups run last in first out, so the streams are closed before the
+	// server waits for its connections.
+	srv := httptest.NewServer(s.router)
+	t.Cleanup(srv.Close)
+
+	stream := openStream(t, srv.URL+"/v1/events?events=shop.*,customer.*", "Authorization", seller)
+	if stream.resp.StatusCode != http.StatusOK || stream.resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("GET /v1/events = %d %s", stream.resp.StatusCode, stream.resp.Header.Get("Content-Type"))
+	}
+	stream.next(t, "retry: ")
+	eventually(t, func() bool { return s.events.Subscribers() == 1 }, "no subscriber")
+
+	// Customer events are for admins only; seller events are not asked for.
+	s.do("POST", "/createCustomer", `{"phone":"+998901234567","email":"a@example.com"}`, "Authorization", admin)
+	s.do("POST", "/CreateSeller", `{"name":"A","phone":"+998901234567","email":"a@example.com","shop_id":"9"}`, "Authorization", admin)
+	s.do("POST", "/CreateShop", `{"slug":"tea","name_en":"Tea","phone":"+998901234567"}`, "Authorization", admin)
+	created, skipped := stream.event(t)
+	if created.Type != "shop.created" || created.ResourceID != "1" {
+		t.Errorf("first event = %+v after %q, want shop.created", created, skipped)
+	}
+	stream.next(t, ": heartbeat")
+
+	s.do("PUT", "/UpdateShop/1", `{"slug":"tea","name_en":"Green tea","phone":"+998901234567"}`, "Authorization", admin)
+	updated, _ := stream.event(t)
+	if updated.Type != "shop.updated" || updated.ID <= created.ID {
+		t.Errorf("second event = %+v, want shop.updated after %d", updated, created.ID)
+	}
+
+	// A client resuming from the buffer gets what it missed; one resuming
+	// from before it is told to reload.
+	resumed := openStream(t, srv.URL+"/v1/events?access_token="+strings.TrimPrefix(seller, "Bearer "), "Last-Event-ID", strconv.FormatUint(created.ID, 10))
+	if ev, _ := resumed.event(t); ev.ID != updated.ID {
+		t.Errorf("resumed stream replayed %+v, want event %d", ev, updated.ID)
+	}
+	reset := openStream(t, srv.URL+"/v1/events?last_event_id=1", "Authorization", admin)
+	if line, _ := reset.next(t, "event: "); line != "event: reset" {
+		t.Errorf("stream resumed from a lost event sent %q, want a reset", line)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		headers []string
+		want    int
+	}{
+		{"anonymous", "/v1/events", nil, http.StatusUnauthorized},
+		{"invalid token", "/v1/events?access_token=abc", nil, http.StatusUnauthorized},
+		{"invalid pattern", "/v1/events?events=shop", []string{"Authorization", admin}, http.StatusBadRequest},
+		{"invalid Last-Event-ID", "/v1/events", []string{"Authorization", admin, "Last-Event-ID", "abc"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := s.do("GET", tt.path, "", tt.headers...); w.Code != tt.want {
+			t.Errorf("%s: GET %s = %d, want %d", tt.name, tt.path, w.Code, tt.want)
+		}
+	}
+}
+
+// published returns "<type> <resource ID>" of the next n events sub
+// receives, sorted, failing after a few seconds.
+func published(t *testing.T, sub *events.Subscription, n int) []string {
+	t.Helper()
+
+	var got []string
+	timeout := time.After(5 * time.Second)
+	for len(got) < n {
+		select {
+		case ev := <-sub.C():
+			got = append(got, ev.Type+" "+ev.ResourceID)
+		case <-timeout:
+			t.Fatalf("%d events after %q, want %d", len(got), got, n)
+		}
+	}
+	sort.Strings(got)
+	return got
+}
+
+func TestPublishedChanges(t *testing.T) {
+	s := newTestServer(t)
+	sub, _, _ := s.events.Subscribe(0, 100)
+	t.Cleanup(sub.Close)
+
+	const customer = `{"phone":"+998901234567","email":"a@example.com"}`
+
+	// Neither the response format nor running in a job hides what changed.
+	s.do("POST", "/createCustomer", customer, "Authorization", admin, "Accept", "application/x-protobuf")
+	s.do("POST", "/bulkCreateCustomer?async=true", `{"customers":[`+customer+`,`+customer+`]}`, "Authorization", admin)
+	body, contentType := upload(t, "phone,email\n+998901234567,a@example.com\n")
+	s.do("POST", "/importCustomer?mode=commit", body, "Authorization", admin, "Content-Type", contentType)
+
+	want := []string{"customer.created 1", "customer.created 2", "customer.created 3", "customer.created 4"}
+	if got := published(t, sub, len(want)); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("creates published %q, want %q", got, want)
+	}
+
+	// A cascade publishes the dependents it deleted along with the shop.
+	s.shops.Add(&pb.Shop{Slug: "a"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.branches.Add(&pb.Branch{Name: "A", ShopId: "1"})
+	if w := s.do("DELETE", "/DeleteShop/1?cascade=true", "", "Authorization", admin); w.Code != http.StatusOK {
+		t.Fatalf("DeleteShop?cascade=true = %d %s", w.Code, w.Body)
+	}
+	want = []string{"branch.deleted 1", "seller.deleted 1", "shop.deleted 1"}
+	if got := published(t, sub, len(want)); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("cascade published %q, want %q", got, want)
+	}
+
+	if w := s.do("POST", "/v1/onboarding", onboarding, "Authorization", admin); w.Code != http.StatusCreated {
+		t.Fatalf("onboarding = %d %s", w.Code, w.Body)
+	}
+	want = []string{"seller.created 2", "shop.created 2", "user.created 1"}
+	if got := published(t, sub, len(want)); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("onboarding published %q, want %q", got, want)
+	}
+}
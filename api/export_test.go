@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/csv"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestExport(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.MaxPageSize = 2 })
+	for _, name := range []string{"Ann", "Bob", "=cmd", "Dan", "Eve"} {
+		s.customers.Add(&pb.Customer{Firstname: name})
+	}
+
+	// Five customers are read in three pages and streamed as one file.
+	w := s.do("GET", "/exportCustomer?columns=id,firstname&lang=ru", "", "Authorization", admin)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") ||
+		w.Header().Get("Content-Disposition") != `attachment; filename="customers.csv"` {
+		t.Fatalf("CSV export = %d %v: %s", w.Code, w.Header(), w.Body)
+	}
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The file starts with a byte order mark so that Excel reads it as UTF-8.
+	want := [][]string{{"\ufeffID", "Имя"}, {"1", "Ann"}, {"2", "Bob"}, {"3", "'=cmd"}, {"4", "Dan"}, {"5", "Eve"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("CSV export = %q, want %q", rows, want)
+	}
+	if n := s.customers.Calls("GetList"); n != 3 {
+		t.Errorf("GetList called %d times for five customers in pages of two, want 3", n)
+	}
+
+	w = s.do("GET", "/ExportShop?format=xlsx", "", "Authorization", admin)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Body.String(), "PK") ||
+		w.Header().Get("Content-Type") != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" {
+		t.Errorf("XLSX export = %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{"unknown format", "/exportCustomer?format=pdf", http.StatusBadRequest},
+		{"unknown column", "/ExportSeller?columns=id,password", http.StatusBadRequest},
+		{"unknown lang", "/exportCustomer?lang=de", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := s.do("GET", tt.path, "", "Authorization", admin); w.Code != tt.want {
+			t.Errorf("%s: GET %s = %d %s, want %d", tt.name, tt.path, w.Code, w.Body, tt.want)
+		}
+	}
+
+	// An upstream error before the first row is a proper error response.
+	s.customers.Fail("GetList", status.Error(codes.Unavailable, "down"))
+	if w := s.do("GET", "/exportCustomer", "", "Authorization", admin); w.Code != http.StatusInternalServerError {
+		t.Errorf("export with the service down = %d %s, want 500", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,625 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "microservice/genproto/user_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// table is an in-memory user service table. Items are filled from requests
+// field by field by name, like the service fills its columns, and deleting
+// them sets deleted_at.
+type table[T proto.Message] struct {
+	mu    sync.Mutex
+	items []T
+	next  int
+	clock time.Time
+	// calls counts the RPCs by method name; fail makes one return an error.
+	calls map[string]int
+	fail  map[string]error
+}
+
+func newTable[T proto.Message]() *table[T] {
+	return &table[T]{
+		clock: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		calls: make(map[string]int),
+		fail:  make(map[string]error),
+	}
+}
+
+// call records an RPC and returns the error it should fail with.
+func (t *table[T]) call(method string) error {
+	t.calls[method]++
+	return t.fail[method]
+}
+
+// Calls returns how many times method was called.
+func (t *table[T]) Calls(method string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.calls[method]
+}
+
+// Fail makes method return err until it is called with nil.
+func (t *table[T]) Fail(method string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.fail[method] = err
+}
+
+// tick returns a new, later updated_at for every write.
+func (t *table[T]) tick() string {
+	t.clock = t.clock.Add(time.Second)
+	return t.clock.Format("2006-01-02 15:04:05")
+}
+
+func (t *table[T]) insert(req proto.Message) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var item T
+	if err := t.call("Create"); err != nil {
+		return item, err
+	}
+
+	item = item.ProtoReflect().New().Interface().(T)
+	copyFields(req.ProtoReflect(), item.ProtoReflect())
+	now := t.tick()
+	t.next++
+	setString(item, "id", fmt.Sprint(t.next))
+	setString(item, "created_at", now)
+	setString(item, "updated_at", now)
+
+	t.items = append(t.items, item)
+	return clone(item), nil
+}
+
+// Add inserts item as is, with the next ID and timestamps when it has none.
+func (t *table[T]) Add(item T) T {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if getString(item, "id") == "" {
+		t.next++
+		setString(item, "id", fmt.Sprint(t.next))
+	}
+	if getString(item, "updated_at") == "" {
+		now := t.tick()
+		setString(item, "created_at", now)
+		setString(item, "updated_at", now)
+	}
+
+	t.items = append(t.items, item)
+	return clone(item)
+}
+
+// Get returns the item with id, deleted or not.
+func (t *table[T]) Get(id string) (T, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	item, ok := t.find(id)
+	if ok {
+		item = clone(item)
+	}
+	return item, ok
+}
+
+// Len returns the number of items that are not deleted.
+func (t *table[T]) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := 0
+	for _, item := range t.items {
+		if !deleted(item) {
+			n++
+		}
+	}
+	return n
+}
+
+func (t *table[T]) find(id string) (T, bool) {
+	for _, item := range t.items {
+		if getString(item, "id") == id {
+			return item, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+func (t *table[T]) byID(req proto.Message) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var zero T
+	if err := t.call("GetByID"); err != nil {
+		return zero, err
+	}
+	item, ok := t.find(getString(req, "id"))
+	if !ok || deleted(item) {
+		return zero, status.Error(codes.NotFound, "no rows in result set")
+	}
+	return clone(item), nil
+}
+
+func (t *table[T]) byField(method string, field protoreflect.Name, value string) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var zero T
+	if err := t.call(method); err != nil {
+		return zero, err
+	}
+	for _, item := range t.items {
+		if getString(item, field) == value && !deleted(item) {
+			return clone(item), nil
+		}
+	}
+	return zero, status.Error(codes.NotFound, "no rows in result set")
+}
+
+// update checks expected_updated_at and copies the set fields of req.
+func (t *table[T]) update(req proto.Message) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var zero T
+	if err := t.call("Update"); err != nil {
+		return zero, err
+	}
+	item, ok := t.find(getString(req, "id"))
+	if !ok || deleted(item) {
+		return zero, status.Error(codes.NotFound, "no rows in result set")
+	}
+	if expected := getString(req, "expected_updated_at"); expected != "" && expected != getString(item, "updated_at") {
+		return zero, status.Error(codes.FailedPrecondition, "the record was modified")
+	}
+
+	copyFields(req.ProtoReflect(), item.ProtoReflect())
+	setString(item, "updated_at", t.tick())
+	return clone(item), nil
+}
+
+// remove soft-deletes, purges or restores the item with the id of req.
+func (t *table[T]) remove(method string, req proto.Message) (T, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var zero T
+	if err := t.call(method); err != nil {
+		return zero, err
+	}
+	item, ok := t.find(getString(req, "id"))
+	if !ok || method != "Purge" && deleted(item) != (method == "Restore") {
+		return zero, status.Error(codes.NotFound, "no rows in result set")
+	}
+	if expected := getString(req, "expected_updated_at"); expected != "" && expected != getString(item, "updated_at") {
+		return zero, status.Error(codes.FailedPrecondition, "the record was modified")
+	}
+
+	switch method {
+	case "Delete":
+		setDeleted(item, true)
+	case "Restore":
+		setDeleted(item, false)
+	case "Purge":
+		for i := range t.items {
+			if getString(t.items[i], "id") == getString(item, "id") {
+				t.items = append(t.items[:i], t.items[i+1:]...)
+				break
+			}
+		}
+		return item, nil
+	}
+	setString(item, "updated_at", t.tick())
+	return clone(item), nil
+}
+
+// list filters by the string fields req shares with the items (shop_id,
+// role, ...), search, the deleted flags, and pages with page and limit.
+func (t *table[T]) list(req proto.Message) ([]T, int64, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.call("GetList"); err != nil {
+		return nil, 0, err
+	}
+
+	var (
+		m       = req.ProtoReflect()
+		fields  = m.Descriptor().Fields()
+		search  = getString(req, "search")
+		include = fields.ByName("include_deleted") != nil && m.Get(fields.ByName("include_deleted")).Bool()
+		only    = fields.ByName("only_deleted") != nil && m.Get(fields.ByName("only_deleted")).Bool()
+		items   []T
+	)
+	for _, item := range t.items {
+		if deleted(item) && !include && !only || !deleted(item) && only {
+			continue
+		}
+		if search != "" && !matches(item.ProtoReflect(), search) {
+			continue
+		}
+		if !filtered(m, item.ProtoReflect()) {
+			continue
+		}
+		items = append(items, clone(item))
+	}
+
+	count := int64(len(items))
+	page, limit := m.Get(fields.ByName("page")).Uint(), m.Get(fields.ByName("limit")).Uint()
+	if page == 0 {
+		page = 1
+	}
+	if limit > 0 {
+		start := (page - 1) * limit
+		if start > uint64(len(items)) {
+			start = uint64(len(items))
+		}
+		end := start + limit
+		if end > uint64(len(items)) {
+			end = uint64(len(items))
+		}
+		items = items[start:end]
+	}
+	return items, count, nil
+}
+
+var listControls = map[protoreflect.Name]bool{
+	"search": true, "cursor": true, "sort": true, "created_at_from": true, "created_at_to": true,
+}
+
+func filtered(req, item protoreflect.Message) bool {
+	fields := req.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Kind() != protoreflect.StringKind || listControls[fd.Name()] {
+			continue
+		}
+		value := req.Get(fd).String()
+		target := item.Descriptor().Fields().ByName(fd.Name())
+		if value != "" && target != nil && item.Get(target).String() != value {
+			return false
+		}
+	}
+	return true
+}
+
+func matches(item protoreflect.Message, search string) bool {
+	found := false
+	item.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if fd.Kind() == protoreflect.StringKind && !fd.IsList() && strings.Contains(strings.ToLower(v.String()), strings.ToLower(search)) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// copyFields sets the fields of dst that src has, by name, skipping the
+// request-only ones.
+func copyFields(src, dst protoreflect.Message) {
+	fields := src.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		target := dst.Descriptor().Fields().ByName(fd.Name())
+		if target == nil || target.Kind() != fd.Kind() || target.Cardinality() != fd.Cardinality() || fd.Name() == "id" {
+			continue
+		}
+		if fd.Kind() == protoreflect.MessageKind && target.Message().FullName() != fd.Message().FullName() {
+			continue
+		}
+		if !src.Has(fd) {
+			if !fd.IsList() && fd.Kind() != protoreflect.MessageKind {
+				dst.Clear(target)
+			}
+			continue
+		}
+		if fd.IsList() {
+			list := dst.Mutable(target).List()
+			list.Truncate(0)
+			for j := 0; j < src.Get(fd).List().Len(); j++ {
+				v := src.Get(fd).List().Get(j)
+				if fd.Kind() == protoreflect.MessageKind {
+					v = protoreflect.ValueOfMessage(proto.Clone(v.Message().Interface()).ProtoReflect())
+				}
+				list.Append(v)
+			}
+			continue
+		}
+		v := src.Get(fd)
+		if fd.Kind() == protoreflect.MessageKind {
+			v = protoreflect.ValueOfMessage(proto.Clone(v.Message().Interface()).ProtoReflect())
+		}
+		dst.Set(target, v)
+	}
+}
+
+func getString(m proto.Message, name protoreflect.Name) string {
+	fd := m.ProtoReflect().Descriptor().Fields().ByName(name)
+	if fd == nil || fd.Kind() != protoreflect.StringKind {
+		return ""
+	}
+	return m.ProtoReflect().Get(fd).String()
+}
+
+func setString(m proto.Message, name protoreflect.Name, value string) {
+	if fd := m.ProtoReflect().Descriptor().Fields().ByName(name); fd != nil {
+		m.ProtoReflect().Set(fd, protoreflect.ValueOfString(value))
+	}
+}
+
+// deleted_at is a timestamp string on shops and unix seconds elsewhere.
+func deleted(m proto.Message) bool {
+	fd := m.ProtoReflect().Descriptor().Fields().ByName("deleted_at")
+	return fd != nil && m.ProtoReflect().Has(fd)
+}
+
+func setDeleted(m proto.Message, on bool) {
+	fd := m.ProtoReflect().Descriptor().Fields().ByName("deleted_at")
+	switch {
+	case !on:
+		m.ProtoReflect().Clear(fd)
+	case fd.Kind() == protoreflect.StringKind:
+		m.ProtoReflect().Set(fd, protoreflect.ValueOfString("2026-01-02 00:00:00"))
+	default:
+		m.ProtoReflect().Set(fd, protoreflect.ValueOfInt64(1767312000))
+	}
+}
+
+func clone[T proto.Message](m T) T {
+	return proto.Clone(m).(T)
+}
+
+type fakeCustomers struct {
+	pb.UnimplementedCustomerServiceServer
+	*table[*pb.Customer]
+}
+
+func (f *fakeCustomers) Create(ctx context.Context, r *pb.CreateCustomer) (*pb.Customer, error) {
+	return f.insert(r)
+}
+
+func (f *fakeCustomers) GetByID(ctx context.Context, r *pb.CustomerPrimaryKey) (*pb.Customer, error) {
+	return f.byID(r)
+}
+
+func (f *fakeCustomers) GetList(ctx context.Context, r *pb.GetListCustomerRequest) (*pb.GetListCustomerResponse, error) {
+	items, count, err := f.list(r)
+	return &pb.GetListCustomerResponse{Count: count, Customers: items}, err
+}
+
+func (f *fakeCustomers) Update(ctx context.Context, r *pb.UpdateCustomer) (*pb.Customer, error) {
+	return f.update(r)
+}
+
+func (f *fakeCustomers) Delete(ctx context.Context, r *pb.CustomerPrimaryKey) (*pb.Empty, error) {
+	_, err := f.remove("Delete", r)
+	return &pb.Empty{}, err
+}
+
+func (f *fakeCustomers) Restore(ctx context.Context, r *pb.CustomerPrimaryKey) (*pb.Customer, error) {
+	return f.remove("Restore", r)
+}
+
+// BulkCreate is the only atomic bulk RPC the fake has; the others are
+// unimplemented, as they may be in the user service.
+func (f *fakeCustomers) BulkCreate(ctx context.Context, r *pb.BulkCreateCustomerRequest) (*pb.BulkCustomerResponse, error) {
+	resp := &pb.BulkCustomerResponse{}
+	for _, item := range r.Customers {
+		customer, err := f.insert(item)
+		if err != nil {
+			return nil, err
+		}
+		resp.Customers = append(resp.Customers, customer)
+	}
+	return resp, nil
+}
+
+func (f *fakeCustomers) Purge(ctx context.Context, r *pb.CustomerPrimaryKey) (*pb.Empty, error) {
+	_, err := f.remove("Purge", r)
+	return &pb.Empty{}, err
+}
+
+type fakeUsers struct {
+	pb.UnimplementedUsServiceServer
+	*table[*pb.Us]
+}
+
+func (f *fakeUsers) Create(ctx context.Context, r *pb.CreateUs) (*pb.Us, error) {
+	return f.insert(r)
+}
+
+func (f *fakeUsers) GetByID(ctx context.Context, r *pb.UsPrimaryKey) (*pb.Us, error) {
+	return f.byID(r)
+}
+
+func (f *fakeUsers) GetList(ctx context.Context, r *pb.GetListUsRequest) (*pb.GetListUsResponse, error) {
+	items, count, err := f.list(r)
+	return &pb.GetListUsResponse{Count: count, Users: items}, err
+}
+
+func (f *fakeUsers) Update(ctx context.Context, r *pb.UpdateUs) (*pb.Us, error) {
+	return f.update(r)
+}
+
+func (f *fakeUsers) Delete(ctx context.Context, r *pb.UsPrimaryKey) (*pb.Empty1, error) {
+	_, err := f.remove("Delete", r)
+	return &pb.Empty1{}, err
+}
+
+func (f *fakeUsers) Restore(ctx context.Context, r *pb.UsPrimaryKey) (*pb.Us, error) {
+	return f.remove("Restore", r)
+}
+
+func (f *fakeUsers) Purge(ctx context.Context, r *pb.UsPrimaryKey) (*pb.Empty1, error) {
+	_, err := f.remove("Purge", r)
+	return &pb.Empty1{}, err
+}
+
+type fakeSellers struct {
+	pb.UnimplementedSellerServiceServer
+	*table[*pb.Seller]
+}
+
+func (f *fakeSellers) Create(ctx context.Context, r *pb.CreateSeller) (*pb.Seller, error) {
+	return f.insert(r)
+}
+
+func (f *fakeSellers) GetByID(ctx context.Context, r *pb.SellerPrimaryKey) (*pb.Seller, error) {
+	return f.byID(r)
+}
+
+func (f *fakeSellers) GetList(ctx context.Context, r *pb.GetListSellerRequest) (*pb.GetListSellerResponse, error) {
+	items, count, err := f.list(r)
+	return &pb.GetListSellerResponse{Count: count, Sellers: items}, err
+}
+
+func (f *fakeSellers) Update(ctx context.Context, r *pb.UpdateSeller) (*pb.Seller, error) {
+	return f.update(r)
+}
+
+func (f *fakeSellers) Delete(ctx context.Context, r *pb.SellerPrimaryKey) (*pb.Empty2, error) {
+	_, err := f.remove("Delete", r)
+	return &pb.Empty2{}, err
+}
+
+func (f *fakeSellers) Restore(ctx context.Context, r *pb.SellerPrimaryKey) (*pb.Seller, error) {
+	return f.remove("Restore", r)
+}
+
+func (f *fakeSellers) Purge(ctx context.Context, r *pb.SellerPrimaryKey) (*pb.Empty2, error) {
+	_, err := f.remove("Purge", r)
+	return &pb.Empty2{}, err
+}
+
+type fakeBranches struct {
+	pb.UnimplementedBranchServiceServer
+	*table[*pb.Branch]
+}
+
+func (f *fakeBranches) Create(ctx context.Context, r *pb.CreateBranch) (*pb.Branch, error) {
+	return f.insert(r)
+}
+
+func (f *fakeBranches) GetByID(ctx context.Context, r *pb.BranchPrimaryKey) (*pb.Branch, error) {
+	return f.byID(r)
+}
+
+func (f *fakeBranches) GetList(ctx context.Context, r *pb.GetListBranchRequest) (*pb.GetListBranchResponse, error) {
+	items, count, err := f.list(r)
+	return &pb.GetListBranchResponse{Count: count, Branches: items}, err
+}
+
+func (f *fakeBranches) Update(ctx context.Context, r *pb.UpdateBranch) (*pb.Branch, error) {
+	return f.update(r)
+}
+
+func (f *fakeBranches) Delete(ctx context.Context, r *pb.BranchPrimaryKey) (*pb.Empty3, error) {
+	_, err := f.remove("Delete", r)
+	return &pb.Empty3{}, err
+}
+
+func (f *fakeBranches) Restore(ctx context.Context, r *pb.BranchPrimaryKey) (*pb.Branch, error) {
+	return f.remove("Restore", r)
+}
+
+func (f *fakeBranches) Purge(ctx context.Context, r *pb.BranchPrimaryKey) (*pb.Empty3, error) {
+	_, err := f.remove("Purge", r)
+	return &pb.Empty3{}, err
+}
+
+type fakeShops struct {
+	pb.UnimplementedShopServiceServer
+	*table[*pb.Shop]
+}
+
+func (f *fakeShops) Create(ctx context.Context, r *pb.CreateShop) (*pb.Shop, error) {
+	return f.insert(r)
+}
+
+func (f *fakeShops) GetByID(ctx context.Context, r *pb.ShopPrimaryKey) (*pb.Shop, error) {
+	return f.byID(r)
+}
+
+func (f *fakeShops) GetBySlug(ctx context.Context, r *pb.ShopSlug) (*pb.Shop, error) {
+	return f.byField("GetBySlug", "slug", r.Slug)
+}
+
+func (f *fakeShops) GetList(ctx context.Context, r *pb.GetListShopRequest) (*pb.GetListShopResponse, error) {
+	items, count, err := f.list(r)
+	return &pb.GetListShopResponse{Count: count, Shops: items}, err
+}
+
+func (f *fakeShops) Update(ctx context.Context, r *pb.UpdateShop) (*pb.Shop, error) {
+	return f.update(r)
+}
+
+func (f *fakeShops) Delete(ctx context.Context, r *pb.ShopPrimaryKey) (*pb.Empty4, error) {
+	_, err := f.remove("Delete", r)
+	return &pb.Empty4{}, err
+}
+
+func (f *fakeShops) Restore(ctx context.Context, r *pb.ShopPrimaryKey) (*pb.Shop, error) {
+	return f.remove("Restore", r)
+}
+
+func (f *fakeShops) Purge(ctx context.Context, r *pb.ShopPrimaryKey) (*pb.Empty4, error) {
+	_, err := f.remove("Purge", r)
+	return &pb.Empty4{}, err
+}
+
+// userService is a fake user service on a local port.
+type userService struct {
+	customers *fakeCustomers
+	users     *fakeUsers
+	sellers   *fakeSellers
+	branches  *fakeBranches
+	shops     *fakeShops
+	addr      *net.TCPAddr
+}
+
+func startUserService(t *testing.T) *userService {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &userService{
+		customers: &fakeCustomers{table: newTable[*pb.Customer]()},
+		users:     &fakeUsers{table: newTable[*pb.Us]()},
+		sellers:   &fakeSellers{table: newTable[*pb.Seller]()},
+		branches:  &fakeBranches{table: newTable[*pb.Branch]()},
+		shops:     &fakeShops{table: newTable[*pb.Shop]()},
+		addr:      lis.Addr().(*net.TCPAddr),
+	}
+
+	server := grpc.NewServer()
+	pb.RegisterCustomerServiceServer(server, s.customers)
+	pb.RegisterUsServiceServer(server, s.users)
+	pb.RegisterSellerServiceServer(server, s.sellers)
+	pb.RegisterBranchServiceServer(server, s.branches)
+	pb.RegisterShopServiceServer(server, s.shops)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	return s
+}
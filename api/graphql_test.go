@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/audit"
+)
+
+func graphqlBody(query string) string {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	return string(body)
+}
+
+func TestGraphQLMutationsAreAudited(t *testing.T) {
+	s := newTestServer(t)
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "2"})
+
+	mutations := []struct {
+		query    string
+		method   string
+		id       string
+		outcome  audit.Outcome
+		status   int
+		snapshot bool
+	}{
+		{`mutation { createShop(input: {slug: "corner", name_en: "Corner", phone: "+998901234567"}) { id } }`, "POST", "1", audit.OutcomeSuccess, http.StatusOK, true},
+		{`mutation { updateShop(input: {id: "1", slug: "corner", name_en: "Corner 2", phone: "+998901234567"}) { id } }`, "PUT", "1", audit.OutcomeSuccess, http.StatusOK, true},
+		{`mutation { updateShop(input: {id: "9", slug: "nine", name_en: "Nine", phone: "+998901234567"}) { id } }`, "PUT", "9", audit.OutcomeFailure, http.StatusNotFound, false},
+		{`mutation { deleteShop(id: "1") }`, "DELETE", "1", audit.OutcomeSuccess, http.StatusOK, true},
+	}
+	for _, m := range mutations {
+		w := s.do("POST", "/graphql", graphqlBody(m.query), "Authorization", admin)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s = %d %s", m.query, w.Code, w.Body)
+		}
+
+		entries := s.auditEntries()
+		e := entries[0]
+		if e.Method != m.method || e.Route != "/graphql" || e.ResourceType != "shop" || e.ResourceID != m.id ||
+			e.Outcome != m.outcome || e.Status != m.status || e.Actor != "user:admin-1" || e.Role != "admin" || e.RequestID == "" {
+			t.Errorf("%s audited as %+v", m.query, e)
+		}
+		if hasSnapshot := e.After != nil || e.Before != nil; hasSnapshot != m.snapshot {
+			t.Errorf("%s audited with before %s and after %s", m.query, e.Before, e.After)
+		}
+	}
+
+	// Queries are not audited, and neither is the request that carries the
+	// mutations.
+	before := len(s.auditEntries())
+	s.do("POST", "/graphql", graphqlBody(`{ shops { count } }`), "Authorization", admin)
+	if entries := s.auditEntries(); len(entries) != before {
+		t.Errorf("a query added %d audit entries", len(entries)-before)
+	}
+	if before != len(mutations) {
+		t.Errorf("%d audit entries for %d mutations", before, len(mutations))
+	}
+
+	w := s.do("POST", "/graphql", graphqlBody(`mutation { deleteShop(id: "2", cascade: false) }`), "Authorization", seller)
+	if e := s.auditEntries()[0]; !strings.Contains(w.Body.String(), "delete them first") || !strings.Contains(w.Body.String(), `"seller_count":1`) || e.Outcome != audit.OutcomeFailure || e.Status != http.StatusConflict || e.Actor != "user:seller-1" || e.Error == "" {
+		t.Errorf("refused deleteShop = %s, audited as %+v", w.Body, e)
+	}
+}
+
+func TestGraphQLValidatesLikeREST(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "corner", NameEn: "Corner"})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`mutation { createShop(input: {slug: "corner", name_en: "Corner", phone: "+998901234567"}) { id } }`, `"suggestions":["corner-2",`},
+		{`mutation { createShop(input: {name_en: "Corner", phone: "+998901234567"}) { slug } }`, `"slug":"corner-2"`},
+		{`mutation { createShop(input: {name_en: "!", phone: "+998901234567"}) { slug } }`, `slug is required`},
+		{`mutation { createCustomer(input: {phone: "+998901234567"}) { id } }`, `email address  is not valid`},
+	}
+	for _, tt := range tests {
+		if w := s.do("POST", "/graphql", graphqlBody(tt.query), "Authorization", admin); !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s = %s, want %s", tt.query, w.Body, tt.want)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"microservice/api/models"
+	"microservice/genproto/user_service"
+	"microservice/pkg/audit"
+	"microservice/pkg/grpc_client"
+	"microservice/pkg/logger"
+	"microservice/pkg/uuid"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	requestIDHeader      = "X-Request-ID"
+	maxRequestIDLength   = 128
+	auditSnapshotTimeout = 5 * time.Second
+	auditTimeLayout      = "2006-01-02 15:04:05"
+)
+
+// auditResourceTypes maps words in route paths to resource types. The
+// routes predate /v1 and are named inconsistently, so the path is matched
+// case-insensitively in this order.
+var auditResourceTypes = []struct{ word, typ string }{
+	{"customer", "customer"},
+	{"seller", "seller"},
+	{"branch", "branch"},
+	{"shop", "shop"},
+	{"user", "user"},
+	{"onboarding", "onboarding"},
+	{"jobs", "job"},
+}
+
+func auditResourceType(route string) string {
+	route = strings.ToLower(route)
+	for _, r := range auditResourceTypes {
+		if strings.Contains(route, r.word) {
+			return r.typ
+		}
+	}
+	return ""
+}
+
+// Audit records every POST, PUT, PATCH and DELETE request in the audit sink:
+// who made it, on which resource, the resource before and after, and how it
+// ended. Successful changes are also published to webhooks and the event
+// stream. Responses Idempotency replays were recorded and published when
+// they were first served, so they are skipped, as are GraphQL requests,
+// whose mutations are recorded one by one. It gives each request an
+// X-Request-ID, keeping the client's.
+func (h *handler) Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			var err error
+			if requestID, err = uuid.New(); err != nil {
+				h.log.Error("failed to generate request ID", logger.Error(err))
+				abortWithError(c, http.StatusInternalServerError, "Internal Server Error")
+				return
+			}
+		}
+		c.Header(requestIDHeader, requestID)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestIDKey{}, requestID))
+
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		if c.FullPath() == graphqlRoute {
+			// The resolvers record each mutation.
+			c.Next()
+			return
+		}
+		if h.audit == nil && h.webhooks == nil && h.events == nil {
+			c.Next()
+			return
+		}
+
+		entryID, err := uuid.New()
+		if err != nil {
+			h.log.Error("failed to generate audit entry ID", logger.Error(err))
+			abortWithError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		e := audit.Entry{
+			ID:           entryID,
+			Time:         time.Now().UTC(),
+			Actor:        principal(c),
+			Method:       c.Request.Method,
+			Route:        c.FullPath(),
+			Path:         c.Request.URL.Path,
+			ResourceType: auditResourceType(c.FullPath()),
+			ResourceID:   c.Param("id"),
+			ClientIP:     c.ClientIP(),
+			RequestID:    requestID,
+		}
+		if claims, err := parseToken(bearerToken(c)); err == nil {
+			e.Role = claims.Role
+		}
+
+		ctx := context.WithoutCancel(c.Request.Context())
+		if e.ResourceID != "" {
+			e.Before = h.auditSnapshot(ctx, e.ResourceType, e.ResourceID)
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		c.Next()
+
+		if w.Header().Get(idempotencyReplayedHeader) == "true" {
+			return
+		}
+
+		e.Status = w.Status()
+		e.Outcome = audit.OutcomeSuccess
+		if e.Status >= http.StatusBadRequest {
+			e.Outcome = audit.OutcomeFailure
+		}
+
+		if e.Outcome == audit.OutcomeFailure {
+			var body auditResponse
+			json.Unmarshal(w.body.Bytes(), &body)
+			e.Error = body.Description
+		} else {
+			if e.ResourceID == "" {
+				e.ResourceID = c.GetString(auditResourceIDKey)
+			}
+			if e.ResourceID != "" {
+				e.After = h.auditSnapshot(ctx, e.ResourceType, e.ResourceID)
+			}
+		}
+
+		if (h.webhooks != nil || h.events != nil) && e.Outcome == audit.OutcomeSuccess {
+			go h.publishChanges(ctx, requestChange(e), requestID)
+		}
+
+		if h.audit != nil {
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			if err := h.audit.Write(ctx, e); err != nil {
+				h.log.Error("failed to write audit entry", logger.String("request_id", requestID), logger.Error(err))
+			}
+		}
+	}
+}
+
+// auditResourceIDKey is the gin context key under which handlers of routes
+// without an :id leave the ID of the resource they created.
+const auditResourceIDKey = "audit_resource_id"
+
+// setAuditResourceID tells Audit which resource the request created, whatever
+// format the response is encoded in.
+func setAuditResourceID(c *gin.Context, id string) {
+	c.Set(auditResourceIDKey, id)
+}
+
+// auditResponse is what Audit reads from error responses, which are always
+// JSON.
+type auditResponse struct {
+	Description string `json:"description"`
+}
+
+type requestIDKey struct{}
+
+// requestIDFrom returns the X-Request-ID of the request ctx belongs to.
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// auditSnapshot returns the resource as JSON, or nil when it cannot be read.
+func (h *handler) auditSnapshot(ctx context.Context, typ, id string) json.RawMessage {
+	ctx, cancel := context.WithTimeout(grpc_client.WithoutCache(ctx), auditSnapshotTimeout)
+	defer cancel()
+
+	var (
+		msg proto.Message
+		err error
+	)
+	switch typ {
+	case "customer":
+		msg, err = h.grpcClient.UserService().GetByID(ctx, &user_service.CustomerPrimaryKey{Id: id})
+	case "user":
+		msg, err = h.grpcClient.SystemUserService().GetByID(ctx, &user_service.UsPrimaryKey{Id: id})
+	case "seller":
+		msg, err = h.grpcClient.SellerService().GetByID(ctx, &user_service.SellerPrimaryKey{Id: id})
+	case "branch":
+		msg, err = h.grpcClient.BranchService().GetByID(ctx, &user_service.BranchPrimaryKey{Id: id})
+	case "shop":
+		msg, err = h.grpcClient.ShopService().GetByID(ctx, &user_service.ShopPrimaryKey{Id: id})
+	default:
+		return nil
+	}
+	if err != nil {
+		return nil
+	}
+
+	body, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		return nil
+	}
+	return body
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/audit [GET]
+// @Summary        Query the audit log
+// @Description    API for reading recorded mutating requests, newest first; admin only
+// @Tags           audit
+// @Produce        json
+// @Param          actor query string false "user:<subject>, token:<hash> or anonymous"
+// @Param          resource_type query string false "customer, user, seller, branch, shop, onboarding or job"
+// @Param          resource_id query string false "resource ID"
+// @Param          request_id query string false "X-Request-ID of the request"
+// @Param          outcome query string false "success or failure"
+// @Param          from query string false "at or after, 2006-01-02 15:04:05 UTC"
+// @Param          to query string false "before, 2006-01-02 15:04:05 UTC"
+// @Param          page query int false "page"
+// @Param          limit query int false "limit"
+// @Success        200 {object} models.AuditLog
+// @Failure        400 {object} models.ResponseError
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetAuditLog(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+	if h.audit == nil {
+		abortWithError(c, http.StatusNotFound, "audit log is disabled")
+		return
+	}
+
+	filter := audit.Filter{
+		Actor:        c.Query("actor"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		RequestID:    c.Query("request_id"),
+		Outcome:      audit.Outcome(c.Query("outcome")),
+	}
+
+	if filter.Outcome != "" && filter.Outcome != audit.OutcomeSuccess && filter.Outcome != audit.OutcomeFailure {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(errors.New("outcome must be success or failure")), "error while parsing outcome")
+		return
+	}
+
+	from, to := c.Query("from"), c.Query("to")
+	if err := validateDateRange(from, to); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing audit range")
+		return
+	}
+	if from != "" {
+		filter.From, _ = time.Parse(auditTimeLayout, from)
+	}
+	if to != "" {
+		filter.To, _ = time.Parse(auditTimeLayout, to)
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing page")
+		return
+	}
+	limit, err := ParseLimitQueryParam(c, h.cfg.DefaultPageSize, h.cfg.MaxPageSize)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing limit")
+		return
+	}
+	filter.Limit = int(limit)
+	filter.Offset = int((page - 1) * limit)
+
+	entries, err := h.audit.Query(c.Request.Context(), filter)
+	if err != nil {
+		h.log.Error("failed to query audit log", logger.Error(err))
+		abortWithError(c, http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditLog{Entries: entries})
+}
@@ -0,0 +1,25 @@
+package handler
+
+import "testing"
+
+func TestAuditResourceType(t *testing.T) {
+	tests := []struct {
+		route string
+		want  string
+	}{
+		{"/updateCustomer/:id", "customer"},
+		{"/BulkDeleteSeller", "seller"},
+		{"/v1/branches/:id/restore", "branch"},
+		{"/v1/shops/:id", "shop"},
+		{"/UpdateUser/:id", "user"},
+		{"/v1/onboarding", "onboarding"},
+		{"/jobs/:id/cancel", "job"},
+		{"/admin/webhooks", ""},
+	}
+
+	for _, tt := range tests {
+		if got := auditResourceType(tt.route); got != tt.want {
+			t.Errorf("auditResourceType(%q) = %q, want %q", tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"microservice/api/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const adminRole = "admin"
+
+// tokenClaims are the access token claims the gateway reads.
+type tokenClaims struct {
+	Subject   string `json:"sub"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// parseToken verifies an HS256 JWT signed with SigningKey and returns its
+// claims.
+func parseToken(token string) (tokenClaims, error) {
+	var claims tokenClaims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, errors.New("malformed token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeTokenPart(parts[0], &header); err != nil || header.Alg != "HS256" {
+		return claims, errors.New("unsupported token algorithm")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return claims, errors.New("malformed token signature")
+	}
+	mac := hmac.New(sha256.New, SigningKey)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return claims, errors.New("invalid token signature")
+	}
+
+	if err := decodeTokenPart(parts[1], &claims); err != nil {
+		return claims, errors.New("malformed token claims")
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() >= claims.ExpiresAt {
+		return claims, errors.New("token expired")
+	}
+	if claims.Subject == "" {
+		claims.Subject = claims.UserID
+	}
+
+	return claims, nil
+}
+
+func decodeTokenPart(part string, v interface{}) error {
+	body, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// bearerToken returns the Authorization header without the Bearer prefix.
+func bearerToken(c *gin.Context) string {
+	token := strings.TrimSpace(c.GetHeader("Authorization"))
+	if len(token) > 7 && strings.EqualFold(token[:7], "bearer ") {
+		token = strings.TrimSpace(token[7:])
+	}
+	return token
+}
+
+// principal identifies the caller: the subject of a valid access token, a
+// hash of any other Authorization value, or "anonymous".
+func principal(c *gin.Context) string {
+	token := bearerToken(c)
+	if token == "" {
+		return "anonymous"
+	}
+
+	if claims, err := parseToken(token); err == nil && claims.Subject != "" {
+		return "user:" + claims.Subject
+	}
+
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+// requireRole answers 401 unless the request carries a valid access token
+// and 403 unless the token has role. It returns whether to go on.
+func requireRole(c *gin.Context, role string) bool {
+	code, description := http.StatusUnauthorized, "a valid access token is required"
+
+	if claims, err := parseToken(bearerToken(c)); err == nil {
+		if claims.Role == role {
+			return true
+		}
+		code, description = http.StatusForbidden, "only "+role+" can do this"
+	}
+
+	c.AbortWithStatusJSON(code, models.ErrorWithDescription{
+		Code:        code,
+		Description: description,
+	})
+	return false
+}
@@ -1,12 +1,17 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"microservice/api/helpers"
 	"microservice/genproto/user_service"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 // @Security ApiKeyAuth
@@ -14,8 +19,8 @@ import (
 // @Summary       Create branch
 // @Description   API for creating branch
 // @Tags          branch
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param branch  body     user_service.CreateBranch true "branch"
 // @Success 200   {object} user_service.CreateBranch
 // @Failure 404   {object} models.ResponseError
@@ -28,13 +33,17 @@ func (h *handler) CreateBranch(c *gin.Context) {
 		err  error
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
+		return
+	}
+
+	if err := h.validateCreateBranch(&req); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating branch")
 		return
 	}
 
@@ -43,7 +52,10 @@ func (h *handler) CreateBranch(c *gin.Context) {
 		handleGrpcErrWithDescription(c, h.log, err, "failed to create branch")
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	setAuditResourceID(c, resp.Id)
+
+	h.decorateBranch(resp, time.Now())
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -51,11 +63,22 @@ func (h *handler) CreateBranch(c *gin.Context) {
 // @Summary        Get List Branch
 // @Description    API for getting list branch
 // @Tags           branch
-// @Accept         json
-// @Produce        json
-// @Param		   branch query string false "branches"
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param		   search query string false "search"
 // @Param		   page query int false "page"
 // @Param		   limit query int false "limit"
+// @Param		   cursor query string false "cursor from next_cursor of the previous page"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   active query boolean false "filter by active flag"
+// @Param		   shop_id query string false "filter by shop ID"
+// @Param		   open_now query boolean false "only branches open (true) or closed (false) right now; cannot be combined with cursor, and refused with 400 when more than BRANCH_SCAN_LIMIT branches match the other filters"
+// @Param		   fields query string false "comma separated fields to return"
+// @Param		   lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success 200    {object} user_service.GetListBranchResponse
 // @Failure 404    {object} models.ResponseError
 // @Failure 500    {object} models.ResponseError
@@ -66,30 +89,52 @@ func (h *handler) GetListBranch(c *gin.Context) {
 		err  error
 	)
 
-	req.Search = c.Query("search")
+	params, ok := h.parseListParams(c, branchSortFields)
+	if !ok {
+		return
+	}
+
+	req.Page = params.Page
+	req.Limit = params.Limit
+	req.Search = params.Search
+	req.Cursor = params.Cursor
+	req.Sort = params.Sort
+	req.CreatedAtFrom = params.CreatedAtFrom
+	req.CreatedAtTo = params.CreatedAtTo
+	req.IncludeDeleted = params.IncludeDeleted
+	req.OnlyDeleted = params.OnlyDeleted
+	req.ShopId = c.Query("shop_id")
 
-	page, err := strconv.ParseUint(c.DefaultQuery("page", "1"), 10, 64)
+	req.Active, err = parseBoolQueryParam(c, "active")
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing page")
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing active")
 		return
 	}
 
-	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "10"), 10, 64)
+	openNow, err := parseBoolQueryParam(c, "open_now")
 	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing limit")
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing open_now")
 		return
 	}
 
-	req.Page = page
-	req.Limit = limit
-
-	resp, err = h.grpcClient.BranchService().GetList(c, &req)
+	if openNow != nil {
+		resp, err = h.listOpenBranches(c, &req, *openNow)
+	} else {
+		resp, err = h.grpcClient.BranchService().GetList(readContext(c), &req)
+	}
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	now := time.Now()
+	for _, branch := range resp.Branches {
+		h.decorateBranch(branch, now)
+	}
+
+	setCacheControl(c, h.cfg.CacheBranchTTL)
+	setLinkHeader(c, params, resp.Count, resp.NextCursor)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -97,10 +142,14 @@ func (h *handler) GetListBranch(c *gin.Context) {
 // @Summary        Get a single branch by ID
 // @Description    API for getting a single branch by ID
 // @Tags           branch
-// @Accept         json
-// @Produce        json
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
 // @Param          id path string true "branch ID"
+// @Param          If-None-Match header string false "etag of the cached copy"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success        200 {object} user_service.Branch
+// @Success        304 "not modified"
 // @Failure        404 {object} models.ResponseError
 // @Failure        500 {object} models.ResponseError
 func (h *handler) GetBranchByID(c *gin.Context) {
@@ -114,13 +163,20 @@ func (h *handler) GetBranchByID(c *gin.Context) {
 		Id: id,
 	}
 
-	resp, err = h.grpcClient.BranchService().GetByID(c, req)
+	resp, err = h.grpcClient.BranchService().GetByID(readContext(c), req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.decorateBranch(resp, time.Now())
+
+	setCacheControl(c, h.cfg.CacheBranchTTL)
+	if WriteVariantETag(c, resp.UpdatedAt, branchOpenVariant(resp)) {
+		return
+	}
+
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -128,11 +184,13 @@ func (h *handler) GetBranchByID(c *gin.Context) {
 // @Summary         Update a branch by ID
 // @Description     API for updating a branch by ID
 // @Tags            branch
-// @Accept          json
-// @Produce         json
+// @Accept          json,application/x-protobuf,application/msgpack
+// @Produce         json,application/x-protobuf,application/msgpack
 // @Param           id path string true "branch ID"
 // @Param           branch body user_service.UpdateBranch true "branch"
+// @Param           If-Match header string false "etag the branch must still have"
 // @Success         200 {object} user_service.Branch
+// @Failure         412 {object} models.ResponseError
 // @Failure         404 {object} models.ResponseError
 // @Failure         500 {object} models.ResponseError
 func (h *handler) UpdateBranch(c *gin.Context) {
@@ -141,26 +199,38 @@ func (h *handler) UpdateBranch(c *gin.Context) {
 		req  user_service.UpdateBranch
 		resp *user_service.Branch
 		err  error
+		ok   bool
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
+		return
+	}
+
+	if err := h.validateUpdateBranch(&req); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating branch")
 		return
 	}
 
 	req.Id = id
+	req.ExpectedUpdatedAt, ok = ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	resp, err = h.grpcClient.BranchService().Update(c, &req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.decorateBranch(resp, time.Now())
+	WriteVariantETag(c, resp.UpdatedAt, branchOpenVariant(resp))
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -168,10 +238,12 @@ func (h *handler) UpdateBranch(c *gin.Context) {
 // @Summary       Delete a branch by ID
 // @Description   API for deleting a branch by ID
 // @Tags          branch
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         id path string true "branch ID"
+// @Param         If-Match header string false "etag the branch must still have"
 // @Success       200 {object} user_service.Empty3
+// @Failure       412 {object} models.ResponseError
 // @Failure       404 {object} models.ResponseError
 // @Failure       500 {object} models.ResponseError
 func (h *handler) DeleteBranch(c *gin.Context) {
@@ -181,8 +253,14 @@ func (h *handler) DeleteBranch(c *gin.Context) {
 		resp *user_service.Empty3
 	)
 
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	req := &user_service.BranchPrimaryKey{
-		Id: id,
+		Id:                id,
+		ExpectedUpdatedAt: expectedUpdatedAt,
 	}
 
 	resp, err = h.grpcClient.BranchService().Delete(c, req)
@@ -191,5 +269,321 @@ func (h *handler) DeleteBranch(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/branches/{id}/restore [POST]
+// @Summary       Restore a deleted branch
+// @Description   API for undoing the soft delete of a branch
+// @Tags          branch
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "branch ID"
+// @Param         If-Match header string false "etag the deleted branch must still have"
+// @Success       200 {object} user_service.Branch
+// @Failure       412 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) RestoreBranch(c *gin.Context) {
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.grpcClient.BranchService().Restore(c.Request.Context(), &user_service.BranchPrimaryKey{
+		Id:                c.Param("id"),
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to restore branch")
+		return
+	}
+
+	h.decorateBranch(resp, time.Now())
+	WriteVariantETag(c, resp.UpdatedAt, branchOpenVariant(resp))
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/admin/branches/{id} [DELETE]
+// @Summary       Purge a branch
+// @Description   API for permanently deleting a branch; admin only. The branch is gone for good and cannot be restored
+// @Tags          branch
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "branch ID"
+// @Param         confirm query string true "the branch ID again"
+// @Success       200 {object} user_service.Empty3
+// @Failure       400 {object} models.ResponseError
+// @Failure       401 {object} models.ResponseError
+// @Failure       403 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) PurgeBranch(c *gin.Context) {
+	id := c.Param("id")
+	if !h.confirmPurge(c, id) {
+		return
+	}
+
+	resp, err := h.grpcClient.BranchService().Purge(c.Request.Context(), &user_service.BranchPrimaryKey{Id: id})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to purge branch")
+		return
+	}
+
+	h.logPurge(c, "branch", id)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /bulkCreateBranch [post]
+// @Summary       Bulk create branches
+// @Description   API for creating many branches; with atomic=true all are created or none
+// @Tags          branch
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         branches body user_service.BulkCreateBranchRequest true "branches"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkCreateBranch(c *gin.Context) {
+	var req user_service.BulkCreateBranchRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_create_branches",
+		event: "branch.created",
+		count: len(req.Branches),
+		validate: func(i int) error {
+			if err := helpers.ValidatePhone(req.Branches[i].Phone); err != nil {
+				return err
+			}
+			return h.validateCreateBranch(req.Branches[i])
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			resp, err := h.grpcClient.BranchService().Create(ctx, req.Branches[i])
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			resp, err := h.grpcClient.BranchService().BulkCreate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]string, 0, len(resp.Branches))
+			for _, item := range resp.Branches {
+				ids = append(ids, item.Id)
+			}
+			return ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /bulkUpdateBranch [put]
+// @Summary       Bulk update branches
+// @Description   API for updating many branches by ID; with atomic=true all are updated or none
+// @Tags          branch
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         branches body user_service.BulkUpdateBranchRequest true "branches"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkUpdateBranch(c *gin.Context) {
+	var req user_service.BulkUpdateBranchRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_update_branches",
+		event: "branch.updated",
+		count: len(req.Branches),
+		validate: func(i int) error {
+			if req.Branches[i].Id == "" {
+				return errors.New("id is required")
+			}
+			if err := helpers.ValidatePhone(req.Branches[i].Phone); err != nil {
+				return err
+			}
+			return h.validateUpdateBranch(req.Branches[i])
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			resp, err := h.grpcClient.BranchService().Update(ctx, req.Branches[i])
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			resp, err := h.grpcClient.BranchService().BulkUpdate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]string, 0, len(resp.Branches))
+			for _, item := range resp.Branches {
+				ids = append(ids, item.Id)
+			}
+			return ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /bulkDeleteBranch [delete]
+// @Summary       Bulk delete branches
+// @Description   API for deleting many branches by ID; with atomic=true all are deleted or none
+// @Tags          branch
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         ids body user_service.BulkDeleteBranchRequest true "branch IDs"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkDeleteBranch(c *gin.Context) {
+	var req user_service.BulkDeleteBranchRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_delete_branches",
+		event: "branch.deleted",
+		count: len(req.Ids),
+		validate: func(i int) error {
+			if req.Ids[i] == "" {
+				return errors.New("id is required")
+			}
+			return nil
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			_, err := h.grpcClient.BranchService().Delete(ctx, &user_service.BranchPrimaryKey{Id: req.Ids[i]})
+			return req.Ids[i], err
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			if _, err := h.grpcClient.BranchService().BulkDelete(ctx, &req); err != nil {
+				return nil, err
+			}
+			return req.Ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /importBranch [post]
+// @Summary       Import branches from CSV
+// @Description   API for importing branches from a CSV file whose header names CreateBranch fields (or exported column titles). mode=dry_run returns a row-by-row validation report; mode=commit creates the rows in a background job polled at /jobs/{id}
+// @Tags          branch
+// @Accept        mpfd
+// @Produce       json
+// @Param         file formData file true "CSV file"
+// @Param         mode query string false "dry_run (default) or commit, which runs in a background job and needs an access token"
+// @Success 200   {object} models.ImportReport
+// @Success 202   {object} models.ImportReport
+// @Failure 400   {object} models.ImportReport
+// @Failure 500   {object} models.ResponseError
+func (h *handler) ImportBranch(c *gin.Context) {
+	h.runImport(c, importTarget{
+		name:    "branches",
+		event:   "branch.created",
+		message: func() proto.Message { return &user_service.CreateBranch{} },
+		validate: func(msg proto.Message) error {
+			req := msg.(*user_service.CreateBranch)
+			if err := helpers.ValidatePhone(req.Phone); err != nil {
+				return err
+			}
+			return h.validateCreateBranch(req)
+		},
+		create: func(ctx context.Context, msg proto.Message) (string, error) {
+			resp, err := h.grpcClient.BranchService().Create(ctx, msg.(*user_service.CreateBranch))
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+	})
+}
+
+// validateCreateBranch checks the opening hours and coordinates of a new
+// branch. A location written as "lat,lng" fills missing coordinates.
+func (h *handler) validateCreateBranch(req *user_service.CreateBranch) error {
+	if err := h.validateBranchHours(createBranchHours(req)); err != nil {
+		return err
+	}
+
+	var err error
+	req.Coordinates, err = branchCoordinates(req.Location, req.Coordinates)
+	return err
+}
+
+// validateUpdateBranch is validateCreateBranch for updates.
+func (h *handler) validateUpdateBranch(req *user_service.UpdateBranch) error {
+	if err := h.validateBranchHours(updateBranchHours(req)); err != nil {
+		return err
+	}
+
+	var err error
+	req.Coordinates, err = branchCoordinates(req.Location, req.Coordinates)
+	return err
+}
+
+// scanBranches calls visit for every branch matching req, reading pages of
+// MaxPageSize from the user service. It is for filters the user service
+// cannot apply itself. With a positive limit, a scan that would read more
+// branches than that fails with ResourceExhausted instead of returning part
+// of them.
+func (h *handler) scanBranches(ctx context.Context, req *user_service.GetListBranchRequest, limit int, visit func(*user_service.Branch)) error {
+	var (
+		scanned  int
+		pageSize = h.cfg.MaxPageSize
+		page     = proto.Clone(req).(*user_service.GetListBranchRequest)
+	)
+	if pageSize == 0 {
+		pageSize = 100
+	}
+
+	page.Cursor = ""
+	page.Limit = pageSize
+	for page.Page = 1; ; page.Page++ {
+		resp, err := h.grpcClient.BranchService().GetList(ctx, page)
+		if err != nil {
+			return err
+		}
+		if limit > 0 && (resp.Count > int64(limit) || scanned+len(resp.Branches) > limit) {
+			return status.Errorf(codes.ResourceExhausted, "more than %d branches match, narrow the filter", limit)
+		}
+
+		for _, branch := range resp.Branches {
+			visit(branch)
+		}
+
+		scanned += len(resp.Branches)
+		if len(resp.Branches) < int(pageSize) || int64(scanned) >= resp.Count {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
 }
@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservice/api/helpers"
+	"microservice/api/models"
+	"microservice/pkg/jobs"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bulkOperation describes a bulk request: how many items it has, how to
+// validate one of them, and how to send it to the user service either one
+// item at a time or, in atomic mode, as a single all-or-nothing call. The
+// items that succeed are published as event.
+type bulkOperation struct {
+	name     string
+	event    string
+	count    int
+	validate func(i int) error
+	single   func(ctx context.Context, i int) (string, error)
+	atomic   func(ctx context.Context) ([]string, error)
+}
+
+var (
+	errAtomicUnsupported = errors.New("user service does not support atomic bulk operations")
+	errAtomicInvalid     = errors.New("some items are invalid, nothing was applied")
+)
+
+// runBulk executes op and writes the per-item report. Without ?atomic=true
+// items are sent concurrently (at most BulkConcurrency in flight) and each
+// succeeds or fails on its own; the response is 207 if any item failed. With
+// ?atomic=true every item is validated first and the batch is sent through
+// the user service's Bulk* RPC, which applies all of it or none. With
+// ?async=true the operation runs as a background job and the report becomes
+// the job's result.
+func (h *handler) runBulk(c *gin.Context, op bulkOperation) {
+	if op.count == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorWithDescription{
+			Code:        http.StatusBadRequest,
+			Description: "bulk request has no items",
+		})
+		return
+	}
+
+	if op.count > h.cfg.BulkMaxItems {
+		c.JSON(http.StatusRequestEntityTooLarge, models.ErrorWithDescription{
+			Code:        http.StatusRequestEntityTooLarge,
+			Description: fmt.Sprintf("bulk request can have at most %d items", h.cfg.BulkMaxItems),
+		})
+		return
+	}
+
+	atomic, err := parseBoolQueryParam(c, "atomic")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing atomic")
+		return
+	}
+	isAtomic := atomic != nil && *atomic
+
+	async, ok := h.parseAsync(c)
+	if !ok {
+		return
+	}
+
+	if async {
+		reqCtx := c.Request.Context()
+		job, ok := h.submitJob(c, op.name, op.count, func(ctx context.Context, t *jobs.Task) (interface{}, error) {
+			if !isAtomic {
+				resp := h.executeBulk(ctx, op, t)
+				h.publish(reqCtx, bulkChanges(op.event, resp))
+				return resp, nil
+			}
+
+			resp, err := h.executeAtomicBulk(ctx, op)
+			if status.Code(err) == codes.Unimplemented {
+				return nil, errAtomicUnsupported
+			}
+			if err != nil {
+				return nil, err
+			}
+			if resp.Failed > 0 {
+				return resp, errAtomicInvalid
+			}
+			t.Add(op.count, 0)
+			h.publish(reqCtx, bulkChanges(op.event, resp))
+			return resp, nil
+		})
+		if ok {
+			c.JSON(http.StatusAccepted, job)
+		}
+		return
+	}
+
+	if isAtomic {
+		h.runAtomicBulk(c, op)
+		return
+	}
+
+	resp := h.executeBulk(c.Request.Context(), op, nil)
+	h.publish(c.Request.Context(), bulkChanges(op.event, resp))
+	h.writeBulk(c, resp)
+}
+
+// executeBulk sends the items of op one by one. t, if set, receives
+// progress; items not yet sent when ctx is cancelled fail.
+func (h *handler) executeBulk(ctx context.Context, op bulkOperation, t *jobs.Task) models.BulkResponse {
+	var (
+		resp = models.BulkResponse{Results: make([]models.BulkItemResult, op.count)}
+		sem  = make(chan struct{}, h.bulkConcurrency())
+		wg   sync.WaitGroup
+	)
+
+	progress := func(failed int) {
+		if t != nil {
+			t.Add(1, failed)
+		}
+	}
+
+	for i := 0; i < op.count; i++ {
+		resp.Results[i].Index = i
+
+		if err := op.validate(i); err != nil {
+			resp.Results[i].Error = bulkError(invalidArgument(err))
+			progress(1)
+			continue
+		}
+
+		if err := ctx.Err(); err != nil {
+			resp.Results[i].Error = bulkError(status.FromContextError(err).Err())
+			progress(1)
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			id, err := op.single(ctx, i)
+			if err != nil {
+				resp.Results[i].Error = bulkError(err)
+				progress(1)
+				return
+			}
+
+			resp.Results[i].Success = true
+			resp.Results[i].ID = id
+			progress(0)
+		}(i)
+	}
+
+	wg.Wait()
+	tallyBulk(&resp)
+	return resp
+}
+
+func (h *handler) runAtomicBulk(c *gin.Context, op bulkOperation) {
+	resp, err := h.executeAtomicBulk(c.Request.Context(), op)
+	if status.Code(err) == codes.Unimplemented {
+		c.JSON(http.StatusNotImplemented, models.ErrorWithDescription{
+			Code:        http.StatusNotImplemented,
+			Description: errAtomicUnsupported.Error(),
+		})
+		return
+	}
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to run atomic bulk operation")
+		return
+	}
+
+	if resp.Failed > 0 {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+
+	h.publish(c.Request.Context(), bulkChanges(op.event, resp))
+	h.writeBulk(c, resp)
+}
+
+// executeAtomicBulk validates every item and, if all are valid, sends op
+// through its Bulk* RPC. Invalid items are reported in the response without
+// an error.
+func (h *handler) executeAtomicBulk(ctx context.Context, op bulkOperation) (models.BulkResponse, error) {
+	resp := models.BulkResponse{Atomic: true}
+
+	for i := 0; i < op.count; i++ {
+		if err := op.validate(i); err != nil {
+			resp.Results = append(resp.Results, models.BulkItemResult{
+				Index: i,
+				Error: bulkError(invalidArgument(err)),
+			})
+		}
+	}
+
+	if len(resp.Results) > 0 {
+		tallyBulk(&resp)
+		return resp, nil
+	}
+
+	ids, err := op.atomic(ctx)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Results = make([]models.BulkItemResult, op.count)
+	for i := range resp.Results {
+		resp.Results[i] = models.BulkItemResult{Index: i, Success: true}
+		if i < len(ids) {
+			resp.Results[i].ID = ids[i]
+		}
+	}
+
+	tallyBulk(&resp)
+	return resp, nil
+}
+
+func (h *handler) writeBulk(c *gin.Context, resp models.BulkResponse) {
+	code := http.StatusOK
+	if resp.Failed > 0 {
+		code = http.StatusMultiStatus
+	}
+
+	c.JSON(code, resp)
+}
+
+func tallyBulk(resp *models.BulkResponse) {
+	resp.Succeeded, resp.Failed = 0, 0
+	for _, result := range resp.Results {
+		if result.Success {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+}
+
+func (h *handler) bulkConcurrency() int {
+	if h.cfg.BulkConcurrency < 1 {
+		return 1
+	}
+	return h.cfg.BulkConcurrency
+}
+
+// bulkError converts a gRPC error into the per-item error, using the same
+// status mapping as handleGrpcErrWithDescription.
+func bulkError(err error) *models.ErrorWithDescription {
+	st, _ := status.FromError(err)
+
+	code := http.StatusBadRequest
+	switch st.Code() {
+	case codes.Internal, codes.Unavailable, codes.AlreadyExists, codes.Unknown:
+		code = http.StatusInternalServerError
+	case codes.NotFound:
+		code = http.StatusNotFound
+	case codes.FailedPrecondition:
+		code = http.StatusPreconditionFailed
+	case codes.DeadlineExceeded:
+		code = http.StatusGatewayTimeout
+	}
+
+	return &models.ErrorWithDescription{
+		Code:        code,
+		Description: st.Message(),
+	}
+}
+
+func validatePhoneAndEmail(phone, email string) error {
+	if err := helpers.ValidatePhone(phone); err != nil {
+		return err
+	}
+	return helpers.ValidateEmailAddress(email)
+}
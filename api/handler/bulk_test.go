@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"microservice/api/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBulkError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want models.ErrorWithDescription
+	}{
+		{status.Error(codes.InvalidArgument, "bad phone"), models.ErrorWithDescription{Code: http.StatusBadRequest, Description: "bad phone"}},
+		{status.Error(codes.NotFound, "no rows"), models.ErrorWithDescription{Code: http.StatusNotFound, Description: "no rows"}},
+		{status.Error(codes.FailedPrecondition, "modified"), models.ErrorWithDescription{Code: http.StatusPreconditionFailed, Description: "modified"}},
+		{status.Error(codes.DeadlineExceeded, "slow"), models.ErrorWithDescription{Code: http.StatusGatewayTimeout, Description: "slow"}},
+		{status.Error(codes.Unavailable, "down"), models.ErrorWithDescription{Code: http.StatusInternalServerError, Description: "down"}},
+		{errors.New("plain"), models.ErrorWithDescription{Code: http.StatusInternalServerError, Description: "plain"}},
+	}
+
+	for _, tt := range tests {
+		if got := bulkError(tt.err); *got != tt.want {
+			t.Errorf("bulkError(%v) = %+v, want %+v", tt.err, *got, tt.want)
+		}
+	}
+}
+
+func TestTallyBulk(t *testing.T) {
+	resp := models.BulkResponse{Succeeded: 9, Results: []models.BulkItemResult{{Success: true}, {}, {Success: true}}}
+	tallyBulk(&resp)
+	if resp.Succeeded != 2 || resp.Failed != 1 {
+		t.Errorf("tallyBulk = %d succeeded, %d failed, want 2 and 1", resp.Succeeded, resp.Failed)
+	}
+}
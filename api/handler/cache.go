@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"microservice/pkg/grpc_client"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readContext is the context for cached reads. Clients sending
+// Cache-Control: no-cache (or Pragma: no-cache) skip the gateway's cache.
+func readContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if noCache(c) {
+		return grpc_client.WithoutCache(ctx)
+	}
+	return ctx
+}
+
+// noCache reports whether the client asked for a fresh response.
+func noCache(c *gin.Context) bool {
+	return strings.Contains(strings.ToLower(c.GetHeader("Cache-Control")), "no-cache") ||
+		strings.Contains(strings.ToLower(c.GetHeader("Pragma")), "no-cache")
+}
+
+// setCacheControl lets clients cache the response for as long as the gateway
+// does. Shared caches key it by the Accept and Authorization headers as well
+// as the URL, and only the client keeps a response to an authorized request.
+// Listings of soft-deleted records are admin only and are never stored.
+func setCacheControl(c *gin.Context, ttl time.Duration) {
+	vary(c, "Accept")
+	vary(c, "Authorization")
+	switch {
+	case listsDeleted(c):
+		c.Header("Cache-Control", "no-store")
+	case ttl <= 0:
+		c.Header("Cache-Control", "no-cache")
+	case c.GetHeader("Authorization") != "":
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(ttl.Seconds())))
+	default:
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(ttl.Seconds())))
+	}
+}
+
+// listsDeleted reports whether the request asks for soft-deleted records.
+func listsDeleted(c *gin.Context) bool {
+	for _, key := range []string{"include_deleted", "only_deleted"} {
+		if value, err := parseBoolQueryParam(c, key); err == nil && value != nil && *value {
+			return true
+		}
+	}
+	return false
+}
+
+// @Security ApiKeyAuth
+// @Router         /debug/vars [GET]
+// @Summary        Read runtime and cache counters
+// @Description    API for reading the expvar counters, such as memory statistics and the hit rates of the read caches; admin only
+// @Tags           debug
+// @Produce        json
+// @Success        200 {object} object
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+func (h *handler) DebugVars(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// vary marks the response as depending on the request header, such as Accept,
+// which picks its encoding.
+func vary(c *gin.Context, header string) {
+	for _, value := range c.Writer.Header().Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), header) {
+				return
+			}
+		}
+	}
+	c.Writer.Header().Add("Vary", header)
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservice/api/models"
+	"microservice/pkg/audit"
+	"microservice/pkg/events"
+	"microservice/pkg/logger"
+	"microservice/pkg/webhook"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const publishTimeout = 10 * time.Second
+
+// Change events are named <resource>.<action>, e.g. customer.created.
+var (
+	eventResources = []string{"customer", "user", "seller", "branch", "shop"}
+	eventActions   = []string{"created", "updated", "deleted", "restored", "purged"}
+)
+
+// change is one resource changed by a request.
+type change struct {
+	event        string
+	resourceType string
+	resourceID   string
+	object       json.RawMessage
+}
+
+// changeAction names what a successful request did to its resource.
+func changeAction(method, route string) string {
+	switch {
+	case strings.HasSuffix(route, "/restore"):
+		return "restored"
+	case strings.HasPrefix(route, "/v1/admin/") && method == http.MethodDelete:
+		return "purged"
+	case method == http.MethodDelete:
+		return "deleted"
+	case method == http.MethodPut || method == http.MethodPatch:
+		return "updated"
+	case method == http.MethodPost:
+		return "created"
+	}
+	return ""
+}
+
+// requestChange is the change a successful request made to the resource
+// named in its route or created by its handler. Handlers that change several
+// resources publish them themselves.
+func requestChange(e audit.Entry) []change {
+	action := changeAction(e.Method, e.Route)
+	if action == "" || e.ResourceID == "" || !contains(eventResources, e.ResourceType) {
+		return nil
+	}
+
+	object := e.After
+	if action == "deleted" || action == "purged" {
+		object = e.Before
+	}
+	return []change{{event: e.ResourceType + "." + action, resourceType: e.ResourceType, resourceID: e.ResourceID, object: object}}
+}
+
+// changesOf returns a change named event, e.g. seller.deleted, for each of
+// ids.
+func changesOf(event string, ids []string) []change {
+	resourceType, _, _ := strings.Cut(event, ".")
+	changes := make([]change, 0, len(ids))
+	for _, id := range ids {
+		if id != "" {
+			changes = append(changes, change{event: event, resourceType: resourceType, resourceID: id})
+		}
+	}
+	return changes
+}
+
+// bulkChanges returns a change named event for each item of a bulk request
+// that succeeded.
+func bulkChanges(event string, resp models.BulkResponse) []change {
+	ids := make([]string, 0, resp.Succeeded)
+	for _, item := range resp.Results {
+		if item.Success {
+			ids = append(ids, item.ID)
+		}
+	}
+	return changesOf(event, ids)
+}
+
+// publish sends changes to the stream subscribers and webhooks in the
+// background, as made by the request ctx belongs to. ctx may end first.
+func (h *handler) publish(ctx context.Context, changes []change) {
+	if len(changes) == 0 || (h.webhooks == nil && h.events == nil) {
+		return
+	}
+	go h.publishChanges(context.WithoutCancel(ctx), changes, requestIDFrom(ctx))
+}
+
+// publishChanges sends an event per change to the stream subscribers and
+// webhooks, reading the resources bulk requests did not return.
+func (h *handler) publishChanges(ctx context.Context, changes []change, requestID string) {
+	for _, ch := range changes {
+		if ch.object == nil && !strings.HasSuffix(ch.event, ".deleted") && !strings.HasSuffix(ch.event, ".purged") {
+			ch.object = h.auditSnapshot(ctx, ch.resourceType, ch.resourceID)
+		}
+
+		if h.events != nil {
+			h.events.Publish(events.Event{
+				Type:         ch.event,
+				ResourceType: ch.resourceType,
+				ResourceID:   ch.resourceID,
+				Object:       ch.object,
+				RequestID:    requestID,
+			})
+		}
+
+		if h.webhooks == nil {
+			continue
+		}
+		ev, err := webhook.NewEvent(ch.event, ch.resourceType, ch.resourceID, ch.object, requestID)
+		if err != nil {
+			h.log.Error("failed to create webhook event", logger.String("event", ch.event), logger.String("id", ch.resourceID), logger.Error(err))
+			continue
+		}
+		err = func() error {
+			ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+			defer cancel()
+			return h.webhooks.Publish(ctx, ev)
+		}()
+		if err != nil {
+			h.log.Error("failed to publish webhook event", logger.String("event", ev.Type), logger.String("id", ch.resourceID), logger.Error(err))
+		}
+	}
+}
+
+// validateEventPattern accepts <resource>.<action>, <resource>.* and *.
+func validateEventPattern(pattern string) error {
+	resource, action, _ := strings.Cut(pattern, ".")
+	if pattern == "*" || (contains(eventResources, resource) && (action == "*" || contains(eventActions, action))) {
+		return nil
+	}
+	return fmt.Errorf("unknown event %q, expected <%s>.<%s>, <resource>.* or *",
+		pattern, strings.Join(eventResources, "|"), strings.Join(eventActions, "|"))
+}
@@ -1,12 +1,14 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"microservice/api/helpers"
 	"microservice/genproto/user_service"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
 )
 
 // @Security ApiKeyAuth
@@ -14,10 +16,11 @@ import (
 // @Summary       Create customer
 // @Description   API for creating customer
 // @Tags          customer
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param order   body     user_service.CreateCustomer true "customer"
 // @Success 200   {object} user_service.CreateCustomer
+// @Failure 400   {object} models.ResponseError
 // @Failure 404   {object} models.ResponseError
 // @Failure 500   {object} models.ResponseError
 func (h *handler) CreateCustomer(c *gin.Context) {
@@ -28,18 +31,18 @@ func (h *handler) CreateCustomer(c *gin.Context) {
 		err  error
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
 		return
 	}
 
 	if err := helpers.ValidateEmailAddress(req.Email); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "erroe while validating email"+req.Email)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating email "+req.Email)
+		return
 	}
 
 	resp, err = h.grpcClient.UserService().Create(c.Request.Context(), &req)
@@ -47,7 +50,8 @@ func (h *handler) CreateCustomer(c *gin.Context) {
 		handleGrpcErrWithDescription(c, h.log, err, "failed to create customer")
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	setAuditResourceID(c, resp.Id)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -55,11 +59,20 @@ func (h *handler) CreateCustomer(c *gin.Context) {
 // @Summary        Get List Customer
 // @Description    API for getting list customer
 // @Tags           customer
-// @Accept         json
-// @Produce        json
-// @Param		   customer query string false "customers"
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param		   search query string false "search"
 // @Param		   page query int false "page"
 // @Param		   limit query int false "limit"
+// @Param		   cursor query string false "cursor from next_cursor of the previous page"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   gender query string false "filter by gender"
+// @Param		   fields query string false "comma separated fields to return"
+// @Param		   lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success 200    {object} user_service.GetListCustomerResponse
 // @Failure 404    {object} models.ResponseError
 // @Failure 500    {object} models.ResponseError
@@ -70,22 +83,22 @@ func (h *handler) GetListCustomer(c *gin.Context) {
 		err  error
 	)
 
-	req.Search = c.Query("search")
-
-	page, err := strconv.ParseUint(c.DefaultQuery("page", "1"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing page")
+	params, ok := h.parseListParams(c, customerSortFields)
+	if !ok {
 		return
 	}
 
-	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "10"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing limit")
-		return
-	}
+	req.Page = params.Page
+	req.Limit = params.Limit
+	req.Search = params.Search
+	req.Cursor = params.Cursor
+	req.Sort = params.Sort
+	req.CreatedAtFrom = params.CreatedAtFrom
+	req.CreatedAtTo = params.CreatedAtTo
+	req.IncludeDeleted = params.IncludeDeleted
+	req.OnlyDeleted = params.OnlyDeleted
 
-	req.Page = page
-	req.Limit = limit
+	req.Gender = c.Query("gender")
 
 	resp, err = h.grpcClient.UserService().GetList(c, &req)
 	if err != nil {
@@ -93,7 +106,8 @@ func (h *handler) GetListCustomer(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	setLinkHeader(c, params, resp.Count, resp.NextCursor)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -101,10 +115,14 @@ func (h *handler) GetListCustomer(c *gin.Context) {
 // @Summary        Get a single customer by ID
 // @Description    API for getting a single category by ID
 // @Tags           customer
-// @Accept         json
-// @Produce        json
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
 // @Param          id path string true "customer ID"
+// @Param          If-None-Match header string false "etag of the cached copy"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success        200 {object} user_service.Customer
+// @Success        304 "not modified"
 // @Failure        404 {object} models.ResponseError
 // @Failure        500 {object} models.ResponseError
 func (h *handler) GetCustomerByID(c *gin.Context) {
@@ -124,7 +142,11 @@ func (h *handler) GetCustomerByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	if WriteETag(c, resp.UpdatedAt) {
+		return
+	}
+
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -132,11 +154,14 @@ func (h *handler) GetCustomerByID(c *gin.Context) {
 // @Summary         Update a customer by ID
 // @Description     API for updating a customer by ID
 // @Tags            customer
-// @Accept          json
-// @Produce         json
+// @Accept          json,application/x-protobuf,application/msgpack
+// @Produce         json,application/x-protobuf,application/msgpack
 // @Param           id path string true "customer ID"
 // @Param           category body user_service.UpdateCustomer true "customer"
+// @Param           If-Match header string false "etag the customer must still have"
 // @Success         200 {object} user_service.Customer
+// @Failure         400 {object} models.ResponseError
+// @Failure         412 {object} models.ResponseError
 // @Failure         404 {object} models.ResponseError
 // @Failure         500 {object} models.ResponseError
 func (h *handler) UpdateCustomer(c *gin.Context) {
@@ -145,30 +170,37 @@ func (h *handler) UpdateCustomer(c *gin.Context) {
 		req  user_service.UpdateCustomer
 		resp *user_service.Customer
 		err  error
+		ok   bool
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
 		return
 	}
 
 	if err := helpers.ValidateEmailAddress(req.Email); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "erroe while validating email"+req.Email)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating email "+req.Email)
+		return
 	}
 
 	req.Id = id
+	req.ExpectedUpdatedAt, ok = ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	resp, err = h.grpcClient.UserService().Update(c, &req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -176,10 +208,12 @@ func (h *handler) UpdateCustomer(c *gin.Context) {
 // @Summary       Delete a customer by ID
 // @Description   API for deleting a customer by ID
 // @Tags          customer
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         id path string true "customer ID"
+// @Param         If-Match header string false "etag the customer must still have"
 // @Success       200 {object} user_service.Empty
+// @Failure       412 {object} models.ResponseError
 // @Failure       404 {object} models.ResponseError
 // @Failure       500 {object} models.ResponseError
 func (h *handler) DeleteCustomer(c *gin.Context) {
@@ -189,8 +223,14 @@ func (h *handler) DeleteCustomer(c *gin.Context) {
 		resp *user_service.Empty
 	)
 
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	req := &user_service.CustomerPrimaryKey{
-		Id: id,
+		Id:                id,
+		ExpectedUpdatedAt: expectedUpdatedAt,
 	}
 
 	resp, err = h.grpcClient.UserService().Delete(c, req)
@@ -199,5 +239,306 @@ func (h *handler) DeleteCustomer(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/customers/{id}/restore [POST]
+// @Summary       Restore a deleted customer
+// @Description   API for undoing the soft delete of a customer
+// @Tags          customer
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "customer ID"
+// @Param         If-Match header string false "etag the deleted customer must still have"
+// @Success       200 {object} user_service.Customer
+// @Failure       412 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) RestoreCustomer(c *gin.Context) {
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.grpcClient.UserService().Restore(c.Request.Context(), &user_service.CustomerPrimaryKey{
+		Id:                c.Param("id"),
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to restore customer")
+		return
+	}
+
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/admin/customers/{id} [DELETE]
+// @Summary       Purge a customer
+// @Description   API for permanently deleting a customer; admin only. The customer is gone for good and cannot be restored
+// @Tags          customer
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "customer ID"
+// @Param         confirm query string true "the customer ID again"
+// @Success       200 {object} user_service.Empty
+// @Failure       400 {object} models.ResponseError
+// @Failure       401 {object} models.ResponseError
+// @Failure       403 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) PurgeCustomer(c *gin.Context) {
+	id := c.Param("id")
+	if !h.confirmPurge(c, id) {
+		return
+	}
+
+	resp, err := h.grpcClient.UserService().Purge(c.Request.Context(), &user_service.CustomerPrimaryKey{Id: id})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to purge customer")
+		return
+	}
+
+	h.logPurge(c, "customer", id)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /bulkCreateCustomer [post]
+// @Summary       Bulk create customers
+// @Description   API for creating many customers; with atomic=true all are created or none
+// @Tags          customer
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         customers body user_service.BulkCreateCustomerRequest true "customers"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkCreateCustomer(c *gin.Context) {
+	var req user_service.BulkCreateCustomerRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_create_customers",
+		event: "customer.created",
+		count: len(req.Customers),
+		validate: func(i int) error {
+			return validatePhoneAndEmail(req.Customers[i].Phone, req.Customers[i].Email)
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			resp, err := h.grpcClient.UserService().Create(ctx, req.Customers[i])
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			resp, err := h.grpcClient.UserService().BulkCreate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]string, 0, len(resp.Customers))
+			for _, item := range resp.Customers {
+				ids = append(ids, item.Id)
+			}
+			return ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /bulkUpdateCustomer [put]
+// @Summary       Bulk update customers
+// @Description   API for updating many customers by ID; with atomic=true all are updated or none
+// @Tags          customer
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         customers body user_service.BulkUpdateCustomerRequest true "customers"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkUpdateCustomer(c *gin.Context) {
+	var req user_service.BulkUpdateCustomerRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_update_customers",
+		event: "customer.updated",
+		count: len(req.Customers),
+		validate: func(i int) error {
+			if req.Customers[i].Id == "" {
+				return errors.New("id is required")
+			}
+			return validatePhoneAndEmail(req.Customers[i].Phone, req.Customers[i].Email)
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			resp, err := h.grpcClient.UserService().Update(ctx, req.Customers[i])
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			resp, err := h.grpcClient.UserService().BulkUpdate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]string, 0, len(resp.Customers))
+			for _, item := range resp.Customers {
+				ids = append(ids, item.Id)
+			}
+			return ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /bulkDeleteCustomer [delete]
+// @Summary       Bulk delete customers
+// @Description   API for deleting many customers by ID; with atomic=true all are deleted or none
+// @Tags          customer
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         ids body user_service.BulkDeleteCustomerRequest true "customer IDs"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkDeleteCustomer(c *gin.Context) {
+	var req user_service.BulkDeleteCustomerRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_delete_customers",
+		event: "customer.deleted",
+		count: len(req.Ids),
+		validate: func(i int) error {
+			if req.Ids[i] == "" {
+				return errors.New("id is required")
+			}
+			return nil
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			_, err := h.grpcClient.UserService().Delete(ctx, &user_service.CustomerPrimaryKey{Id: req.Ids[i]})
+			return req.Ids[i], err
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			if _, err := h.grpcClient.UserService().BulkDelete(ctx, &req); err != nil {
+				return nil, err
+			}
+			return req.Ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router         /exportCustomer [GET]
+// @Summary        Export customers
+// @Description    API for exporting customers as CSV or XLSX, streamed page by page
+// @Tags           customer
+// @Produce        text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		   format query string false "csv (default) or xlsx"
+// @Param		   columns query string false "comma separated fields, default: id, firstname, lastname, phone, email, gender, date_of_birth, languange, created_at"
+// @Param		   lang query string false "header language: uz, ru or en (default)"
+// @Param		   search query string false "search"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   gender query string false "filter by gender"
+// @Param		   async query boolean false "build the file in a background job, which needs an access token"
+// @Success 200    {file} file
+// @Success 202    {object} jobs.Job
+// @Failure 400    {object} models.ResponseError
+// @Failure 500    {object} models.ResponseError
+func (h *handler) ExportCustomer(c *gin.Context) {
+	params, ok := h.parseListParams(c, customerSortFields)
+	if !ok {
+		return
+	}
+	gender := c.Query("gender")
+
+	h.runExport(c, exportSource{
+		name:    "customers",
+		item:    (&user_service.Customer{}).ProtoReflect().Descriptor(),
+		columns: []string{"id", "firstname", "lastname", "phone", "email", "gender", "date_of_birth", "languange", "created_at"},
+		fetch: func(ctx context.Context, page, limit uint64, cursor string) (exportPage, error) {
+			resp, err := h.grpcClient.UserService().GetList(ctx, &user_service.GetListCustomerRequest{
+				Page:           page,
+				Limit:          limit,
+				Cursor:         cursor,
+				Search:         params.Search,
+				Sort:           params.Sort,
+				CreatedAtFrom:  params.CreatedAtFrom,
+				CreatedAtTo:    params.CreatedAtTo,
+				IncludeDeleted: params.IncludeDeleted,
+				OnlyDeleted:    params.OnlyDeleted,
+				Gender:         gender,
+			})
+			if err != nil {
+				return exportPage{}, err
+			}
+
+			result := exportPage{count: resp.Count, nextCursor: resp.NextCursor}
+			for _, item := range resp.Customers {
+				result.items = append(result.items, item)
+			}
+			return result, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /importCustomer [post]
+// @Summary       Import customers from CSV
+// @Description   API for importing customers from a CSV file whose header names CreateCustomer fields (or exported column titles). mode=dry_run returns a row-by-row validation report; mode=commit creates the rows in a background job polled at /jobs/{id}
+// @Tags          customer
+// @Accept        mpfd
+// @Produce       json
+// @Param         file formData file true "CSV file"
+// @Param         mode query string false "dry_run (default) or commit, which runs in a background job and needs an access token"
+// @Success 200   {object} models.ImportReport
+// @Success 202   {object} models.ImportReport
+// @Failure 400   {object} models.ImportReport
+// @Failure 500   {object} models.ResponseError
+func (h *handler) ImportCustomer(c *gin.Context) {
+	h.runImport(c, importTarget{
+		name:    "customers",
+		event:   "customer.created",
+		message: func() proto.Message { return &user_service.CreateCustomer{} },
+		validate: func(msg proto.Message) error {
+			req := msg.(*user_service.CreateCustomer)
+			return validatePhoneAndEmail(req.Phone, req.Email)
+		},
+		create: func(ctx context.Context, msg proto.Message) (string, error) {
+			resp, err := h.grpcClient.UserService().Create(ctx, msg.(*user_service.CreateCustomer))
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+	})
 }
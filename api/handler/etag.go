@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"microservice/api/models"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// ETags are the entity's updated_at encoded so that the gateway can turn an
+// If-Match header back into the version the user service has to compare.
+// Responses with gateway-computed fields, or in another encoding, fields= or
+// lang= than the default, append a variant after a dot so that a cached copy
+// is revalidated when those change; the variant is ignored when the tag comes
+// back in If-Match.
+
+func makeETag(updatedAt string) string {
+	return makeVariantETag(updatedAt, "")
+}
+
+func makeVariantETag(updatedAt, variant string) string {
+	tag := base64.RawURLEncoding.EncodeToString([]byte(updatedAt))
+	if variant != "" {
+		tag += "." + variant
+	}
+	return `"` + tag + `"`
+}
+
+func parseETag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if strings.HasPrefix(tag, "W/") {
+		return "", errors.New("weak etag is not allowed in If-Match")
+	}
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return "", errors.New("etag must be a quoted string")
+	}
+
+	tag, _, _ = strings.Cut(tag[1:len(tag)-1], ".")
+	updatedAt, err := base64.RawURLEncoding.DecodeString(tag)
+	if err != nil {
+		return "", errors.New("unknown etag")
+	}
+
+	return string(updatedAt), nil
+}
+
+// ParseIfMatchHeader returns the updated_at value the request expects the
+// entity to have. An absent header or "*" yields an empty version, which the
+// user service treats as "no check". On a malformed header it writes 412 and
+// returns false.
+func ParseIfMatchHeader(c *gin.Context) (string, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return "", true
+	}
+
+	if strings.Contains(header, ",") {
+		c.JSON(http.StatusPreconditionFailed, models.ErrorWithDescription{
+			Code:        http.StatusPreconditionFailed,
+			Description: "If-Match must contain a single etag",
+		})
+		return "", false
+	}
+
+	updatedAt, err := parseETag(header)
+	if err != nil {
+		c.JSON(http.StatusPreconditionFailed, models.ErrorWithDescription{
+			Code:        http.StatusPreconditionFailed,
+			Description: err.Error(),
+		})
+		return "", false
+	}
+
+	return updatedAt, true
+}
+
+// hasIfMatch reports whether the request names the version it expects in
+// If-Match, so that a FailedPrecondition from the user service means the
+// entity has changed since.
+func hasIfMatch(c *gin.Context) bool {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	return header != "" && header != "*"
+}
+
+// WriteETag sets the ETag header for an entity and answers 304 if it matches
+// If-None-Match. It returns true when the response has already been written.
+func WriteETag(c *gin.Context, updatedAt string) bool {
+	return WriteVariantETag(c, updatedAt, "")
+}
+
+// WriteVariantETag is WriteETag for a response that also depends on
+// variant, such as a branch's computed open state.
+func WriteVariantETag(c *gin.Context, updatedAt, variant string) bool {
+	if updatedAt == "" {
+		return false
+	}
+
+	if rv := representationVariant(c); rv != "" {
+		variant = strings.TrimPrefix(variant+"."+rv, ".")
+	}
+	etag := makeVariantETag(updatedAt, variant)
+	c.Header("ETag", etag)
+	vary(c, "Accept")
+
+	if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+		return false
+	}
+
+	for _, tag := range strings.Split(c.GetHeader("If-None-Match"), ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			c.Status(http.StatusNotModified)
+			return true
+		}
+	}
+
+	return false
+}
+
+// representationVariant identifies the encoding, fields= and lang= of the
+// response, which change its bytes but not the entity's version. It is
+// empty for the default JSON representation.
+func representationVariant(c *gin.Context) string {
+	format := c.NegotiateFormat(offeredFormats...)
+	if format == binding.MIMEJSON {
+		format = ""
+	}
+	fields, lang := c.Query("fields"), c.Query("lang")
+	if format == "" && fields == "" && lang == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(format + "\x00" + fields + "\x00" + lang))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"microservice/pkg/events"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sseRetry is the reconnection delay suggested to EventSource clients.
+const sseRetry = 3 * time.Second
+
+// publicEventResources are streamed to every signed-in caller; customer and
+// user events carry personal data and are streamed to admins only.
+var publicEventResources = []string{"shop", "seller", "branch"}
+
+func canSeeEvent(role, resourceType string) bool {
+	return role == adminRole || contains(publicEventResources, resourceType)
+}
+
+// streamClaims authenticates a stream. Browsers cannot set headers on
+// EventSource and WebSocket requests, so the token may also be passed as
+// ?access_token=.
+func streamClaims(c *gin.Context) (tokenClaims, bool) {
+	token := bearerToken(c)
+	if token == "" {
+		token = c.Query("access_token")
+	}
+
+	claims, err := parseToken(token)
+	if err != nil {
+		abortWithError(c, http.StatusUnauthorized, "a valid access token is required")
+		return claims, false
+	}
+	return claims, true
+}
+
+// RequestLogger is gin.Logger with the access_token query parameter
+// streamClaims accepts redacted, so that tokens do not end up in logs.
+func RequestLogger() gin.HandlerFunc {
+	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+		var statusColor, methodColor, resetColor string
+		if param.IsOutputColor() {
+			statusColor = param.StatusCodeColor()
+			methodColor = param.MethodColor()
+			resetColor = param.ResetColor()
+		}
+
+		if param.Latency > time.Minute {
+			param.Latency = param.Latency.Truncate(time.Second)
+		}
+		return fmt.Sprintf("[GIN] %v |%s %3d %s| %13v | %15s |%s %-7s %s %#v\n%s",
+			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+			statusColor, param.StatusCode, resetColor,
+			param.Latency,
+			param.ClientIP,
+			methodColor, param.Method, resetColor,
+			redactAccessToken(param.Path),
+			param.ErrorMessage,
+		)
+	})
+}
+
+// redactAccessToken replaces the value of access_token in the query of
+// path, leaving the rest of it as it was sent.
+func redactAccessToken(path string) string {
+	base, query, ok := strings.Cut(path, "?")
+	if !ok {
+		return path
+	}
+
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if key, err := url.QueryUnescape(key); err == nil && key == "access_token" {
+			params[i] = "access_token=REDACTED"
+		}
+	}
+	return base + "?" + strings.Join(params, "&")
+}
+
+// parseEventPatterns reads a comma separated list of event patterns from
+// the query parameter key.
+func parseEventPatterns(c *gin.Context, key string) ([]string, error) {
+	var patterns []string
+	for _, pattern := range strings.Split(c.Query(key), ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if err := validateEventPattern(pattern); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+func parseLastEventID(c *gin.Context) (uint64, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.New("Last-Event-ID must be an event id")
+	}
+	return id, nil
+}
+
+// @Security ApiKeyAuth
+// @Router         /v1/events [GET]
+// @Summary        Stream entity changes
+// @Description    Server-Sent Events stream of created, updated, deleted, restored and purged resources. Every event is sent as a message whose id is the event id and whose data is the event JSON. Clients resuming with Last-Event-ID (or ?last_event_id=) first get the buffered events after it; when some were already dropped from the buffer a "reset" event is sent so that the client reloads its data. A ": heartbeat" comment is sent when the stream is idle. Admins see every resource, other callers shops, sellers and branches. The token may be passed as ?access_token= for EventSource clients
+// @Tags           event
+// @Produce        text/event-stream
+// @Param          events query string false "comma separated event types, <resource>.* or *, e.g. shop.*,seller.created"
+// @Param          last_event_id query string false "resume after this event id, if the Last-Event-ID header is not set"
+// @Param          access_token query string false "access token, if the Authorization header is not set"
+// @Success        200 {object} events.Event
+// @Failure        400 {object} models.ResponseError
+// @Failure        401 {object} models.ResponseError
+// @Failure        503 {object} models.ResponseError
+func (h *handler) StreamEvents(c *gin.Context) {
+	claims, ok := streamClaims(c)
+	if !ok {
+		return
+	}
+
+	patterns, err := parseEventPatterns(c, "events")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing events")
+		return
+	}
+	lastID, err := parseLastEventID(c)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing Last-Event-ID")
+		return
+	}
+
+	if h.events == nil {
+		abortWithError(c, http.StatusServiceUnavailable, "the event stream is disabled")
+		return
+	}
+
+	sub, backlog, complete := h.events.Subscribe(lastID, h.cfg.EventClientBuffer)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds())
+	if !complete {
+		fmt.Fprintf(w, "event: reset\ndata: {\"last_event_id\":%d}\n\n", lastID)
+	}
+	send := func(ev events.Event) {
+		if !canSeeEvent(claims.Role, ev.ResourceType) || !events.Match(patterns, ev.Type) {
+			return
+		}
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return
+		}
+		fmt.Fprintf(w, "id: %d\ndata: %s\n\n", ev.ID, data)
+	}
+	for _, ev := range backlog {
+		send(ev)
+	}
+	w.Flush()
+
+	interval := h.cfg.EventHeartbeat
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	heartbeat := time.NewTicker(interval)
+	defer heartbeat.Stop()
+
+	// The stream ends with the token; the client reconnects with a new one.
+	var expired <-chan time.Time
+	if claims.ExpiresAt != 0 {
+		timer := time.NewTimer(time.Until(time.Unix(claims.ExpiresAt, 0)))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				// The client fell behind; it resumes from the buffer when it
+				// reconnects.
+				return
+			}
+			send(ev)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-expired:
+			return
+		case <-c.Request.Context().Done():
+			return
+		}
+		w.Flush()
+	}
+}
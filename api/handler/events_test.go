@@ -0,0 +1,45 @@
+package handler
+
+import "testing"
+
+func TestRedactAccessToken(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/v1/events", "/v1/events"},
+		{"/v1/events?events=shop.*", "/v1/events?events=shop.*"},
+		{"/v1/events?access_token=eyJ.a.b", "/v1/events?access_token=REDACTED"},
+		{"/v1/events/ws?events=shop.*&access_token=eyJ.a.b&x=1", "/v1/events/ws?events=shop.*&access_token=REDACTED&x=1"},
+		{"/v1/events?access%5Ftoken=eyJ.a.b", "/v1/events?access_token=REDACTED"},
+		{"/v1/events?access_token", "/v1/events?access_token=REDACTED"},
+		{"/v1/events?my_access_token=1", "/v1/events?my_access_token=1"},
+	}
+
+	for _, tt := range tests {
+		if got := redactAccessToken(tt.path); got != tt.want {
+			t.Errorf("redactAccessToken(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestChangeAction(t *testing.T) {
+	tests := []struct {
+		method, route string
+		want          string
+	}{
+		{"POST", "/CreateShop", "created"},
+		{"PUT", "/UpdateShop/:id", "updated"},
+		{"PATCH", "/v1/shops/:id", "updated"},
+		{"DELETE", "/DeleteShop/:id", "deleted"},
+		{"POST", "/v1/shops/:id/restore", "restored"},
+		{"DELETE", "/v1/admin/shops/:id", "purged"},
+		{"GET", "/GetByIdShop/:id", ""},
+	}
+
+	for _, tt := range tests {
+		if got := changeAction(tt.method, tt.route); got != tt.want {
+			t.Errorf("changeAction(%s, %s) = %q, want %q", tt.method, tt.route, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"microservice/api/helpers"
+	"microservice/pkg/export"
+	"microservice/pkg/jobs"
+	"microservice/pkg/logger"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// exportPage is one page of a GetList response.
+type exportPage struct {
+	items      []proto.Message
+	count      int64
+	nextCursor string
+}
+
+// exportSource describes a resource that can be exported: the list item
+// message, the default columns and how to fetch one page from the user
+// service.
+type exportSource struct {
+	name    string
+	item    protoreflect.MessageDescriptor
+	columns []string
+	fetch   func(ctx context.Context, page, limit uint64, cursor string) (exportPage, error)
+}
+
+// exportHeaders are the localized column titles; fields without an entry
+// use their proto name.
+var exportHeaders = map[string]map[string]string{
+	"id":             {"uz": "ID", "ru": "ID", "en": "ID"},
+	"phone":          {"uz": "Telefon", "ru": "Телефон", "en": "Phone"},
+	"email":          {"uz": "Elektron pochta", "ru": "Эл. почта", "en": "Email"},
+	"gmail":          {"uz": "Elektron pochta", "ru": "Эл. почта", "en": "Email"},
+	"languange":      {"uz": "Til", "ru": "Язык", "en": "Language"},
+	"date_of_birth":  {"uz": "Tug'ilgan sana", "ru": "Дата рождения", "en": "Date of birth"},
+	"gender":         {"uz": "Jinsi", "ru": "Пол", "en": "Gender"},
+	"firstname":      {"uz": "Ism", "ru": "Имя", "en": "First name"},
+	"lastname":       {"uz": "Familiya", "ru": "Фамилия", "en": "Last name"},
+	"name":           {"uz": "Nomi", "ru": "Название", "en": "Name"},
+	"role":           {"uz": "Rol", "ru": "Роль", "en": "Role"},
+	"shop_id":        {"uz": "Do'kon ID", "ru": "ID магазина", "en": "Shop ID"},
+	"slug":           {"uz": "Slug", "ru": "Slug", "en": "Slug"},
+	"name_uz":        {"uz": "Nomi (uz)", "ru": "Название (uz)", "en": "Name (uz)"},
+	"name_ru":        {"uz": "Nomi (ru)", "ru": "Название (ru)", "en": "Name (ru)"},
+	"name_en":        {"uz": "Nomi (en)", "ru": "Название (en)", "en": "Name (en)"},
+	"description_uz": {"uz": "Tavsif (uz)", "ru": "Описание (uz)", "en": "Description (uz)"},
+	"description_ru": {"uz": "Tavsif (ru)", "ru": "Описание (ru)", "en": "Description (ru)"},
+	"description_en": {"uz": "Tavsif (en)", "ru": "Описание (en)", "en": "Description (en)"},
+	"location":       {"uz": "Joylashuv", "ru": "Местоположение", "en": "Location"},
+	"address":        {"uz": "Manzil", "ru": "Адрес", "en": "Address"},
+	"currency":       {"uz": "Valyuta", "ru": "Валюта", "en": "Currency"},
+	"payment_types":  {"uz": "To'lov turlari", "ru": "Способы оплаты", "en": "Payment types"},
+	"open_time":      {"uz": "Ochilish vaqti", "ru": "Время открытия", "en": "Open time"},
+	"close_time":     {"uz": "Yopilish vaqti", "ru": "Время закрытия", "en": "Close time"},
+	"active":         {"uz": "Faol", "ru": "Активен", "en": "Active"},
+	"created_at":     {"uz": "Yaratilgan", "ru": "Создан", "en": "Created at"},
+	"updated_at":     {"uz": "Yangilangan", "ru": "Обновлён", "en": "Updated at"},
+	"deleted_at":     {"uz": "O'chirilgan", "ru": "Удалён", "en": "Deleted at"},
+}
+
+// runExport pages through src until it is exhausted and streams the rows as
+// CSV or XLSX (format=), with the columns= subset and headers in lang=.
+// Each page is flushed to the client before the next one is fetched, so the
+// whole dataset is never held in memory. With async=true the file is built
+// by a background job and downloaded from /jobs/{id}/download.
+func (h *handler) runExport(c *gin.Context, src exportSource) {
+	format := c.DefaultQuery("format", export.FormatCSV)
+	if format != export.FormatCSV && format != export.FormatXLSX {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(fmt.Errorf("format %s is not supported, use csv or xlsx", format)), "error while parsing format")
+		return
+	}
+
+	lang := c.DefaultQuery("lang", "en")
+	if err := helpers.ValidateLanguage(lang); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing lang")
+		return
+	}
+
+	columns, err := exportColumns(c.Query("columns"), src)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing columns")
+		return
+	}
+
+	async, ok := h.parseAsync(c)
+	if !ok {
+		return
+	}
+
+	limit := h.cfg.MaxPageSize
+	if limit == 0 {
+		limit = 100
+	}
+
+	if async {
+		job, ok := h.submitJob(c, "export_"+src.name, 0, func(ctx context.Context, t *jobs.Task) (interface{}, error) {
+			first, err := src.fetch(ctx, 1, limit, "")
+			if err != nil {
+				return nil, err
+			}
+			t.SetTotal(int(first.count))
+
+			// The file is streamed to disk page by page, like the response
+			// of a synchronous export.
+			f, err := t.CreateFile()
+			if err != nil {
+				return nil, err
+			}
+			err = func() error {
+				defer f.Close()
+
+				w, err := export.NewWriter(format, f, src.name)
+				if err != nil {
+					return err
+				}
+				if err := writeExport(ctx, w, func() {}, src, columns, lang, limit, first, t); err != nil {
+					return err
+				}
+				if err := f.Close(); err != nil {
+					return err
+				}
+
+				return t.Attach(jobs.File{
+					Name:        src.name + "." + format,
+					ContentType: w.ContentType(),
+					Path:        f.Name(),
+				})
+			}()
+			if err != nil {
+				os.Remove(f.Name())
+			}
+			return nil, err
+		})
+		if ok {
+			c.JSON(http.StatusAccepted, job)
+		}
+		return
+	}
+
+	// The first page is fetched before anything is written so that upstream
+	// errors still produce a proper error response.
+	ctx := c.Request.Context()
+	first, err := src.fetch(ctx, 1, limit, "")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to export "+src.name)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, src.name, format))
+	w, err := export.NewWriter(format, c.Writer, src.name)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to export "+src.name)
+		return
+	}
+	c.Header("Content-Type", w.ContentType())
+
+	// Headers are already sent once rows are written; leaving the document
+	// unfinished lets the client notice the export is incomplete.
+	if err := writeExport(ctx, w, c.Writer.Flush, src, columns, lang, limit, first, nil); err != nil {
+		h.log.Error("failed to export "+src.name, logger.Error(err))
+	}
+}
+
+// writeExport writes the header and every page of src, starting with the
+// already fetched first page, then closes w. flush is called after each
+// page; t, if set, receives progress.
+func writeExport(ctx context.Context, w export.Writer, flush func(), src exportSource, columns []protoreflect.FieldDescriptor, lang string, limit uint64, result exportPage, t *jobs.Task) error {
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = exportHeader(column.TextName(), lang)
+	}
+	if err := w.WriteRow(headers); err != nil {
+		return err
+	}
+
+	var (
+		page     = uint64(1)
+		exported = uint64(0)
+		err      error
+	)
+	for {
+		for _, item := range result.items {
+			if err := w.WriteRow(exportRow(item.ProtoReflect(), columns)); err != nil {
+				return err
+			}
+		}
+		exported += uint64(len(result.items))
+
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		flush()
+		if t != nil {
+			t.Add(len(result.items), 0)
+		}
+
+		cursor := result.nextCursor
+		if len(result.items) == 0 || (cursor == "" && (uint64(len(result.items)) < limit || exported >= uint64(result.count))) {
+			break
+		}
+		page++
+
+		result, err = src.fetch(ctx, page, limit, cursor)
+		if err != nil {
+			return fmt.Errorf("page %d: %w", page, err)
+		}
+	}
+
+	return w.Close()
+}
+
+func exportColumns(query string, src exportSource) ([]protoreflect.FieldDescriptor, error) {
+	names := src.columns
+	if query != "" {
+		names = strings.Split(query, ",")
+	}
+
+	columns := make([]protoreflect.FieldDescriptor, 0, len(names))
+	for _, name := range names {
+		fd := src.item.Fields().ByName(protoreflect.Name(strings.TrimSpace(name)))
+		if fd == nil {
+			return nil, fmt.Errorf("unknown column %s", name)
+		}
+		columns = append(columns, fd)
+	}
+
+	return columns, nil
+}
+
+func exportHeader(field, lang string) string {
+	if titles, ok := exportHeaders[field]; ok {
+		return titles[lang]
+	}
+	return field
+}
+
+func exportRow(m protoreflect.Message, columns []protoreflect.FieldDescriptor) []string {
+	row := make([]string, len(columns))
+	for i, fd := range columns {
+		row[i] = exportValue(fd, m.Get(fd))
+	}
+	return row
+}
+
+func exportValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	if fd.IsList() {
+		list := v.List()
+		items := make([]string, list.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(list.Get(i).Interface())
+		}
+		return strings.Join(items, "; ")
+	}
+
+	if fd.Kind() == protoreflect.MessageKind {
+		return ""
+	}
+
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,909 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"microservice/api/helpers"
+	"microservice/api/models"
+	"microservice/config"
+	"microservice/genproto/user_service"
+	"microservice/pkg/audit"
+	"microservice/pkg/grpc_client"
+	"microservice/pkg/logger"
+	"microservice/pkg/uuid"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// graphqlEntity describes how the GraphQL schema reads and changes one
+// user_service resource. Requests and responses are the service messages.
+type graphqlEntity struct {
+	name, plural, typeName string
+	sortFields             []string
+
+	message, listRequest, listResponse proto.Message
+	createRequest, updateRequest, key  proto.Message
+
+	get    func(ctx context.Context, id string) (proto.Message, error)
+	list   func(ctx context.Context, req proto.Message) (proto.Message, error)
+	create func(ctx context.Context, req proto.Message) (proto.Message, error)
+	update func(ctx context.Context, req proto.Message) (proto.Message, error)
+	delete func(ctx context.Context, key proto.Message) error
+	// validate checks a create or update request like the REST handlers.
+	validate func(ctx context.Context, req proto.Message) error
+}
+
+func (h *handler) graphqlEntities() []graphqlEntity {
+	users := func() user_service.UsServiceClient { return h.grpcClient.SystemUserService() }
+	decorate := func(b *user_service.Branch, err error) (proto.Message, error) {
+		if err != nil {
+			return nil, err
+		}
+		h.decorateBranch(b, time.Now())
+		return b, nil
+	}
+
+	return []graphqlEntity{{
+		name: "customer", plural: "customers", typeName: "Customer", sortFields: customerSortFields,
+		message: &user_service.Customer{}, listRequest: &user_service.GetListCustomerRequest{}, listResponse: &user_service.GetListCustomerResponse{},
+		createRequest: &user_service.CreateCustomer{}, updateRequest: &user_service.UpdateCustomer{}, key: &user_service.CustomerPrimaryKey{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.UserService().GetByID(ctx, &user_service.CustomerPrimaryKey{Id: id})
+		},
+		list: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.UserService().GetList(ctx, req.(*user_service.GetListCustomerRequest))
+		},
+		create: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.UserService().Create(ctx, req.(*user_service.CreateCustomer))
+		},
+		update: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.UserService().Update(ctx, req.(*user_service.UpdateCustomer))
+		},
+		delete: func(ctx context.Context, key proto.Message) error {
+			_, err := h.grpcClient.UserService().Delete(ctx, key.(*user_service.CustomerPrimaryKey))
+			return err
+		},
+		validate: func(ctx context.Context, req proto.Message) error {
+			switch r := req.(type) {
+			case *user_service.CreateCustomer:
+				return validatePhoneAndEmail(r.Phone, r.Email)
+			case *user_service.UpdateCustomer:
+				return validatePhoneAndEmail(r.Phone, r.Email)
+			}
+			return nil
+		},
+	}, {
+		name: "user", plural: "users", typeName: "User", sortFields: userSortFields,
+		message: &user_service.Us{}, listRequest: &user_service.GetListUsRequest{}, listResponse: &user_service.GetListUsResponse{},
+		createRequest: &user_service.CreateUs{}, updateRequest: &user_service.UpdateUs{}, key: &user_service.UsPrimaryKey{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return users().GetByID(ctx, &user_service.UsPrimaryKey{Id: id})
+		},
+		list: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return users().GetList(ctx, req.(*user_service.GetListUsRequest))
+		},
+		create: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return users().Create(ctx, req.(*user_service.CreateUs))
+		},
+		update: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return users().Update(ctx, req.(*user_service.UpdateUs))
+		},
+		delete: func(ctx context.Context, key proto.Message) error {
+			_, err := users().Delete(ctx, key.(*user_service.UsPrimaryKey))
+			return err
+		},
+		validate: func(ctx context.Context, req proto.Message) error {
+			switch r := req.(type) {
+			case *user_service.CreateUs:
+				return validatePhoneAndEmail(r.Phone, r.Gmail)
+			case *user_service.UpdateUs:
+				return validatePhoneAndEmail(r.Phone, r.Gmail)
+			}
+			return nil
+		},
+	}, {
+		name: "seller", plural: "sellers", typeName: "Seller", sortFields: sellerSortFields,
+		message: &user_service.Seller{}, listRequest: &user_service.GetListSellerRequest{}, listResponse: &user_service.GetListSellerResponse{},
+		createRequest: &user_service.CreateSeller{}, updateRequest: &user_service.UpdateSeller{}, key: &user_service.SellerPrimaryKey{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.SellerService().GetByID(ctx, &user_service.SellerPrimaryKey{Id: id})
+		},
+		list: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.SellerService().GetList(ctx, req.(*user_service.GetListSellerRequest))
+		},
+		create: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.SellerService().Create(ctx, req.(*user_service.CreateSeller))
+		},
+		update: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.SellerService().Update(ctx, req.(*user_service.UpdateSeller))
+		},
+		delete: func(ctx context.Context, key proto.Message) error {
+			_, err := h.grpcClient.SellerService().Delete(ctx, key.(*user_service.SellerPrimaryKey))
+			return err
+		},
+		validate: func(ctx context.Context, req proto.Message) error {
+			var phone, email, shopID string
+			switch r := req.(type) {
+			case *user_service.CreateSeller:
+				phone, email, shopID = r.Phone, r.Email, r.ShopId
+			case *user_service.UpdateSeller:
+				phone, email, shopID = r.Phone, r.Email, r.ShopId
+			}
+			if err := validatePhoneAndEmail(phone, email); err != nil {
+				return err
+			}
+			return h.newShopChecker(ctx).check(shopID)
+		},
+	}, {
+		name: "branch", plural: "branches", typeName: "Branch", sortFields: branchSortFields,
+		message: &user_service.Branch{}, listRequest: &user_service.GetListBranchRequest{}, listResponse: &user_service.GetListBranchResponse{},
+		createRequest: &user_service.CreateBranch{}, updateRequest: &user_service.UpdateBranch{}, key: &user_service.BranchPrimaryKey{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return decorate(h.grpcClient.BranchService().GetByID(ctx, &user_service.BranchPrimaryKey{Id: id}))
+		},
+		list: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			resp, err := h.grpcClient.BranchService().GetList(ctx, req.(*user_service.GetListBranchRequest))
+			if err != nil {
+				return nil, err
+			}
+			now := time.Now()
+			for _, b := range resp.Branches {
+				h.decorateBranch(b, now)
+			}
+			return resp, nil
+		},
+		create: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return decorate(h.grpcClient.BranchService().Create(ctx, req.(*user_service.CreateBranch)))
+		},
+		update: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return decorate(h.grpcClient.BranchService().Update(ctx, req.(*user_service.UpdateBranch)))
+		},
+		delete: func(ctx context.Context, key proto.Message) error {
+			_, err := h.grpcClient.BranchService().Delete(ctx, key.(*user_service.BranchPrimaryKey))
+			return err
+		},
+		validate: func(ctx context.Context, req proto.Message) error {
+			switch r := req.(type) {
+			case *user_service.CreateBranch:
+				if err := helpers.ValidatePhone(r.Phone); err != nil {
+					return err
+				}
+				return h.validateCreateBranch(r)
+			case *user_service.UpdateBranch:
+				if err := helpers.ValidatePhone(r.Phone); err != nil {
+					return err
+				}
+				return h.validateUpdateBranch(r)
+			}
+			return nil
+		},
+	}, {
+		name: "shop", plural: "shops", typeName: "Shop", sortFields: shopSortFields,
+		message: &user_service.Shop{}, listRequest: &user_service.GetListShopRequest{}, listResponse: &user_service.GetListShopResponse{},
+		createRequest: &user_service.CreateShop{}, updateRequest: &user_service.UpdateShop{}, key: &user_service.ShopPrimaryKey{},
+		get: func(ctx context.Context, id string) (proto.Message, error) {
+			return h.grpcClient.ShopService().GetByID(ctx, &user_service.ShopPrimaryKey{Id: id})
+		},
+		list: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.ShopService().GetList(ctx, req.(*user_service.GetListShopRequest))
+		},
+		create: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.ShopService().Create(ctx, req.(*user_service.CreateShop))
+		},
+		update: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return h.grpcClient.ShopService().Update(ctx, req.(*user_service.UpdateShop))
+		},
+		delete: func(ctx context.Context, key proto.Message) error {
+			_, err := h.grpcClient.ShopService().Delete(ctx, key.(*user_service.ShopPrimaryKey))
+			return err
+		},
+		validate: func(ctx context.Context, req proto.Message) error {
+			var err error
+			switch r := req.(type) {
+			case *user_service.CreateShop:
+				if err = helpers.ValidatePhone(r.Phone); err == nil {
+					if r.Slug == "" {
+						r.Slug, err = h.newShopSlug(ctx, r)
+					} else {
+						err = h.validateShopSlug(ctx, r.Slug, "")
+					}
+				}
+			case *user_service.UpdateShop:
+				if err = helpers.ValidatePhone(r.Phone); err == nil && r.Slug != "" {
+					err = h.validateShopSlug(ctx, r.Slug, r.Id)
+				}
+			}
+			return err
+		},
+	}}
+}
+
+// graphqlRoute is where GraphQL is served.
+const graphqlRoute = "/graphql"
+
+// graphqlContext is what resolvers of one request share.
+type graphqlContext struct {
+	requestID string
+	// actor, role and clientIP describe the caller for the audit log; role
+	// is that of the caller's access token, if any.
+	actor, role, clientIP string
+	// sellers and branches are loaded by "<shop id>/<limit>", shops by ID.
+	sellers, branches, shops *loader
+}
+
+type graphqlContextKey struct{}
+
+func (h *handler) newGraphQLContext(c *gin.Context) context.Context {
+	listByShop := func(list func(ctx context.Context, shopID string, limit uint64) (interface{}, error)) *loader {
+		return newLoader(func(ctx context.Context, keys []string) (map[string]interface{}, map[string]error) {
+			return fetchDistinct(ctx, keys, func(ctx context.Context, key string) (interface{}, error) {
+				shopID, limit, _ := strings.Cut(key, "/")
+				n, _ := strconv.ParseUint(limit, 10, 64)
+				return list(ctx, shopID, n)
+			})
+		})
+	}
+
+	gc := &graphqlContext{
+		requestID: c.Writer.Header().Get(requestIDHeader),
+		actor:     principal(c),
+		clientIP:  c.ClientIP(),
+		sellers: listByShop(func(ctx context.Context, shopID string, limit uint64) (interface{}, error) {
+			resp, err := h.grpcClient.SellerService().GetList(ctx, &user_service.GetListSellerRequest{Page: 1, Limit: limit, ShopId: shopID})
+			if err != nil {
+				return nil, err
+			}
+			return resp.Sellers, nil
+		}),
+		branches: listByShop(func(ctx context.Context, shopID string, limit uint64) (interface{}, error) {
+			resp, err := h.grpcClient.BranchService().GetList(ctx, &user_service.GetListBranchRequest{Page: 1, Limit: limit, ShopId: shopID})
+			if err != nil {
+				return nil, err
+			}
+			now := time.Now()
+			for _, b := range resp.Branches {
+				h.decorateBranch(b, now)
+			}
+			return resp.Branches, nil
+		}),
+		shops: newLoader(func(ctx context.Context, keys []string) (map[string]interface{}, map[string]error) {
+			return fetchDistinct(ctx, keys, func(ctx context.Context, id string) (interface{}, error) {
+				shop, err := h.grpcClient.ShopService().GetByID(ctx, &user_service.ShopPrimaryKey{Id: id})
+				if status.Code(err) == codes.NotFound {
+					return nil, nil
+				}
+				return shop, err
+			})
+		}),
+	}
+	if claims, err := parseToken(bearerToken(c)); err == nil {
+		gc.role = claims.Role
+	}
+	return context.WithValue(readContext(c), graphqlContextKey{}, gc)
+}
+
+func graphqlContextFrom(ctx context.Context) *graphqlContext {
+	gc, _ := ctx.Value(graphqlContextKey{}).(*graphqlContext)
+	return gc
+}
+
+// graphqlError strips the gRPC prefix from service errors, keeping those
+// that carry extensions.
+func graphqlError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(gqlerrors.ExtendedError); ok {
+		return err
+	}
+	return plainError(err)
+}
+
+// pageLimit applies the page size defaults of parseListParams to limit.
+func (h *handler) pageLimit(limit uint64) uint64 {
+	switch {
+	case limit == 0:
+		return h.cfg.DefaultPageSize
+	case h.cfg.MaxPageSize > 0 && limit > h.cfg.MaxPageSize:
+		return h.cfg.MaxPageSize
+	}
+	return limit
+}
+
+// intArg reads an Int argument, treating unset and negative values as zero.
+func intArg(value interface{}) uint64 {
+	if n, ok := value.(int); ok && n > 0 {
+		return uint64(n)
+	}
+	return 0
+}
+
+// normalizeListRequest applies the defaults and checks of parseListParams
+// to a GetList request of a caller with role; they all share these fields.
+func (h *handler) normalizeListRequest(req proto.Message, sortFields []string, role string) error {
+	m := req.ProtoReflect()
+	field := func(name protoreflect.Name) protoreflect.FieldDescriptor { return m.Descriptor().Fields().ByName(name) }
+
+	if m.Get(field("page")).Uint() == 0 {
+		m.Set(field("page"), protoreflect.ValueOfUint64(1))
+	}
+	m.Set(field("limit"), protoreflect.ValueOfUint64(h.pageLimit(m.Get(field("limit")).Uint())))
+
+	sort, err := parseSortQueryParam(m.Get(field("sort")).String(), sortFields)
+	if err != nil {
+		return err
+	}
+	m.Set(field("sort"), protoreflect.ValueOfString(sort))
+
+	if err := validateDateRange(m.Get(field("created_at_from")).String(), m.Get(field("created_at_to")).String()); err != nil {
+		return err
+	}
+	if (m.Get(field("include_deleted")).Bool() || m.Get(field("only_deleted")).Bool()) && role != adminRole {
+		return errors.New("only " + adminRole + " can list deleted records")
+	}
+	if m.Get(field("only_deleted")).Bool() {
+		m.Set(field("include_deleted"), protoreflect.ValueOfBool(true))
+	}
+	return nil
+}
+
+// protoID returns the id field of msg.
+func protoID(msg proto.Message) string {
+	m := msg.ProtoReflect()
+	if fd := m.Descriptor().Fields().ByName("id"); fd != nil {
+		return m.Get(fd).String()
+	}
+	return ""
+}
+
+// newGraphQLSchema maps the user_service messages to GraphQL types, with a
+// query for one and for a list of each resource and mutations to create,
+// update and delete them. Shops have their sellers and branches, which are
+// read once per shop and query level, and sellers and branches their shop.
+func (h *handler) newGraphQLSchema() (graphql.Schema, error) {
+	var (
+		types     = newProtoTypes()
+		queries   = graphql.Fields{}
+		mutations = graphql.Fields{}
+		entities  = h.graphqlEntities()
+		shopType  = types.object((&user_service.Shop{}).ProtoReflect().Descriptor())
+	)
+
+	byShop := func(typ proto.Message, pick func(gc *graphqlContext) *loader) *graphql.Field {
+		return &graphql.Field{
+			Type:        graphql.NewList(types.object(typ.ProtoReflect().Descriptor())),
+			Description: fmt.Sprintf("The first limit of the shop, %d by default.", h.cfg.DefaultPageSize),
+			Args:        graphql.FieldConfigArgument{"limit": &graphql.ArgumentConfig{Type: graphql.Int}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				shop := p.Source.(*user_service.Shop)
+				key := fmt.Sprintf("%s/%d", shop.Id, h.pageLimit(intArg(p.Args["limit"])))
+				return graphqlThunk(pick(graphqlContextFrom(p.Context)).load(p.Context, key)), nil
+			},
+		}
+	}
+	shopOf := &graphql.Field{
+		Type: shopType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			shopID := ""
+			switch s := p.Source.(type) {
+			case *user_service.Seller:
+				shopID = s.ShopId
+			case *user_service.Branch:
+				shopID = s.ShopId
+			}
+			if shopID == "" {
+				return nil, nil
+			}
+			return graphqlThunk(graphqlContextFrom(p.Context).shops.load(p.Context, shopID)), nil
+		},
+	}
+	types.extra["Shop"] = graphql.Fields{
+		"sellers":  byShop(&user_service.Seller{}, func(gc *graphqlContext) *loader { return gc.sellers }),
+		"branches": byShop(&user_service.Branch{}, func(gc *graphqlContext) *loader { return gc.branches }),
+	}
+	types.extra["Seller"] = graphql.Fields{"shop": shopOf}
+	types.extra["Branch"] = graphql.Fields{"shop": shopOf}
+
+	for _, e := range entities {
+		e := e
+		obj := types.object(e.message.ProtoReflect().Descriptor())
+
+		queries[e.name] = &graphql.Field{
+			Type: obj,
+			Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				msg, err := e.get(p.Context, p.Args["id"].(string))
+				if status.Code(err) == codes.NotFound {
+					return nil, nil
+				}
+				return msg, graphqlError(err)
+			},
+		}
+
+		queries[e.plural] = &graphql.Field{
+			Type: types.object(e.listResponse.ProtoReflect().Descriptor()),
+			Args: types.args(e.listRequest.ProtoReflect().Descriptor()),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				req := e.listRequest.ProtoReflect().New().Interface()
+				if err := protoFromArgs(p.Args, req); err != nil {
+					return nil, err
+				}
+				if err := h.normalizeListRequest(req, e.sortFields, graphqlContextFrom(p.Context).role); err != nil {
+					return nil, err
+				}
+				resp, err := e.list(p.Context, req)
+				return resp, graphqlError(err)
+			},
+		}
+
+		for _, m := range []struct {
+			action  string
+			request proto.Message
+			call    func(ctx context.Context, req proto.Message) (proto.Message, error)
+		}{
+			{"created", e.createRequest, e.create},
+			{"updated", e.updateRequest, e.update},
+		} {
+			m := m
+			name := "create" + e.typeName
+			if m.action == "updated" {
+				name = "update" + e.typeName
+			}
+			mutations[name] = &graphql.Field{
+				Type: obj,
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(types.input(m.request.ProtoReflect().Descriptor()))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					input, _ := p.Args["input"].(map[string]interface{})
+					req := m.request.ProtoReflect().New().Interface()
+					if err := protoFromArgs(input, req); err != nil {
+						return nil, err
+					}
+					method, id, before := http.MethodPost, "", json.RawMessage(nil)
+					if m.action == "updated" {
+						method, id = http.MethodPut, protoID(req)
+						before = h.auditSnapshot(p.Context, e.name, id)
+					}
+					if err := e.validate(p.Context, req); err != nil {
+						h.auditGraphQLChange(p.Context, method, e.name, id, before, err)
+						return nil, graphqlError(err)
+					}
+					resp, err := m.call(p.Context, req)
+					if err != nil {
+						h.auditGraphQLChange(p.Context, method, e.name, id, before, err)
+						return nil, graphqlError(err)
+					}
+					h.auditGraphQLChange(p.Context, method, e.name, protoID(resp), before, nil)
+					h.publishGraphQLChange(p.Context, e.name, m.action, protoID(resp), resp)
+					return resp, nil
+				},
+			}
+		}
+
+		deleteArgs := types.args(e.key.ProtoReflect().Descriptor())
+		deleteArgs["id"].Type = graphql.NewNonNull(graphql.ID)
+		if e.name == "shop" {
+			deleteArgs["cascade"] = &graphql.ArgumentConfig{
+				Type:        graphql.Boolean,
+				Description: "Delete the shop's sellers and branches too; SHOP_DELETE_CASCADE by default.",
+			}
+		}
+		mutations["delete"+e.typeName] = &graphql.Field{
+			Type: graphql.Boolean,
+			Args: deleteArgs,
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				cascade, hasCascade := p.Args["cascade"].(bool)
+				delete(p.Args, "cascade")
+
+				key := e.key.ProtoReflect().New().Interface()
+				if err := protoFromArgs(p.Args, key); err != nil {
+					return nil, err
+				}
+				id := protoID(key)
+				before := h.auditSnapshot(p.Context, e.name, id)
+
+				if e.name == "shop" && !hasCascade {
+					cascade = h.cfg.ShopDeleteCascade
+				}
+				if e.name == "shop" && !cascade {
+					if err := h.graphqlShopDependents(p.Context, id); err != nil {
+						h.auditGraphQLChange(p.Context, http.MethodDelete, e.name, id, before, err)
+						return nil, graphqlError(err)
+					}
+				}
+
+				var err error
+				if e.name == "shop" && cascade {
+					_, err = h.deleteShopCascade(p.Context, key.(*user_service.ShopPrimaryKey))
+				} else {
+					err = e.delete(p.Context, key)
+				}
+				if err != nil {
+					h.auditGraphQLChange(p.Context, http.MethodDelete, e.name, id, before, err)
+					return nil, graphqlError(err)
+				}
+				h.auditGraphQLChange(p.Context, http.MethodDelete, e.name, id, before, nil)
+				h.publishGraphQLChange(p.Context, e.name, "deleted", id, nil)
+				return true, nil
+			},
+		}
+	}
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: queries}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: mutations}),
+	})
+}
+
+// graphqlShopDependents does for deleteShop what DeleteShop does before
+// deleting without cascade: it refuses while the shop has sellers or
+// branches.
+func (h *handler) graphqlShopDependents(ctx context.Context, id string) error {
+	deps, err := h.shopDependents(grpc_client.WithoutCache(ctx), id, false)
+	if err != nil {
+		return err
+	}
+	if deps.SellerCount > 0 || deps.BranchCount > 0 {
+		return &shopDependentsError{deps: deps}
+	}
+	return nil
+}
+
+// publishGraphQLChange publishes a mutation like Audit does for REST
+// requests.
+func (h *handler) publishGraphQLChange(ctx context.Context, resourceType, action, id string, object proto.Message) {
+	if h.webhooks == nil && h.events == nil {
+		return
+	}
+
+	ch := change{event: resourceType + "." + action, resourceType: resourceType, resourceID: id}
+	if object != nil {
+		ch.object, _ = protojson.MarshalOptions{UseProtoNames: true}.Marshal(object)
+	}
+
+	requestID := ""
+	if gc := graphqlContextFrom(ctx); gc != nil {
+		requestID = gc.requestID
+	}
+	go h.publishChanges(context.WithoutCancel(ctx), []change{ch}, requestID)
+}
+
+// auditGraphQLChange records a mutation in the audit log like Audit does for
+// REST requests, under the method of the REST request it corresponds to.
+// Audit skips /graphql itself, whose requests may hold any number of
+// mutations or none. before is the resource ahead of the change and err why
+// the mutation failed.
+func (h *handler) auditGraphQLChange(ctx context.Context, method, resourceType, id string, before json.RawMessage, err error) {
+	gc := graphqlContextFrom(ctx)
+	if h.audit == nil || gc == nil {
+		return
+	}
+
+	entryID, idErr := uuid.New()
+	if idErr != nil {
+		h.log.Error("failed to generate audit entry ID", logger.Error(idErr))
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	e := audit.Entry{
+		ID:           entryID,
+		Time:         time.Now().UTC(),
+		Actor:        gc.actor,
+		Role:         gc.role,
+		Method:       method,
+		Route:        graphqlRoute,
+		Path:         graphqlRoute,
+		ResourceType: resourceType,
+		ResourceID:   id,
+		Before:       before,
+		ClientIP:     gc.clientIP,
+		RequestID:    gc.requestID,
+		Status:       http.StatusOK,
+		Outcome:      audit.OutcomeSuccess,
+	}
+	if err != nil {
+		e.Status, e.Outcome, e.Error = graphqlErrorStatus(err), audit.OutcomeFailure, graphqlError(err).Error()
+	} else if id != "" && method != http.MethodDelete {
+		e.After = h.auditSnapshot(ctx, resourceType, id)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := h.audit.Write(ctx, e); err != nil {
+		h.log.Error("failed to write audit entry", logger.String("request_id", gc.requestID), logger.Error(err))
+	}
+}
+
+// graphqlErrorStatus is the status the REST route would answer err with.
+func graphqlErrorStatus(err error) int {
+	st, ok := status.FromError(err)
+	if !ok {
+		return http.StatusBadRequest
+	}
+	switch st.Code() {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Aborted:
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// graphqlThunk adapts a loader thunk to the signature graphql-go defers.
+func graphqlThunk(thunk func() (interface{}, error)) func() (interface{}, error) {
+	return func() (interface{}, error) {
+		value, err := thunk()
+		return value, graphqlError(err)
+	}
+}
+
+// graphqlOperation returns the operation a request runs.
+func graphqlOperation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil, errors.New("operationName is required when the document has several operations")
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			return op, nil
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("unknown operation %q", name)
+	}
+	return found, nil
+}
+
+// graphqlCost measures an operation: its depth in fields and its
+// complexity, where every field costs one and the fields below a list count
+// once per item the list may hold, as set by the limit argument of the list
+// or of the query returning it. Introspection is free.
+type graphqlCost struct {
+	h         *handler
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+func (g *graphqlCost) selectionSet(set *ast.SelectionSet, parent graphql.Type, limit uint64) (depth, cost int) {
+	if set == nil {
+		return 0, 0
+	}
+
+	for _, sel := range set.Selections {
+		var d, c int
+		switch s := sel.(type) {
+		case *ast.Field:
+			d, c = g.field(s, parent, limit)
+		case *ast.InlineFragment:
+			typ := parent
+			if s.TypeCondition != nil {
+				typ = g.schema.Type(s.TypeCondition.Name.Value)
+			}
+			d, c = g.selectionSet(s.SelectionSet, typ, limit)
+		case *ast.FragmentSpread:
+			if frag, ok := g.fragments[s.Name.Value]; ok {
+				d, c = g.selectionSet(frag.SelectionSet, g.schema.Type(frag.TypeCondition.Name.Value), limit)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+		cost += c
+	}
+	return depth, cost
+}
+
+func (g *graphqlCost) field(f *ast.Field, parent graphql.Type, limit uint64) (depth, cost int) {
+	if strings.HasPrefix(f.Name.Value, "__") {
+		return 0, 0
+	}
+
+	var (
+		typ    graphql.Type
+		isList bool
+		own    = g.limitArg(f)
+	)
+	if obj, ok := parent.(*graphql.Object); ok {
+		if def, ok := obj.Fields()[f.Name.Value]; ok {
+			typ, isList = unwrapGraphQLType(def.Type)
+			for _, arg := range def.Args {
+				if arg.Name() == "limit" && own == 0 {
+					own = g.h.pageLimit(0)
+				}
+			}
+		}
+	}
+
+	multiplier := uint64(1)
+	if isList {
+		switch {
+		case own > 0:
+			multiplier = own
+		case limit > 0:
+			multiplier = limit
+		default:
+			multiplier = g.h.cfg.DefaultPageSize
+		}
+		own = 0
+	}
+
+	depth, cost = g.selectionSet(f.SelectionSet, typ, own)
+	return depth + 1, 1 + int(multiplier)*cost
+}
+
+// limitArg returns the limit argument of f, or zero.
+func (g *graphqlCost) limitArg(f *ast.Field) uint64 {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "limit" {
+			continue
+		}
+
+		var limit uint64
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			limit, _ = strconv.ParseUint(v.Value, 10, 64)
+		case *ast.Variable:
+			switch n := g.variables[v.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					limit = uint64(n)
+				}
+			case string:
+				limit, _ = strconv.ParseUint(n, 10, 64)
+			}
+		}
+		return g.h.pageLimit(limit)
+	}
+	return 0
+}
+
+func unwrapGraphQLType(t graphql.Type) (graphql.Type, bool) {
+	isList := false
+	for {
+		switch w := t.(type) {
+		case *graphql.NonNull:
+			t = w.OfType
+		case *graphql.List:
+			t, isList = w.OfType, true
+		default:
+			return t, isList
+		}
+	}
+}
+
+// graphiQLPage is the playground served on GET /graphql in debug mode.
+const graphiQLPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>GraphiQL</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css" />
+</head>
+<body style="margin: 0;">
+  <div id="graphiql" style="height: 100vh;"></div>
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const fetcher = GraphiQL.createFetcher({ url: window.location.pathname });
+    ReactDOM.createRoot(document.getElementById('graphiql')).render(React.createElement(GraphiQL, { fetcher }));
+  </script>
+</body>
+</html>`
+
+// @Router         /graphql [POST]
+// @Summary        GraphQL
+// @Description    GraphQL over the customers, users, sellers, branches and shops, with their proto field names. Queries: customer(id), customers(page, limit, ...) and so on for user, seller, branch and shop; shops have sellers(limit) and branches(limit), which are read once per shop and level, and sellers and branches their shop. Mutations: create<Type>(input), update<Type>(input) and delete<Type>(id, expected_updated_at), with cascade on deleteShop. Queries deeper than GRAPHQL_MAX_DEPTH or more complex than GRAPHQL_MAX_COMPLEXITY are rejected; every field costs 1 and the fields below a list count once per item its limit allows. GET /graphql takes ?query= for queries, and serves GraphiQL without a query in debug mode
+// @Tags           graphql
+// @Accept         json
+// @Produce        json
+// @Param          request body models.GraphQLRequest true "query"
+// @Success        200 {object} models.GraphQLResponse
+// @Failure        400 {object} models.GraphQLResponse
+// @Failure        405 {object} models.GraphQLResponse
+func (h *handler) GraphQL(c *gin.Context) {
+	var req models.GraphQLRequest
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if req.Query == "" && h.cfg.Environment == config.DebugMode {
+			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(graphiQLPage))
+			return
+		}
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				graphqlFail(c, http.StatusBadRequest, errors.New("variables must be a JSON object"))
+				return
+			}
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		graphqlFail(c, http.StatusBadRequest, err)
+		return
+	}
+	if req.Query == "" {
+		graphqlFail(c, http.StatusBadRequest, errors.New("query is required"))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})})
+	if err != nil {
+		graphqlFail(c, http.StatusBadRequest, err)
+		return
+	}
+	if result := graphql.ValidateDocument(&h.graphql, doc, nil); !result.IsValid {
+		c.JSON(http.StatusBadRequest, &graphql.Result{Errors: result.Errors})
+		return
+	}
+
+	op, err := graphqlOperation(doc, req.OperationName)
+	if err != nil {
+		graphqlFail(c, http.StatusBadRequest, err)
+		return
+	}
+	if op.Operation == ast.OperationTypeMutation && c.Request.Method == http.MethodGet {
+		graphqlFail(c, http.StatusMethodNotAllowed, errors.New("mutations must be sent with POST"))
+		return
+	}
+
+	cost := &graphqlCost{h: h, schema: h.graphql, fragments: make(map[string]*ast.FragmentDefinition), variables: req.Variables}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok {
+			cost.fragments[frag.Name.Value] = frag
+		}
+	}
+	root := graphql.Type(h.graphql.QueryType())
+	if op.Operation == ast.OperationTypeMutation {
+		root = h.graphql.MutationType()
+	}
+	depth, complexity := cost.selectionSet(op.SelectionSet, root, 0)
+	if h.cfg.GraphQLMaxDepth > 0 && depth > h.cfg.GraphQLMaxDepth {
+		graphqlFail(c, http.StatusBadRequest, fmt.Errorf("query depth %d exceeds the limit of %d", depth, h.cfg.GraphQLMaxDepth))
+		return
+	}
+	if h.cfg.GraphQLMaxComplexity > 0 && complexity > h.cfg.GraphQLMaxComplexity {
+		graphqlFail(c, http.StatusBadRequest, fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, h.cfg.GraphQLMaxComplexity))
+		return
+	}
+
+	ctx := h.newGraphQLContext(c)
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.graphql,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	c.JSON(http.StatusOK, result)
+}
+
+func graphqlFail(c *gin.Context, code int, err error) {
+	c.AbortWithStatusJSON(code, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+}
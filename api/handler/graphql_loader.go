@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"sync"
+)
+
+// loader collects the keys a GraphQL query asks for. Resolvers call load,
+// which only records the key and returns a thunk; graphql-go runs the thunks
+// once every field of a level has been resolved, so the first of them
+// fetches all the keys recorded by then, each of them once.
+type loader struct {
+	fetch fetchFunc
+
+	mu      sync.Mutex
+	pending []string
+	values  map[string]interface{}
+	errs    map[string]error
+}
+
+// fetchFunc returns the value or the error of every key.
+type fetchFunc func(ctx context.Context, keys []string) (map[string]interface{}, map[string]error)
+
+func newLoader(fetch fetchFunc) *loader {
+	return &loader{
+		fetch:  fetch,
+		values: make(map[string]interface{}),
+		errs:   make(map[string]error),
+	}
+}
+
+func (l *loader) load(ctx context.Context, key string) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.values[key]; !ok && !contains(l.pending, key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if keys := l.pending; len(keys) > 0 {
+			l.pending = nil
+
+			values, errs := l.fetch(ctx, keys)
+			for _, k := range keys {
+				l.values[k] = values[k]
+				l.errs[k] = errs[k]
+			}
+		}
+		return l.values[key], l.errs[key]
+	}
+}
+
+// fetchDistinct calls get for every key at the same time. It saves only the
+// calls for repeated keys, which the loader drops: the user service cannot
+// read several IDs in one call, so it is still one RPC per distinct key.
+func fetchDistinct(ctx context.Context, keys []string, get func(ctx context.Context, key string) (interface{}, error)) (map[string]interface{}, map[string]error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		values = make(map[string]interface{}, len(keys))
+		errs   = make(map[string]error)
+	)
+
+	for _, key := range keys {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+
+			value, err := get(ctx, key)
+
+			mu.Lock()
+			defer mu.Unlock()
+			values[key], errs[key] = value, err
+		}(key)
+	}
+	wg.Wait()
+
+	return values, errs
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// int64Scalar carries 64-bit integers, which do not fit GraphQL's Int, as
+// JSON numbers.
+var int64Scalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "Int64",
+	Description: "A 64-bit integer.",
+	Serialize: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int64, uint64:
+			return v
+		case int:
+			return int64(v)
+		}
+		return nil
+	},
+	ParseValue: func(value interface{}) interface{} {
+		switch v := value.(type) {
+		case int:
+			return int64(v)
+		case int64:
+			return v
+		case float64:
+			if v == math.Trunc(v) {
+				return int64(v)
+			}
+		case string:
+			if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+	ParseLiteral: func(value ast.Value) interface{} {
+		switch v := value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		case *ast.StringValue:
+			if n, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+				return n
+			}
+		}
+		return nil
+	},
+})
+
+// protoTypes maps proto messages to GraphQL object and input types, one
+// per message, with the proto field names. Extra holds fields that are not
+// in a message, such as a shop's sellers, by message name.
+type protoTypes struct {
+	objects map[protoreflect.FullName]*graphql.Object
+	inputs  map[protoreflect.FullName]*graphql.InputObject
+	extra   map[protoreflect.Name]graphql.Fields
+}
+
+func newProtoTypes() *protoTypes {
+	return &protoTypes{
+		objects: make(map[protoreflect.FullName]*graphql.Object),
+		inputs:  make(map[protoreflect.FullName]*graphql.InputObject),
+		extra:   make(map[protoreflect.Name]graphql.Fields),
+	}
+}
+
+// object returns the output type of md, resolving fields from the message.
+func (t *protoTypes) object(md protoreflect.MessageDescriptor) *graphql.Object {
+	if obj, ok := t.objects[md.FullName()]; ok {
+		return obj
+	}
+
+	obj := graphql.NewObject(graphql.ObjectConfig{
+		Name: string(md.Name()),
+		// Fields are built lazily so that messages may refer to each other
+		// and extra fields may be added after the type.
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			fields := graphql.Fields{}
+			for i := 0; i < md.Fields().Len(); i++ {
+				fd := md.Fields().Get(i)
+				fields[string(fd.Name())] = &graphql.Field{
+					Type:    t.fieldType(fd, false),
+					Resolve: resolveProtoField(fd),
+				}
+			}
+			for name, field := range t.extra[md.Name()] {
+				fields[name] = field
+			}
+			return fields
+		}),
+	})
+	t.objects[md.FullName()] = obj
+	return obj
+}
+
+// input returns the input type of md, named after it with an Input suffix.
+func (t *protoTypes) input(md protoreflect.MessageDescriptor) *graphql.InputObject {
+	if in, ok := t.inputs[md.FullName()]; ok {
+		return in
+	}
+
+	in := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: string(md.Name()) + "Input",
+		Fields: graphql.InputObjectConfigFieldMapThunk(func() graphql.InputObjectConfigFieldMap {
+			fields := graphql.InputObjectConfigFieldMap{}
+			for i := 0; i < md.Fields().Len(); i++ {
+				fd := md.Fields().Get(i)
+				fields[string(fd.Name())] = &graphql.InputObjectFieldConfig{Type: t.fieldType(fd, true)}
+			}
+			return fields
+		}),
+	})
+	t.inputs[md.FullName()] = in
+	return in
+}
+
+// args returns the fields of md as arguments, for request messages.
+func (t *protoTypes) args(md protoreflect.MessageDescriptor) graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{}
+	for i := 0; i < md.Fields().Len(); i++ {
+		fd := md.Fields().Get(i)
+		args[string(fd.Name())] = &graphql.ArgumentConfig{Type: t.fieldType(fd, true)}
+	}
+	return args
+}
+
+func (t *protoTypes) fieldType(fd protoreflect.FieldDescriptor, input bool) graphql.Type {
+	var typ graphql.Type
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		typ = graphql.Boolean
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		typ = graphql.Int
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		typ = int64Scalar
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		typ = graphql.Float
+	case protoreflect.MessageKind:
+		if input {
+			typ = t.input(fd.Message())
+		} else {
+			typ = t.object(fd.Message())
+		}
+	default:
+		typ = graphql.String
+	}
+
+	if fd.IsList() {
+		return graphql.NewList(typ)
+	}
+	return typ
+}
+
+func resolveProtoField(fd protoreflect.FieldDescriptor) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		msg, ok := p.Source.(proto.Message)
+		if !ok {
+			return nil, nil
+		}
+
+		m := msg.ProtoReflect()
+		if fd.HasPresence() && !m.Has(fd) {
+			return nil, nil
+		}
+
+		value := m.Get(fd)
+		if fd.IsList() {
+			list := value.List()
+			items := make([]interface{}, list.Len())
+			for i := range items {
+				items[i] = protoValue(fd, list.Get(i))
+			}
+			return items, nil
+		}
+		return protoValue(fd, value), nil
+	}
+}
+
+func protoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.MessageKind:
+		return v.Message().Interface()
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	case protoreflect.BytesKind:
+		return string(v.Bytes())
+	}
+	return v.Interface()
+}
+
+// protoFromArgs fills msg from GraphQL arguments or an input object, whose
+// keys are the proto field names.
+func protoFromArgs(args map[string]interface{}, msg proto.Message) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	if err := protojson.Unmarshal(data, msg); err != nil {
+		return fmt.Errorf("invalid input: %w", err)
+	}
+	return nil
+}
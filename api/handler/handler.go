@@ -1,29 +1,53 @@
 package handler
 
 import (
+	"microservice/api/helpers"
 	"microservice/api/models"
 	"microservice/config"
+	"microservice/pkg/audit"
+	"microservice/pkg/events"
 	"microservice/pkg/grpc_client"
+	"microservice/pkg/idempotency"
+	"microservice/pkg/jobs"
 	"microservice/pkg/logger"
+	"microservice/pkg/saga"
+	"microservice/pkg/webhook"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type handler struct {
-	log        logger.Logger
-	grpcClient *grpc_client.GrpcClient
-	cfg        config.Config
+	log         logger.Logger
+	grpcClient  *grpc_client.GrpcClient
+	cfg         config.Config
+	renderer    *helpers.JSONRenderer
+	jobs        *jobs.Manager
+	idempotency idempotency.Store
+	branchIndex *branchIndex
+	sagas       *saga.Coordinator
+	audit       audit.Sink
+	webhooks    *webhook.Dispatcher
+	events      *events.Hub
+	wsConns     *connLimiter
+	graphql     graphql.Schema
 }
 
 // HandlerV1Config ...
 type HandlerConfig struct {
-	Logger     logger.Logger
-	GrpcClient *grpc_client.GrpcClient
-	Cfg        config.Config
+	Logger      logger.Logger
+	GrpcClient  *grpc_client.GrpcClient
+	Cfg         config.Config
+	Jobs        *jobs.Manager
+	Idempotency idempotency.Store
+	Sagas       *saga.Coordinator
+	Audit       audit.Sink
+	Webhooks    *webhook.Dispatcher
+	Events      *events.Hub
 }
 
 const (
@@ -59,11 +83,36 @@ var (
 
 // New ...
 func New(c *HandlerConfig) *handler {
-	return &handler{
-		log:        c.Logger,
-		grpcClient: c.GrpcClient,
-		cfg:        c.Cfg,
+	h := &handler{
+		log:         c.Logger,
+		grpcClient:  c.GrpcClient,
+		cfg:         c.Cfg,
+		jobs:        c.Jobs,
+		idempotency: c.Idempotency,
+		branchIndex: newBranchIndex(c.Cfg.CacheBranchTTL),
+		sagas:       c.Sagas,
+		audit:       c.Audit,
+		webhooks:    c.Webhooks,
+		events:      c.Events,
+		wsConns:     newConnLimiter(),
+		renderer: helpers.NewJSONRenderer(helpers.JSONOptions{
+			EmitUnpopulated: c.Cfg.JSONEmitUnpopulated,
+			UseProtoNames:   c.Cfg.JSONUseProtoNames,
+			Int64AsString:   c.Cfg.JSONInt64AsString,
+		}),
 	}
+
+	schema, err := h.newGraphQLSchema()
+	if err != nil {
+		h.log.Fatal("failed to build the GraphQL schema", logger.Error(err))
+	}
+	h.graphql = schema
+
+	if h.sagas != nil {
+		h.sagas.Register(h.onboardingSaga())
+	}
+
+	return h
 }
 
 func handleGrpcErrWithDescription(c *gin.Context, l logger.Logger, err error, message string) bool {
@@ -104,6 +153,13 @@ func handleGrpcErrWithDescription(c *gin.Context, l logger.Logger, err error, me
 		})
 		l.Error(message+", invalid field", logger.Error(err))
 		return true
+	} else if st.Code() == codes.FailedPrecondition && hasIfMatch(c) {
+		c.JSON(http.StatusPreconditionFailed, models.ErrorWithDescription{
+			Code:        http.StatusPreconditionFailed,
+			Description: st.Message(),
+		})
+		l.Error(message+", version mismatch", logger.Error(err))
+		return true
 	} else if st.Code() == codes.Code(20) {
 		c.JSON(http.StatusBadRequest, models.ErrorWithDescription{
 			Code:        http.StatusBadRequest,
@@ -140,10 +196,10 @@ func ParsePageQueryParam(c *gin.Context) (uint64, error) {
 	return page, nil
 }
 
-func ParseLimitQueryParam(c *gin.Context) (uint64, error) {
+func ParseLimitQueryParam(c *gin.Context, defaultLimit, maxLimit uint64) (uint64, error) {
 	limitStr := c.Query("limit")
 	if limitStr == "" {
-		limitStr = "2"
+		return defaultLimit, nil
 	}
 
 	limit, err := strconv.ParseUint(limitStr, 10, 30)
@@ -152,7 +208,11 @@ func ParseLimitQueryParam(c *gin.Context) (uint64, error) {
 	}
 
 	if limit == 0 {
-		return 2, nil
+		return defaultLimit, nil
+	}
+
+	if maxLimit > 0 && limit > maxLimit {
+		return maxLimit, nil
 	}
 
 	return limit, nil
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"microservice/api/helpers"
+	"microservice/genproto/user_service"
+	"microservice/pkg/logger"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// branchHours collects the hours fields shared by CreateBranch, UpdateBranch
+// and Branch. open_time/close_time are the default for every day; schedule
+// overrides single weekdays and holidays override single dates.
+type branchHours struct {
+	timezone  string
+	openTime  string
+	closeTime string
+	schedule  []*user_service.BranchHours
+	holidays  []*user_service.BranchHoliday
+}
+
+func (h *handler) openingHours(b branchHours) (helpers.OpeningHours, error) {
+	hours := helpers.OpeningHours{
+		Timezone: b.timezone,
+		Default:  helpers.DayHours{Open: b.openTime, Close: b.closeTime},
+		Weekdays: make(map[time.Weekday]helpers.DayHours, len(b.schedule)),
+		Holidays: make(map[string]helpers.DayHours, len(b.holidays)),
+	}
+	if hours.Timezone == "" {
+		hours.Timezone = h.cfg.DefaultTimezone
+	}
+
+	for _, day := range b.schedule {
+		weekday := time.Weekday(day.Weekday)
+		if _, ok := hours.Weekdays[weekday]; ok {
+			return hours, fmt.Errorf("weekday %d is scheduled more than once", day.Weekday)
+		}
+		hours.Weekdays[weekday] = helpers.DayHours{Open: day.OpenTime, Close: day.CloseTime, Closed: day.Closed}
+	}
+
+	for _, day := range b.holidays {
+		if _, ok := hours.Holidays[day.Date]; ok {
+			return hours, fmt.Errorf("holiday %s is listed more than once", day.Date)
+		}
+		hours.Holidays[day.Date] = helpers.DayHours{Open: day.OpenTime, Close: day.CloseTime, Closed: day.Closed}
+	}
+
+	return hours, nil
+}
+
+func (h *handler) branchSchedule(b branchHours) (helpers.Schedule, error) {
+	hours, err := h.openingHours(b)
+	if err != nil {
+		return helpers.Schedule{}, err
+	}
+	return helpers.ParseOpeningHours(hours)
+}
+
+// validateBranchHours checks the hours of a branch being created or updated.
+func (h *handler) validateBranchHours(b branchHours) error {
+	if b.timezone != "" {
+		if err := helpers.ValidateTimezone(b.timezone); err != nil {
+			return err
+		}
+	}
+
+	_, err := h.branchSchedule(b)
+	return err
+}
+
+func createBranchHours(req *user_service.CreateBranch) branchHours {
+	return branchHours{req.Timezone, req.OpenTime, req.CloseTime, req.Schedule, req.Holidays}
+}
+
+func updateBranchHours(req *user_service.UpdateBranch) branchHours {
+	return branchHours{req.Timezone, req.OpenTime, req.CloseTime, req.Schedule, req.Holidays}
+}
+
+// decorateBranch fills the computed is_open_now and next_open_at fields.
+// Branches whose stored hours do not parse are reported as closed.
+func (h *handler) decorateBranch(b *user_service.Branch, now time.Time) {
+	if b == nil {
+		return
+	}
+
+	b.IsOpenNow, b.NextOpenAt = false, ""
+
+	schedule, err := h.branchSchedule(branchHours{b.Timezone, b.OpenTime, b.CloseTime, b.Schedule, b.Holidays})
+	if err != nil {
+		h.log.Debug("branch has invalid opening hours", logger.String("id", b.Id), logger.Error(err))
+		return
+	}
+
+	if schedule.IsOpen(now) {
+		b.IsOpenNow = true
+		return
+	}
+
+	if next, ok := schedule.NextOpen(now); ok {
+		b.NextOpenAt = next.Format(time.RFC3339)
+	}
+}
+
+// branchOpenVariant is the ETag variant of a decorated branch. next_open_at
+// only changes when the branch opens, so the open state is enough.
+func branchOpenVariant(b *user_service.Branch) string {
+	if b.IsOpenNow {
+		return "open"
+	}
+	return "closed"
+}
+
+// listOpenBranches answers GetListBranch with open_now set. The user service
+// has no notion of opening hours, so every matching branch is read, filtered
+// here and paginated in memory; more than BranchScanLimit of them are
+// refused rather than listed in part.
+func (h *handler) listOpenBranches(c *gin.Context, req *user_service.GetListBranchRequest, openNow bool) (*user_service.GetListBranchResponse, error) {
+	if req.Cursor != "" {
+		return nil, invalidArgument(errors.New("cursor cannot be combined with open_now, use page"))
+	}
+
+	var (
+		now     = time.Now()
+		matched []*user_service.Branch
+	)
+
+	err := h.scanBranches(readContext(c), req, h.cfg.BranchScanLimit, func(branch *user_service.Branch) {
+		h.decorateBranch(branch, now)
+		if branch.IsOpenNow == openNow {
+			matched = append(matched, branch)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &user_service.GetListBranchResponse{Count: int64(len(matched))}
+
+	start := (req.Page - 1) * req.Limit
+	if start < uint64(len(matched)) {
+		end := start + req.Limit
+		if end > uint64(len(matched)) {
+			end = uint64(len(matched))
+		}
+		resp.Branches = matched[start:end]
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"microservice/api/models"
+	"microservice/pkg/idempotency"
+	"microservice/pkg/logger"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotencyReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+)
+
+// idempotentHeaders are the response headers a replay repeats.
+var idempotentHeaders = []string{"Content-Type", "Location", "ETag", "Link", "Vary"}
+
+// Idempotency makes POST requests carrying an Idempotency-Key header safe to
+// retry. The first response for a key (scoped to the caller) is stored and
+// replayed, with its idempotentHeaders, for retries with the same method,
+// URL, body and negotiated response format; reusing the key for a different
+// request, or while the first one is still running, is rejected with 409.
+// 5xx responses are not stored so the request can be retried. Records expire
+// after IdempotencyTTL.
+//
+// Keys need an Authorization header to be scoped to, as anonymous callers
+// would share them, and bodies are limited to IdempotencyMaxBody.
+func (h *handler) Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if h.idempotency == nil || c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+
+		owner := principal(c)
+		if owner == "anonymous" {
+			abortWithError(c, http.StatusUnauthorized, "Idempotency-Key needs an Authorization header to scope the key to")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, h.cfg.IdempotencyMaxBody))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				abortWithError(c, http.StatusRequestEntityTooLarge, fmt.Sprintf("requests with an Idempotency-Key can have at most %d bytes of body", tooLarge.Limit))
+				return
+			}
+			abortWithError(c, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		rec := idempotency.Record{
+			Key:         hashParts(owner, key),
+			Fingerprint: hashParts(c.Request.Method, c.Request.URL.RequestURI(), string(body), c.NegotiateFormat(offeredFormats...)),
+			ExpiresAt:   time.Now().Add(h.cfg.IdempotencyTTL),
+		}
+
+		existing, reserved, err := h.idempotency.Reserve(c.Request.Context(), rec)
+		if err != nil {
+			h.log.Error("failed to reserve idempotency key", logger.Error(err))
+			abortWithError(c, http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+
+		if !reserved {
+			switch {
+			case existing.Fingerprint != rec.Fingerprint:
+				abortWithError(c, http.StatusConflict, "Idempotency-Key was already used for a different request")
+			case !existing.Completed():
+				abortWithError(c, http.StatusConflict, "a request with this Idempotency-Key is still being processed")
+			default:
+				c.Header(idempotencyReplayedHeader, "true")
+				for name, values := range existing.Header {
+					for _, value := range values {
+						c.Writer.Header().Add(name, value)
+					}
+				}
+				c.Data(existing.Status, existing.Header.Get("Content-Type"), existing.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		completed := false
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if !completed {
+				if err := h.idempotency.Release(ctx, rec.Key); err != nil {
+					h.log.Error("failed to release idempotency key", logger.Error(err))
+				}
+				return
+			}
+
+			rec.Status = w.Status()
+			rec.Header = http.Header{}
+			for _, name := range idempotentHeaders {
+				if values := w.Header().Values(name); len(values) > 0 {
+					rec.Header[http.CanonicalHeaderKey(name)] = values
+				}
+			}
+			rec.Body = w.body.Bytes()
+			if err := h.idempotency.Complete(ctx, rec); err != nil {
+				h.log.Error("failed to store idempotent response", logger.Error(err))
+			}
+		}()
+
+		c.Next()
+		completed = w.Written() && w.Status() < http.StatusInternalServerError
+	}
+}
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+func hashParts(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func abortWithError(c *gin.Context, code int, description string) {
+	c.AbortWithStatusJSON(code, models.ErrorWithDescription{
+		Code:        code,
+		Description: description,
+	})
+}
@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"microservice/api/models"
+	"microservice/pkg/export"
+	"microservice/pkg/jobs"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	importModeDryRun = "dry_run"
+	importModeCommit = "commit"
+)
+
+// importTarget describes a resource that can be created from CSV rows.
+type importTarget struct {
+	name     string
+	event    string
+	message  func() proto.Message
+	validate func(msg proto.Message) error
+	create   func(ctx context.Context, msg proto.Message) (string, error)
+}
+
+// importedRow is a parsed CSV row ready to be sent to the user service.
+type importedRow struct {
+	line int
+	msg  proto.Message
+}
+
+// runImport parses the uploaded CSV ("file" form field), maps its header to
+// the fields of the target's create message, and validates every row. With
+// mode=dry_run (the default) only the row-by-row report is returned. With
+// mode=commit a file without invalid rows is created through the user
+// service in a background job whose ID is returned with 202.
+func (h *handler) runImport(c *gin.Context, target importTarget) {
+	mode := c.DefaultQuery("mode", importModeDryRun)
+	if mode != importModeDryRun && mode != importModeCommit {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(fmt.Errorf("mode %s is not supported, use dry_run or commit", mode)), "error while parsing mode")
+		return
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(errors.New("csv file is required in the file field")), "error while reading upload")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "error while reading upload")
+		return
+	}
+	defer file.Close()
+
+	rows, report, err := h.parseImport(file, target)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing csv")
+		return
+	}
+
+	if mode == importModeDryRun {
+		report.DryRun = true
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	if report.Invalid > 0 {
+		c.JSON(http.StatusBadRequest, report)
+		return
+	}
+
+	reqCtx := c.Request.Context()
+	job, ok := h.submitJob(c, "import_"+target.name, len(rows), func(ctx context.Context, t *jobs.Task) (interface{}, error) {
+		var created []string
+		defer func() { h.publish(reqCtx, changesOf(target.event, created)) }()
+
+		results := make([]models.ImportRow, 0, len(rows))
+		for _, row := range rows {
+			if err := ctx.Err(); err != nil {
+				return results, err
+			}
+
+			result := models.ImportRow{Row: row.line, Valid: true}
+			id, err := target.create(ctx, row.msg)
+			if err != nil {
+				result.Errors = []string{bulkError(err).Description}
+				t.Add(1, 1)
+			} else {
+				result.ID = id
+				created = append(created, id)
+				t.Add(1, 0)
+			}
+			results = append(results, result)
+		}
+		return results, nil
+	})
+	if !ok {
+		return
+	}
+
+	report.JobID = job.ID
+	c.JSON(http.StatusAccepted, report)
+}
+
+func (h *handler) parseImport(r io.Reader, target importTarget) ([]importedRow, models.ImportReport, error) {
+	var (
+		report models.ImportReport
+		rows   []importedRow
+		reader = csv.NewReader(r)
+		md     = target.message().ProtoReflect().Descriptor()
+	)
+
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	names, err := reader.Read()
+	if err != nil {
+		return nil, report, errors.New("csv file must start with a header row")
+	}
+
+	columns := make([]protoreflect.FieldDescriptor, len(names))
+	for i, name := range names {
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\xEF\xBB\xBF")
+		}
+		if columns[i] = importColumn(md, name); columns[i] == nil {
+			return nil, report, fmt.Errorf("unknown column %q", name)
+		}
+	}
+
+	report.Rows = []models.ImportRow{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, report, err
+		}
+
+		if len(rows)+report.Invalid >= h.cfg.ImportMaxRows {
+			return nil, report, fmt.Errorf("csv file can have at most %d rows", h.cfg.ImportMaxRows)
+		}
+
+		line, _ := reader.FieldPos(0)
+		result := models.ImportRow{Row: line}
+		msg := target.message()
+
+		for i, value := range record {
+			if i >= len(columns) {
+				result.Errors = append(result.Errors, "row has more values than the header")
+				break
+			}
+			if err := setImportValue(msg.ProtoReflect(), columns[i], value); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+
+		if len(result.Errors) == 0 {
+			if err := target.validate(msg); err != nil {
+				result.Errors = append(result.Errors, err.Error())
+			}
+		}
+
+		report.Total++
+		result.Valid = len(result.Errors) == 0
+		if result.Valid {
+			report.Valid++
+			rows = append(rows, importedRow{line: line, msg: msg})
+		} else {
+			report.Invalid++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	return rows, report, nil
+}
+
+// importColumn finds the field a CSV header refers to: its proto name or any
+// of the localized titles used by exports, so exported files can be
+// imported back.
+func importColumn(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	name = strings.TrimSpace(name)
+
+	if fd := md.Fields().ByName(protoreflect.Name(strings.ToLower(name))); fd != nil {
+		return fd
+	}
+
+	for field, titles := range exportHeaders {
+		fd := md.Fields().ByName(protoreflect.Name(field))
+		if fd == nil {
+			continue
+		}
+		for _, title := range titles {
+			if strings.EqualFold(title, name) {
+				return fd
+			}
+		}
+	}
+
+	return nil
+}
+
+func setImportValue(m protoreflect.Message, fd protoreflect.FieldDescriptor, value string) error {
+	value = export.UnescapeCell(strings.TrimSpace(value))
+	if value == "" {
+		return nil
+	}
+
+	if fd.IsList() {
+		if fd.Kind() != protoreflect.StringKind {
+			return fmt.Errorf("%s: unsupported column", fd.Name())
+		}
+		list := m.Mutable(fd).List()
+		for _, item := range strings.Split(value, ";") {
+			if item = strings.TrimSpace(item); item != "" {
+				list.Append(protoreflect.ValueOfString(item))
+			}
+		}
+		return nil
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		m.Set(fd, protoreflect.ValueOfString(value))
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not true or false", fd.Name(), value)
+		}
+		m.Set(fd, protoreflect.ValueOfBool(b))
+	case protoreflect.Int32Kind, protoreflect.Int64Kind:
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a number", fd.Name(), value)
+		}
+		if fd.Kind() == protoreflect.Int32Kind {
+			m.Set(fd, protoreflect.ValueOfInt32(int32(i)))
+		} else {
+			m.Set(fd, protoreflect.ValueOfInt64(i))
+		}
+	default:
+		return fmt.Errorf("%s: unsupported column", fd.Name())
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"testing"
+
+	"microservice/genproto/user_service"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestImportColumn(t *testing.T) {
+	md := (&user_service.CreateBranch{}).ProtoReflect().Descriptor()
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"name", "name"},
+		{" Phone ", "phone"},
+		{"Время открытия", "open_time"},
+		{"faol", "active"},
+		{"password", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if fd := importColumn(md, tt.header); fd != nil {
+			got = string(fd.Name())
+		}
+		if got != tt.want {
+			t.Errorf("importColumn(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSetImportValue(t *testing.T) {
+	tests := []struct {
+		field   string
+		value   string
+		want    proto.Message
+		wantErr bool
+	}{
+		{"name", " Tea ", &user_service.CreateBranch{Name: "Tea"}, false},
+		{"name", "'=1+1", &user_service.CreateBranch{Name: "=1+1"}, false},
+		{"active", "true", &user_service.CreateBranch{Active: true}, false},
+		{"active", "yes", &user_service.CreateBranch{}, true},
+		{"payment_types", "cash; card;", &user_service.CreateShop{PaymentTypes: []string{"cash", "card"}}, false},
+		{"holidays", "2026-01-01", &user_service.CreateBranch{}, true},
+		{"name", "", &user_service.CreateBranch{}, false},
+	}
+
+	for _, tt := range tests {
+		msg := tt.want.ProtoReflect().New().Interface()
+		fd := msg.ProtoReflect().Descriptor().Fields().ByName(protoreflect.Name(tt.field))
+		err := setImportValue(msg.ProtoReflect(), fd, tt.value)
+		if (err != nil) != tt.wantErr || !proto.Equal(msg, tt.want) {
+			t.Errorf("setImportValue(%s, %q) = %v, %v, want %v, error %v", tt.field, tt.value, msg, err, tt.want, tt.wantErr)
+		}
+	}
+}
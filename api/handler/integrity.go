@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservice/api/models"
+	"microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
+	"microservice/pkg/logger"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The user service does not enforce seller.shop_id (or branch.shop_id), so
+// the gateway checks that the shop exists when a seller is written and
+// looks for dependents when a shop is deleted.
+
+// shopChecker checks that referenced shops exist, remembering the answers
+// for the duration of one request so that bulk requests and imports look
+// each shop up once. Lookups bypass the response cache, so a shop deleted
+// a moment ago is not taken to exist.
+type shopChecker struct {
+	h    *handler
+	ctx  context.Context
+	mu   sync.Mutex // guards seen, not the lookups
+	seen map[string]error
+}
+
+func (h *handler) newShopChecker(ctx context.Context) *shopChecker {
+	ctx = grpc_client.WithoutCache(context.WithoutCancel(ctx))
+	return &shopChecker{h: h, ctx: ctx, seen: make(map[string]error)}
+}
+
+// check returns an InvalidArgument error when shop id is empty or does not
+// exist and the upstream error when it could not be looked up.
+func (s *shopChecker) check(id string) error {
+	if id == "" {
+		return invalidArgument(errors.New("shop_id is required"))
+	}
+
+	s.mu.Lock()
+	err, ok := s.seen[id]
+	s.mu.Unlock()
+	if ok {
+		return err
+	}
+
+	_, err = s.h.grpcClient.ShopService().GetByID(s.ctx, &user_service.ShopPrimaryKey{Id: id})
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.NotFound:
+		err = invalidArgument(fmt.Errorf("shop %s does not exist", id))
+	default:
+		return err
+	}
+
+	s.mu.Lock()
+	s.seen[id] = err
+	s.mu.Unlock()
+	return err
+}
+
+// plainError drops the gRPC prefix from err for per-item validation errors,
+// which callers report as text.
+func plainError(err error) error {
+	if st, ok := status.FromError(err); ok && err != nil {
+		return errors.New(st.Message())
+	}
+	return err
+}
+
+// shopDependents returns the sellers and branches that reference shop id,
+// with includeDeleted the soft-deleted ones too. At most MaxPageSize IDs of
+// each are listed; the counts are complete.
+func (h *handler) shopDependents(ctx context.Context, id string, includeDeleted bool) (models.ShopDependents, error) {
+	deps := models.ShopDependents{ShopID: id}
+
+	sellers, err := h.grpcClient.SellerService().GetList(ctx, &user_service.GetListSellerRequest{
+		Page:           1,
+		Limit:          h.cfg.MaxPageSize,
+		ShopId:         id,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		return deps, err
+	}
+
+	branches, err := h.grpcClient.BranchService().GetList(ctx, &user_service.GetListBranchRequest{
+		Page:           1,
+		Limit:          h.cfg.MaxPageSize,
+		ShopId:         id,
+		IncludeDeleted: includeDeleted,
+	})
+	if err != nil {
+		return deps, err
+	}
+
+	deps.SellerCount, deps.BranchCount = sellers.Count, branches.Count
+	deps.SellerIDs = make([]string, 0, len(sellers.Sellers))
+	for _, seller := range sellers.Sellers {
+		deps.SellerIDs = append(deps.SellerIDs, seller.Id)
+	}
+	deps.BranchIDs = make([]string, 0, len(branches.Branches))
+	for _, branch := range branches.Branches {
+		deps.BranchIDs = append(deps.BranchIDs, branch.Id)
+	}
+
+	return deps, nil
+}
+
+// cascadeRoundLimit bounds how many pages of dependents deleteShopDependents
+// deletes before it gives up on a shop whose dependents keep reappearing.
+const cascadeRoundLimit = 100
+
+// deleteShopCascade deletes shop req.Id and then its sellers and branches.
+// The shop goes first, so that a stale ExpectedUpdatedAt deletes nothing and
+// no seller can be attached to it in the meantime. If the dependents cannot
+// all be deleted, the shop and those that were are restored; otherwise
+// their deletion is published, the shop's being left to the caller.
+func (h *handler) deleteShopCascade(ctx context.Context, req *user_service.ShopPrimaryKey) (*user_service.Empty4, error) {
+	resp, err := h.grpcClient.ShopService().Delete(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := h.deleteShopDependents(ctx, req.Id)
+	if err != nil {
+		h.undoShopDelete(context.WithoutCancel(ctx), req.Id, deleted)
+		return nil, err
+	}
+
+	h.publish(ctx, append(changesOf("seller.deleted", deleted.SellerIDs), changesOf("branch.deleted", deleted.BranchIDs)...))
+	return resp, nil
+}
+
+// deleteShopDependents soft-deletes every seller and branch of shop id,
+// a page at a time, until none is left. It returns the IDs it deleted, also
+// when it fails.
+func (h *handler) deleteShopDependents(ctx context.Context, id string) (models.ShopDependents, error) {
+	ctx = grpc_client.WithoutCache(ctx)
+	deleted := models.ShopDependents{ShopID: id}
+
+	for round := 0; ; round++ {
+		deps, err := h.shopDependents(ctx, id, false)
+		if err != nil {
+			return deleted, err
+		}
+		if deps.SellerCount == 0 && deps.BranchCount == 0 {
+			return deleted, nil
+		}
+		if round >= cascadeRoundLimit {
+			return deleted, status.Error(codes.Internal, "shop dependents are not going away")
+		}
+
+		if len(deps.SellerIDs) > 0 {
+			_, err = h.grpcClient.SellerService().BulkDelete(ctx, &user_service.BulkDeleteSellerRequest{Ids: deps.SellerIDs})
+			if err == nil {
+				deleted.SellerIDs = append(deleted.SellerIDs, deps.SellerIDs...)
+			} else if status.Code(err) == codes.Unimplemented {
+				err = deleteEach(deps.SellerIDs, &deleted.SellerIDs, func(id string) error {
+					_, err := h.grpcClient.SellerService().Delete(ctx, &user_service.SellerPrimaryKey{Id: id})
+					return err
+				})
+			}
+			if err != nil {
+				return deleted, err
+			}
+		}
+
+		if len(deps.BranchIDs) > 0 {
+			_, err = h.grpcClient.BranchService().BulkDelete(ctx, &user_service.BulkDeleteBranchRequest{Ids: deps.BranchIDs})
+			if err == nil {
+				deleted.BranchIDs = append(deleted.BranchIDs, deps.BranchIDs...)
+			} else if status.Code(err) == codes.Unimplemented {
+				err = deleteEach(deps.BranchIDs, &deleted.BranchIDs, func(id string) error {
+					_, err := h.grpcClient.BranchService().Delete(ctx, &user_service.BranchPrimaryKey{Id: id})
+					return err
+				})
+			}
+			if err != nil {
+				return deleted, err
+			}
+		}
+	}
+}
+
+// deleteEach deletes ids one by one, appending those it deleted to done.
+// Records that are already gone are skipped.
+func deleteEach(ids []string, done *[]string, del func(id string) error) error {
+	for _, id := range ids {
+		err := del(id)
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		*done = append(*done, id)
+	}
+	return nil
+}
+
+// undoShopDelete restores the dependents a failed cascade deleted, and
+// then the shop. Failures are logged, as the cascade's error is what the
+// caller is told about.
+func (h *handler) undoShopDelete(ctx context.Context, id string, deleted models.ShopDependents) {
+	for _, sellerID := range deleted.SellerIDs {
+		if _, err := h.grpcClient.SellerService().Restore(ctx, &user_service.SellerPrimaryKey{Id: sellerID}); err != nil {
+			h.log.Error("failed to restore seller after a failed cascade", logger.String("seller_id", sellerID), logger.String("shop_id", id), logger.Error(err))
+		}
+	}
+	for _, branchID := range deleted.BranchIDs {
+		if _, err := h.grpcClient.BranchService().Restore(ctx, &user_service.BranchPrimaryKey{Id: branchID}); err != nil {
+			h.log.Error("failed to restore branch after a failed cascade", logger.String("branch_id", branchID), logger.String("shop_id", id), logger.Error(err))
+		}
+	}
+	if _, err := h.grpcClient.ShopService().Restore(ctx, &user_service.ShopPrimaryKey{Id: id}); err != nil {
+		h.log.Error("failed to restore shop after a failed cascade", logger.String("shop_id", id), logger.Error(err))
+	}
+}
+
+// shopDependentsError refuses to delete a shop that still has sellers or
+// branches. It is an Aborted status, which GraphQL answers like the 409 of
+// DeleteShop, and carries the dependents as the error's extensions.
+type shopDependentsError struct {
+	deps models.ShopDependents
+}
+
+func (e *shopDependentsError) Error() string {
+	return fmt.Sprintf("shop %s has %d sellers and %d branches, delete them first or pass cascade: true", e.deps.ShopID, e.deps.SellerCount, e.deps.BranchCount)
+}
+
+func (e *shopDependentsError) GRPCStatus() *status.Status {
+	return status.New(codes.Aborted, e.Error())
+}
+
+func (e *shopDependentsError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":         http.StatusConflict,
+		"shop_id":      e.deps.ShopID,
+		"seller_count": e.deps.SellerCount,
+		"seller_ids":   e.deps.SellerIDs,
+		"branch_count": e.deps.BranchCount,
+		"branch_ids":   e.deps.BranchIDs,
+	}
+}
+
+// writeShopDependents answers 409 with what still references the shop and
+// hint on what to do about it.
+func writeShopDependents(c *gin.Context, deps models.ShopDependents, hint string) {
+	deps.Code = http.StatusConflict
+	deps.Description = fmt.Sprintf("shop has %d sellers and %d branches, %s", deps.SellerCount, deps.BranchCount, hint)
+	c.JSON(http.StatusConflict, deps)
+}
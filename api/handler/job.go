@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservice/api/models"
+	"microservice/pkg/jobs"
+	"microservice/pkg/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Security ApiKeyAuth
+// @Router         /jobs/{id} [GET]
+// @Summary        Get a background job by ID
+// @Description    API for polling the status, progress and result of a background job. Jobs are visible to the caller that submitted them and to admins
+// @Tags           job
+// @Accept         json
+// @Produce        json
+// @Param          id path string true "job ID"
+// @Success        200 {object} jobs.Job
+// @Failure        401 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetJob(c *gin.Context) {
+	job, ok := h.getOwnJob(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// @Security ApiKeyAuth
+// @Router         /jobs/{id}/cancel [POST]
+// @Summary        Cancel a background job
+// @Description    API for cancelling a pending or running background job. Items already processed are not rolled back
+// @Tags           job
+// @Accept         json
+// @Produce        json
+// @Param          id path string true "job ID"
+// @Success        200 {object} jobs.Job
+// @Failure        401 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        409 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) CancelJob(c *gin.Context) {
+	job, ok := h.getOwnJob(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.jobs.Cancel(c.Request.Context(), job.ID)
+	if err != nil {
+		h.handleJobErr(c, err, "failed to cancel job")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// @Security ApiKeyAuth
+// @Router         /jobs/{id}/download [GET]
+// @Summary        Download the result of a background job
+// @Description    API for downloading the file produced by a finished job, such as an asynchronous export
+// @Tags           job
+// @Produce        octet-stream
+// @Param          id path string true "job ID"
+// @Success        200 {file} file
+// @Failure        401 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        409 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) DownloadJob(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	job, ok := h.getOwnJob(c)
+	if !ok {
+		return
+	}
+	if job.Status != jobs.StatusSucceeded {
+		c.JSON(http.StatusConflict, models.ErrorWithDescription{
+			Code:        http.StatusConflict,
+			Description: fmt.Sprintf("job is %s", job.Status),
+		})
+		return
+	}
+
+	file, err := h.jobs.File(ctx, job.ID)
+	if err != nil {
+		h.handleJobErr(c, err, "failed to get job file")
+		return
+	}
+	content, err := file.Open()
+	if err != nil {
+		h.handleJobErr(c, err, "failed to open job file")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, file.Name))
+	c.Header("Content-Type", file.ContentType)
+	http.ServeContent(c.Writer, c.Request, file.Name, job.UpdatedAt, content)
+}
+
+// getOwnJob gets the job in the id path parameter if the caller submitted it
+// or is an admin; other callers' jobs are reported as not found. On failure
+// it writes the error response and returns false.
+func (h *handler) getOwnJob(c *gin.Context) (jobs.Job, bool) {
+	owner := principal(c)
+	if owner == "anonymous" {
+		abortWithError(c, http.StatusUnauthorized, "an access token is required for background jobs")
+		return jobs.Job{}, false
+	}
+
+	job, err := h.jobs.Get(c.Request.Context(), c.Param("id"))
+	if err == nil && job.Owner != owner {
+		if claims, cerr := parseToken(bearerToken(c)); cerr != nil || claims.Role != adminRole {
+			err = jobs.ErrNotFound
+		}
+	}
+	if err != nil {
+		h.handleJobErr(c, err, "failed to get job")
+		return jobs.Job{}, false
+	}
+
+	return job, true
+}
+
+// submitJob queues fn as a job of the caller and points the Location header
+// at it. Jobs are only visible to their owner, so anonymous callers cannot
+// submit any. On failure it writes the error response and returns false.
+func (h *handler) submitJob(c *gin.Context, typ string, total int, fn jobs.Func) (jobs.Job, bool) {
+	owner := principal(c)
+	if owner == "anonymous" {
+		abortWithError(c, http.StatusUnauthorized, "an access token is required for background jobs")
+		return jobs.Job{}, false
+	}
+
+	job, err := h.jobs.Submit(c.Request.Context(), typ, owner, total, fn)
+	if err != nil {
+		h.handleJobErr(c, err, "failed to submit job")
+		return jobs.Job{}, false
+	}
+
+	c.Header("Location", "/jobs/"+job.ID)
+	return job, true
+}
+
+// parseAsync reads ?async=true. On failure it writes 400.
+func (h *handler) parseAsync(c *gin.Context) (bool, bool) {
+	async, err := parseBoolQueryParam(c, "async")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing async")
+		return false, false
+	}
+
+	return async != nil && *async, true
+}
+
+func (h *handler) handleJobErr(c *gin.Context, err error, message string) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, jobs.ErrNotFound), errors.Is(err, jobs.ErrNoFile):
+		code = http.StatusNotFound
+	case errors.Is(err, jobs.ErrFinished):
+		code = http.StatusConflict
+	case errors.Is(err, jobs.ErrQueueFull):
+		code = http.StatusServiceUnavailable
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		code = http.StatusServiceUnavailable
+	}
+
+	description := err.Error()
+	if code == http.StatusInternalServerError {
+		h.log.Error(message, logger.Error(err))
+		description = "Internal Server Error"
+	}
+
+	c.JSON(code, models.ErrorWithDescription{
+		Code:        code,
+		Description: description,
+	})
+}
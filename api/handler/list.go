@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"microservice/api/helpers"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	customerSortFields = []string{"created_at", "updated_at", "firstname", "lastname", "email", "date_of_birth"}
+	userSortFields     = []string{"created_at", "updated_at", "name", "role"}
+	sellerSortFields   = []string{"created_at", "updated_at", "name"}
+	branchSortFields   = []string{"created_at", "updated_at", "name", "open_time"}
+	shopSortFields     = []string{"created_at", "updated_at", "slug", "name_uz", "name_ru", "name_en"}
+)
+
+// listParams holds the query parameters every GetList endpoint accepts.
+type listParams struct {
+	Page          uint64
+	Limit         uint64
+	Cursor        string
+	Sort          string
+	Search        string
+	CreatedAtFrom string
+	CreatedAtTo   string
+	// IncludeDeleted lists soft-deleted records along with the others;
+	// OnlyDeleted lists just them (the trash).
+	IncludeDeleted bool
+	OnlyDeleted    bool
+}
+
+// parseListParams reads page/limit/cursor/sort/search, the created_at range
+// and the deleted filters from the query string. On invalid input it writes
+// 400 and returns false, as it does with 401 or 403 when someone other than
+// an admin asks for deleted records.
+func (h *handler) parseListParams(c *gin.Context, sortFields []string) (listParams, bool) {
+	var (
+		p   = listParams{Search: c.Query("search"), Cursor: c.Query("cursor")}
+		err error
+	)
+
+	p.Page, err = ParsePageQueryParam(c)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing page")
+		return p, false
+	}
+
+	p.Limit, err = ParseLimitQueryParam(c, h.cfg.DefaultPageSize, h.cfg.MaxPageSize)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing limit")
+		return p, false
+	}
+
+	p.Sort, err = parseSortQueryParam(c.Query("sort"), sortFields)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing sort")
+		return p, false
+	}
+
+	p.CreatedAtFrom, p.CreatedAtTo = c.Query("created_at_from"), c.Query("created_at_to")
+	if err = validateDateRange(p.CreatedAtFrom, p.CreatedAtTo); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing created_at range")
+		return p, false
+	}
+
+	for key, dst := range map[string]*bool{"include_deleted": &p.IncludeDeleted, "only_deleted": &p.OnlyDeleted} {
+		value, err := parseBoolQueryParam(c, key)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing "+key)
+			return p, false
+		}
+		*dst = value != nil && *value
+	}
+	if p.IncludeDeleted || p.OnlyDeleted {
+		if !requireRole(c, adminRole) {
+			return p, false
+		}
+	}
+	if p.OnlyDeleted {
+		p.IncludeDeleted = true
+	}
+
+	return p, true
+}
+
+// parseSortQueryParam validates "field:asc,field:desc" against the allowed
+// fields and returns it normalized; the direction defaults to asc.
+func parseSortQueryParam(sort string, allowed []string) (string, error) {
+	if sort == "" {
+		return "", nil
+	}
+
+	var parts []string
+	for _, item := range strings.Split(sort, ",") {
+		field, dir, _ := strings.Cut(strings.TrimSpace(item), ":")
+		if dir == "" {
+			dir = "asc"
+		}
+
+		if !contains(allowed, field) {
+			return "", fmt.Errorf("sort field %q is not allowed, use one of: %s", field, strings.Join(allowed, ", "))
+		}
+
+		dir = strings.ToLower(dir)
+		if dir != "asc" && dir != "desc" {
+			return "", fmt.Errorf("sort direction %q must be asc or desc", dir)
+		}
+
+		parts = append(parts, field+":"+dir)
+	}
+
+	return strings.Join(parts, ","), nil
+}
+
+func validateDateRange(from, to string) error {
+	if from != "" && to != "" {
+		return helpers.ValidateDates(from, to)
+	}
+
+	for _, date := range []string{from, to} {
+		if date == "" {
+			continue
+		}
+		if err := helpers.ValidateDateTime(date); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setLinkHeader writes an RFC 8288 Link header for a list response. With a
+// cursor from the user service only "next" is known; in page mode first,
+// prev, next and last are derived from count.
+func setLinkHeader(c *gin.Context, p listParams, count int64, nextCursor string) {
+	var links []string
+
+	link := func(rel string, set map[string]string) {
+		u := *c.Request.URL
+		q := u.Query()
+		for k, v := range set {
+			if v == "" {
+				q.Del(k)
+				continue
+			}
+			q.Set(k, v)
+		}
+		u.RawQuery = q.Encode()
+		links = append(links, fmt.Sprintf("<%s>; rel=%q", u.RequestURI(), rel))
+	}
+
+	if nextCursor != "" {
+		link("next", map[string]string{"cursor": nextCursor, "page": ""})
+	}
+
+	if p.Cursor == "" {
+		lastPage := uint64(1)
+		if p.Limit > 0 && count > 0 {
+			lastPage = (uint64(count) + p.Limit - 1) / p.Limit
+		}
+
+		link("first", map[string]string{"page": "1"})
+		if p.Page > 1 {
+			link("prev", map[string]string{"page": fmt.Sprint(p.Page - 1)})
+		}
+		if nextCursor == "" && p.Page < lastPage {
+			link("next", map[string]string{"page": fmt.Sprint(p.Page + 1)})
+		}
+		link("last", map[string]string{"page": fmt.Sprint(lastPage)})
+	}
+
+	if len(links) > 0 {
+		c.Header("Link", strings.Join(links, ", "))
+	}
+}
+
+func parseBoolQueryParam(c *gin.Context, key string) (*bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	switch strings.ToLower(value) {
+	case "true", "1":
+		b := true
+		return &b, nil
+	case "false", "0":
+		b := false
+		return &b, nil
+	}
+
+	return nil, errors.New(key + " must be true or false")
+}
+
+// invalidArgument turns a parsing error into a status the error handler maps
+// to 400 instead of 500.
+func invalidArgument(err error) error {
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+
+func contains(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
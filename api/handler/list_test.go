@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseSortQueryParam(t *testing.T) {
+	allowed := []string{"created_at", "name"}
+	tests := []struct {
+		sort    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"name", "name:asc", false},
+		{"name:DESC, created_at", "name:desc,created_at:asc", false},
+		{"email:asc", "", true},
+		{"name:up", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSortQueryParam(tt.sort, allowed)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseSortQueryParam(%q) = %q, %v, want %q, error %v", tt.sort, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestValidateDateRange(t *testing.T) {
+	tests := []struct {
+		from, to string
+		wantErr  bool
+	}{
+		{"", "", false},
+		{"2026-01-01 00:00:00", "", false},
+		{"", "2026-01-01 00:00:00", false},
+		{"2026-01-01 00:00:00", "2026-02-01 00:00:00", false},
+		{"2026-02-01 00:00:00", "2026-01-01 00:00:00", true},
+		{"2026-01-01", "", true},
+	}
+
+	for _, tt := range tests {
+		if err := validateDateRange(tt.from, tt.to); (err != nil) != tt.wantErr {
+			t.Errorf("validateDateRange(%q, %q) = %v, want error %v", tt.from, tt.to, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetLinkHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		params     listParams
+		count      int64
+		nextCursor string
+		want       string
+	}{
+		{
+			name:   "first page",
+			url:    "/GetListShop?limit=2&search=a",
+			params: listParams{Page: 1, Limit: 2},
+			count:  5,
+			want:   `</GetListShop?limit=2&page=1&search=a>; rel="first", </GetListShop?limit=2&page=2&search=a>; rel="next", </GetListShop?limit=2&page=3&search=a>; rel="last"`,
+		},
+		{
+			name:   "last page",
+			url:    "/GetListShop?limit=2&page=3",
+			params: listParams{Page: 3, Limit: 2},
+			count:  5,
+			want:   `</GetListShop?limit=2&page=1>; rel="first", </GetListShop?limit=2&page=2>; rel="prev", </GetListShop?limit=2&page=3>; rel="last"`,
+		},
+		{
+			name:   "empty",
+			url:    "/GetListShop",
+			params: listParams{Page: 1},
+			want:   `</GetListShop?page=1>; rel="first", </GetListShop?page=1>; rel="last"`,
+		},
+		{
+			name:       "cursor",
+			url:        "/GetListShop?cursor=abc&limit=2",
+			params:     listParams{Page: 1, Limit: 2, Cursor: "abc"},
+			count:      5,
+			nextCursor: "def",
+			want:       `</GetListShop?cursor=def&limit=2>; rel="next"`,
+		},
+		{
+			name:       "first cursor page",
+			url:        "/GetListShop?limit=2&page=1",
+			params:     listParams{Page: 1, Limit: 2},
+			count:      5,
+			nextCursor: "def",
+			want:       `</GetListShop?cursor=def&limit=2>; rel="next", </GetListShop?limit=2&page=1>; rel="first", </GetListShop?limit=2&page=3>; rel="last"`,
+		},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", tt.url, nil)
+
+		setLinkHeader(c, tt.params, tt.count, tt.nextCursor)
+		if got := w.Header().Get("Link"); got != tt.want {
+			t.Errorf("%s: Link = %s\nwant %s", tt.name, got, tt.want)
+		}
+	}
+}
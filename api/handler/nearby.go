@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"math"
+	"microservice/api/helpers"
+	"microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// defaultNearbyRadius and maxNearbyRadius are in meters.
+	defaultNearbyRadius = 5000
+	maxNearbyRadius     = 100000
+)
+
+// branchCoordinates returns the coordinates to store for a branch: point if
+// set, otherwise location parsed as "lat,lng", otherwise nil.
+func branchCoordinates(location string, point *user_service.GeoPoint) (*user_service.GeoPoint, error) {
+	if point != nil {
+		if err := helpers.ValidateCoordinates(point.Latitude, point.Longitude); err != nil {
+			return nil, err
+		}
+		return point, nil
+	}
+
+	if lat, lng, ok := helpers.ParseLatLng(location); ok {
+		return &user_service.GeoPoint{Latitude: lat, Longitude: lng}, nil
+	}
+	return nil, nil
+}
+
+// indexedBranch is a branch with the coordinates it is indexed under.
+type indexedBranch struct {
+	lat, lng float64
+	branch   *user_service.Branch
+}
+
+// branchIndex answers nearby queries in the gateway when the user service
+// does not implement GetNearby. It is rebuilt from GetList at most once per
+// ttl, so it lags behind writes by up to CACHE_BRANCH_TTL. Clients cannot
+// force a rebuild: Cache-Control: no-cache on /branches/nearby would
+// otherwise let anyone make the gateway rescan every branch.
+type branchIndex struct {
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu      sync.Mutex
+	builtAt time.Time
+	points  []indexedBranch // sorted by latitude
+}
+
+func newBranchIndex(ttl time.Duration) *branchIndex {
+	return &branchIndex{ttl: ttl}
+}
+
+// snapshot returns the current points, rebuilding them with build when they
+// are older than ttl. Concurrent callers share one rebuild, which runs
+// without holding mu so readers of the old points are not blocked; a caller
+// whose ctx ends stops waiting but does not cancel the rebuild.
+func (x *branchIndex) snapshot(ctx context.Context, build func(context.Context) ([]indexedBranch, error)) ([]indexedBranch, error) {
+	x.mu.Lock()
+	points, builtAt := x.points, x.builtAt
+	x.mu.Unlock()
+
+	if points != nil && time.Since(builtAt) < x.ttl {
+		return points, nil
+	}
+
+	ch := x.group.DoChan("", func() (interface{}, error) {
+		shared := context.WithoutCancel(ctx)
+		if deadline, ok := ctx.Deadline(); ok {
+			var cancel context.CancelFunc
+			shared, cancel = context.WithDeadline(shared, deadline)
+			defer cancel()
+		}
+
+		points, err := build(shared)
+		if err != nil {
+			return nil, err
+		}
+
+		sort.Slice(points, func(i, j int) bool { return points[i].lat < points[j].lat })
+		x.mu.Lock()
+		x.points, x.builtAt = points, time.Now()
+		x.mu.Unlock()
+		return points, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-ch:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.([]indexedBranch), nil
+	}
+}
+
+// nearbyFromIndex is GetNearby evaluated over the gateway's branch index.
+func (h *handler) nearbyFromIndex(c *gin.Context, req *user_service.GetNearbyBranchRequest) (*user_service.GetListBranchResponse, error) {
+	points, err := h.branchIndex.snapshot(c.Request.Context(), h.indexBranches)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only points within the radius in latitude alone can be within it.
+	span := helpers.LatitudeSpan(req.Radius)
+	first := sort.Search(len(points), func(i int) bool { return points[i].lat >= req.Latitude-span })
+
+	var found []*user_service.Branch
+	for _, p := range points[first:] {
+		if p.lat > req.Latitude+span {
+			break
+		}
+		if req.Active != nil && p.branch.Active != *req.Active {
+			continue
+		}
+
+		distance := helpers.Haversine(req.Latitude, req.Longitude, p.lat, p.lng)
+		if distance > req.Radius {
+			continue
+		}
+
+		branch := proto.Clone(p.branch).(*user_service.Branch)
+		branch.Distance = math.Round(distance)
+		found = append(found, branch)
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Distance < found[j].Distance })
+
+	resp := &user_service.GetListBranchResponse{Count: int64(len(found))}
+	if uint64(len(found)) > req.Limit {
+		found = found[:req.Limit]
+	}
+	resp.Branches = found
+
+	return resp, nil
+}
+
+// indexBranches reads every branch with coordinates, bypassing the response
+// cache so the index is no staler than its own ttl. BranchScanLimit does not
+// apply: an index missing branches would leave them out of every search.
+func (h *handler) indexBranches(ctx context.Context) ([]indexedBranch, error) {
+	var points []indexedBranch
+
+	err := h.scanBranches(grpc_client.WithoutCache(ctx), &user_service.GetListBranchRequest{}, 0, func(branch *user_service.Branch) {
+		point, _ := branchCoordinates(branch.Location, branch.Coordinates)
+		if point == nil {
+			return
+		}
+		points = append(points, indexedBranch{lat: point.Latitude, lng: point.Longitude, branch: branch})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// An empty non-nil slice marks the index as built.
+	if points == nil {
+		points = []indexedBranch{}
+	}
+	return points, nil
+}
+
+func parseCoordinateQueryParam(c *gin.Context, key string) (float64, error) {
+	value := c.Query(key)
+	if value == "" {
+		return 0, errors.New(key + " is required")
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, errors.New(key + " must be a number")
+	}
+	return f, nil
+}
+
+// parseNearbyParams reads lat, lng, radius, limit and active. On invalid
+// input it writes 400 and returns false.
+func (h *handler) parseNearbyParams(c *gin.Context) (*user_service.GetNearbyBranchRequest, bool) {
+	var (
+		req = &user_service.GetNearbyBranchRequest{Radius: defaultNearbyRadius}
+		err error
+	)
+
+	if req.Latitude, err = parseCoordinateQueryParam(c, "lat"); err == nil {
+		req.Longitude, err = parseCoordinateQueryParam(c, "lng")
+	}
+	if err == nil {
+		err = helpers.ValidateCoordinates(req.Latitude, req.Longitude)
+	}
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing coordinates")
+		return nil, false
+	}
+
+	if value := c.Query("radius"); value != "" {
+		req.Radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || !(req.Radius > 0 && req.Radius <= maxNearbyRadius) {
+			err = errors.New("radius must be a number of meters between 0 and " + strconv.Itoa(maxNearbyRadius))
+			handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing radius")
+			return nil, false
+		}
+	}
+
+	req.Limit, err = ParseLimitQueryParam(c, h.cfg.DefaultPageSize, h.cfg.MaxPageSize)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing limit")
+		return nil, false
+	}
+
+	req.Active, err = parseBoolQueryParam(c, "active")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing active")
+		return nil, false
+	}
+
+	return req, true
+}
+
+// @Security ApiKeyAuth
+// @Router         /branches/nearby [GET]
+// @Summary        Find branches near a point
+// @Description    API for finding the branches within radius meters of lat/lng, nearest first, with their distance in meters. Branches without coordinates are never returned
+// @Tags           branch
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param          lat query number true "latitude"
+// @Param          lng query number true "longitude"
+// @Param          radius query number false "search radius in meters, default 5000, at most 100000"
+// @Param          limit query int false "limit"
+// @Param          active query boolean false "filter by active flag"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
+// @Success 200    {object} user_service.GetListBranchResponse
+// @Failure 400    {object} models.ResponseError
+// @Failure 500    {object} models.ResponseError
+func (h *handler) NearbyBranches(c *gin.Context) {
+	req, ok := h.parseNearbyParams(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.grpcClient.BranchService().GetNearby(readContext(c), req)
+	if status.Code(err) == codes.Unimplemented {
+		resp, err = h.nearbyFromIndex(c, req)
+	}
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to find nearby branches")
+		return
+	}
+
+	now := time.Now()
+	for _, branch := range resp.Branches {
+		h.decorateBranch(branch, now)
+	}
+
+	setCacheControl(c, h.cfg.CacheBranchTTL)
+	h.renderResponse(c, http.StatusOK, resp)
+}
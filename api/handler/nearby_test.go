@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"microservice/genproto/user_service"
+)
+
+func TestBranchCoordinates(t *testing.T) {
+	tests := []struct {
+		name     string
+		location string
+		point    *user_service.GeoPoint
+		want     *user_service.GeoPoint
+		wantErr  bool
+	}{
+		{"point wins", "1,2", &user_service.GeoPoint{Latitude: 3, Longitude: 4}, &user_service.GeoPoint{Latitude: 3, Longitude: 4}, false},
+		{"location", "41.3,69.2", nil, &user_service.GeoPoint{Latitude: 41.3, Longitude: 69.2}, false},
+		{"address", "Chilonzor 7", nil, nil, false},
+		{"invalid point", "", &user_service.GeoPoint{Latitude: 100}, nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := branchCoordinates(tt.location, tt.point)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+		if (got == nil) != (tt.want == nil) || got != nil && (got.Latitude != tt.want.Latitude || got.Longitude != tt.want.Longitude) {
+			t.Errorf("%s: branchCoordinates = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBranchIndexSnapshot(t *testing.T) {
+	var (
+		x       = newBranchIndex(time.Minute)
+		builds  atomic.Int32
+		release = make(chan struct{})
+	)
+	build := func(ctx context.Context) ([]indexedBranch, error) {
+		builds.Add(1)
+		<-release
+		return []indexedBranch{{lat: 2}, {lat: 1}}, nil
+	}
+
+	// Concurrent callers share one build, and one that gives up does not
+	// cancel it for the others.
+	gone, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := x.snapshot(gone, build); err == nil {
+		t.Fatal("snapshot with a done context: want an error")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			points, err := x.snapshot(context.Background(), build)
+			if err != nil || len(points) != 2 || points[0].lat != 1 {
+				t.Errorf("snapshot = %v, %v, want two points by latitude", points, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if _, err := x.snapshot(context.Background(), build); err != nil {
+		t.Fatal(err)
+	}
+	if n := builds.Load(); n != 1 {
+		t.Errorf("built %d times, want 1", n)
+	}
+}
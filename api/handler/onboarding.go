@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"microservice/api/helpers"
+	"microservice/api/models"
+	"microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
+	"microservice/pkg/logger"
+	"microservice/pkg/saga"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	onboardingSagaType = "seller_onboarding"
+	sellerRole         = "seller"
+)
+
+// onboardingSaga creates the shop, then the seller in it, then the seller's
+// system user. If a step fails the records created before it are deleted.
+// A step interrupted by a restart is run again and first looks for what the
+// interrupted run may have created: the shop by its slug, which is chosen
+// before the saga starts, and the seller and the user by phone.
+func (h *handler) onboardingSaga() saga.Definition {
+	return saga.Definition{
+		Type: onboardingSagaType,
+		Steps: []saga.Step{
+			{
+				Name: "create_shop",
+				Do: func(ctx context.Context, s *saga.Saga) (string, error) {
+					req, err := onboardingInput(s)
+					if err != nil {
+						return "", err
+					}
+
+					if s.Attempts("create_shop") > 1 {
+						shop, err := h.shopBySlug(grpc_client.WithoutCache(ctx), req.Shop.Slug)
+						if err == nil && shop.Phone == req.Shop.Phone {
+							return shop.Id, nil
+						}
+						if status.Code(err) != codes.NotFound && err != nil {
+							return "", err
+						}
+					}
+
+					resp, err := h.grpcClient.ShopService().Create(ctx, req.Shop)
+					if err != nil {
+						return "", err
+					}
+					return resp.Id, nil
+				},
+				Undo: func(ctx context.Context, s *saga.Saga, id string) error {
+					_, err := h.grpcClient.ShopService().Delete(ctx, &user_service.ShopPrimaryKey{Id: id})
+					return ignoreNotFound(err)
+				},
+			},
+			{
+				Name: "create_seller",
+				Do: func(ctx context.Context, s *saga.Saga) (string, error) {
+					req, err := onboardingInput(s)
+					if err != nil {
+						return "", err
+					}
+
+					seller := proto.Clone(req.Seller).(*user_service.CreateSeller)
+					seller.ShopId = s.ResourceID("create_shop")
+
+					// The shop is new, so a seller of it with this phone
+					// can only have been created by an earlier run.
+					list, err := h.grpcClient.SellerService().GetList(grpc_client.WithoutCache(ctx), &user_service.GetListSellerRequest{
+						Page:   1,
+						Limit:  h.cfg.MaxPageSize,
+						ShopId: seller.ShopId,
+						Search: seller.Phone,
+					})
+					if err != nil {
+						return "", err
+					}
+					for _, found := range list.Sellers {
+						if found.Phone == seller.Phone {
+							return found.Id, nil
+						}
+					}
+
+					resp, err := h.grpcClient.SellerService().Create(ctx, seller)
+					if err != nil {
+						return "", err
+					}
+					return resp.Id, nil
+				},
+				Undo: func(ctx context.Context, s *saga.Saga, id string) error {
+					_, err := h.grpcClient.SellerService().Delete(ctx, &user_service.SellerPrimaryKey{Id: id})
+					return ignoreNotFound(err)
+				},
+			},
+			{
+				Name: "create_user",
+				Do: func(ctx context.Context, s *saga.Saga) (string, error) {
+					req, err := onboardingInput(s)
+					if err != nil {
+						return "", err
+					}
+
+					user := proto.Clone(req.User).(*user_service.CreateUs)
+					user.Role = sellerRole
+
+					if s.Attempts("create_user") > 1 {
+						list, err := h.grpcClient.SystemUserService().GetList(grpc_client.WithoutCache(ctx), &user_service.GetListUsRequest{
+							Page:   1,
+							Limit:  h.cfg.MaxPageSize,
+							Role:   sellerRole,
+							Search: user.Phone,
+						})
+						if err != nil {
+							return "", err
+						}
+						for _, found := range list.Users {
+							if found.Phone == user.Phone {
+								return found.Id, nil
+							}
+						}
+					}
+
+					resp, err := h.grpcClient.SystemUserService().Create(ctx, user)
+					if err != nil {
+						return "", err
+					}
+					return resp.Id, nil
+				},
+				Undo: func(ctx context.Context, s *saga.Saga, id string) error {
+					_, err := h.grpcClient.SystemUserService().Delete(ctx, &user_service.UsPrimaryKey{Id: id})
+					return ignoreNotFound(err)
+				},
+			},
+		},
+	}
+}
+
+func onboardingInput(s *saga.Saga) (models.OnboardingRequest, error) {
+	var req models.OnboardingRequest
+	err := json.Unmarshal(s.Input, &req)
+	return req, err
+}
+
+// ignoreNotFound treats deleting a record that is already gone as done.
+func ignoreNotFound(err error) error {
+	if status.Code(err) == codes.NotFound {
+		return nil
+	}
+	return err
+}
+
+func validateOnboarding(req *models.OnboardingRequest) error {
+	if req.Shop == nil || req.Seller == nil || req.User == nil {
+		return errors.New("shop, seller and user are required")
+	}
+
+	if err := helpers.ValidatePhone(req.Shop.Phone); err != nil {
+		return errors.New("shop: " + err.Error())
+	}
+	if err := validatePhoneAndEmail(req.Seller.Phone, req.Seller.Email); err != nil {
+		return errors.New("seller: " + err.Error())
+	}
+	if err := validatePhoneAndEmail(req.User.Phone, req.User.Gmail); err != nil {
+		return errors.New("user: " + err.Error())
+	}
+	return nil
+}
+
+func (h *handler) handleSagaErr(c *gin.Context, err error, message string) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, saga.ErrNotFound) {
+		code = http.StatusNotFound
+	}
+
+	description := err.Error()
+	if code == http.StatusInternalServerError {
+		h.log.Error(message, logger.Error(err))
+		description = "Internal Server Error"
+	}
+
+	c.JSON(code, models.ErrorWithDescription{
+		Code:        code,
+		Description: description,
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router         /v1/onboarding [POST]
+// @Summary        Onboard a merchant
+// @Description    API for creating a shop, its seller and the seller's system user (role seller) in one go. The steps run as a saga: if one fails the records already created are deleted and the response is 422 (status compensated) or, when a deletion failed too, 500 (status failed, see steps for what is left). The saga state is kept and can be read at /v1/onboarding/{id}
+// @Tags           onboarding
+// @Accept         json
+// @Produce        json
+// @Param          onboarding body models.OnboardingRequest true "shop, seller and user"
+// @Success        201 {object} saga.Saga
+// @Failure        400 {object} models.ResponseError
+// @Failure        409 {object} models.SlugAvailability
+// @Failure        422 {object} saga.Saga
+// @Failure        500 {object} saga.Saga
+func (h *handler) OnboardSeller(c *gin.Context) {
+	var req models.OnboardingRequest
+
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while decoding onboarding request")
+		return
+	}
+
+	if err := validateOnboarding(&req); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating onboarding request")
+		return
+	}
+
+	if req.Shop.Slug == "" {
+		if !h.generateShopSlug(c, req.Shop) {
+			return
+		}
+	} else if !h.checkShopSlug(c, req.Shop.Slug, "") {
+		return
+	}
+
+	s, err := h.sagas.Start(c.Request.Context(), onboardingSagaType, req)
+	if err != nil && s.ID == "" {
+		h.handleSagaErr(c, err, "failed to start onboarding")
+		return
+	}
+
+	setAuditResourceID(c, s.ID)
+	code := http.StatusCreated
+	switch s.Status {
+	case saga.StatusSucceeded:
+		h.publish(c.Request.Context(), onboardingChanges(s))
+		c.Header("Location", "/v1/onboarding/"+s.ID)
+	case saga.StatusCompensated:
+		code = http.StatusUnprocessableEntity
+	default:
+		code = http.StatusInternalServerError
+	}
+
+	c.JSON(code, s)
+}
+
+// @Security ApiKeyAuth
+// @Router         /v1/onboarding/{id} [GET]
+// @Summary        Get a merchant onboarding
+// @Description    API for reading the step-by-step state of an onboarding saga
+// @Tags           onboarding
+// @Produce        json
+// @Param          id path string true "onboarding ID"
+// @Success        200 {object} saga.Saga
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetOnboarding(c *gin.Context) {
+	s, err := h.sagas.Get(c.Request.Context(), c.Param("id"))
+	if err == nil && s.Type != onboardingSagaType {
+		err = saga.ErrNotFound
+	}
+	if err != nil {
+		h.handleSagaErr(c, err, "failed to get onboarding")
+		return
+	}
+
+	c.JSON(http.StatusOK, s)
+}
+
+// onboardingChanges returns the records an onboarding created.
+func onboardingChanges(s saga.Saga) []change {
+	var changes []change
+	for _, step := range s.Steps {
+		typ := strings.TrimPrefix(step.Name, "create_")
+		if step.ResourceID != "" && contains(eventResources, typ) {
+			changes = append(changes, change{event: typ + ".created", resourceType: typ, resourceID: step.ResourceID})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"microservice/api/helpers"
+	"microservice/api/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// offeredFormats are the response encodings picked from the Accept header.
+// The first one is used when the client does not send Accept.
+var offeredFormats = []string{
+	binding.MIMEJSON,
+	binding.MIMEPROTOBUF,
+	binding.MIMEMSGPACK2,
+	binding.MIMEMSGPACK,
+}
+
+var protojsonUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// renderResponse writes msg in the format negotiated from the Accept header:
+// protojson (the default), protobuf wire format or MessagePack. The fields=
+// (sparse fieldset) and lang= (collapse localized fields) query parameters
+// are applied first; lang has no effect on protobuf responses.
+func (h *handler) renderResponse(c *gin.Context, code int, msg proto.Message) {
+	vary(c, "Accept")
+	format := c.NegotiateFormat(offeredFormats...)
+	if format == "" {
+		c.JSON(http.StatusNotAcceptable, models.ErrorWithDescription{
+			Code:        http.StatusNotAcceptable,
+			Description: "supported formats: application/json, application/x-protobuf, application/msgpack",
+		})
+		return
+	}
+
+	lang := c.Query("lang")
+	if lang != "" {
+		if err := helpers.ValidateLanguage(lang); err != nil {
+			handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing lang")
+			return
+		}
+	}
+
+	fields := helpers.ParseFields(msg, c.Query("fields"), lang)
+	if err := helpers.PruneFields(msg, fields); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing fields")
+		return
+	}
+
+	switch format {
+	case binding.MIMEPROTOBUF:
+		body, err := proto.Marshal(msg)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "error while encoding response")
+			return
+		}
+		c.Data(code, binding.MIMEPROTOBUF, body)
+
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		obj, err := h.renderer.Object(msg, fields, lang)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "error while encoding response")
+			return
+		}
+		c.Header("Content-Type", format)
+		c.Render(code, render.MsgPack{Data: helpers.NativeNumbers(obj)})
+
+	default:
+		body, err := h.renderer.Marshal(msg, fields, lang)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "error while encoding response")
+			return
+		}
+		c.Data(code, "application/json; charset=utf-8", body)
+	}
+}
+
+// bindRequest decodes the request body into msg according to Content-Type:
+// protojson (the default), protobuf wire format or MessagePack. Unknown
+// fields are ignored. On failure it writes 400/415 and returns false.
+func (h *handler) bindRequest(c *gin.Context, msg proto.Message) bool {
+	var err error
+
+	switch c.ContentType() {
+	case binding.MIMEJSON, "":
+		var body []byte
+		if body, err = io.ReadAll(c.Request.Body); err == nil {
+			err = protojsonUnmarshal.Unmarshal(body, msg)
+		}
+
+	case binding.MIMEPROTOBUF:
+		var body []byte
+		if body, err = io.ReadAll(c.Request.Body); err == nil {
+			err = proto.Unmarshal(body, msg)
+		}
+
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		var obj map[string]interface{}
+		if err = binding.MsgPack.Bind(c.Request, &obj); err != nil {
+			break
+		}
+		if obj == nil {
+			err = errors.New("request body must be an object")
+			break
+		}
+
+		var body []byte
+		if body, err = json.Marshal(helpers.StringKeys(obj)); err == nil {
+			err = protojsonUnmarshal.Unmarshal(body, msg)
+		}
+
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, models.ErrorWithDescription{
+			Code:        http.StatusUnsupportedMediaType,
+			Description: "supported content types: application/json, application/x-protobuf, application/msgpack",
+		})
+		return false
+	}
+
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "invalid request body")
+		return false
+	}
+
+	return true
+}
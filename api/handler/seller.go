@@ -1,12 +1,15 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"microservice/api/helpers"
 	"microservice/genproto/user_service"
+	"microservice/pkg/logger"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/proto"
 )
 
 // @Security ApiKeyAuth
@@ -14,10 +17,11 @@ import (
 // @Summary       Create seller
 // @Description   API for creating seller
 // @Tags          seller
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         seller body user_service.CreateSeller true "seller"
 // @Success 200   {object} user_service.CreateSeller
+// @Failure 400   {object} models.ResponseError
 // @Failure 404   {object} models.ResponseError
 // @Failure 500   {object} models.ResponseError
 func (h *handler) CreateSeller(c *gin.Context) {
@@ -26,18 +30,23 @@ func (h *handler) CreateSeller(c *gin.Context) {
 		resp *user_service.Seller
 		err  error
 	)
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
 		return
 	}
 
 	if err := helpers.ValidateEmailAddress(req.Email); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "erroe while validating email"+req.Email)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating email "+req.Email)
+		return
+	}
+
+	if err := h.newShopChecker(c.Request.Context()).check(req.ShopId); err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "error while checking shop")
+		return
 	}
 
 	resp, err = h.grpcClient.SellerService().Create(c.Request.Context(), &req)
@@ -45,7 +54,8 @@ func (h *handler) CreateSeller(c *gin.Context) {
 		handleGrpcErrWithDescription(c, h.log, err, "failed to create customer")
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	setAuditResourceID(c, resp.Id)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -53,11 +63,20 @@ func (h *handler) CreateSeller(c *gin.Context) {
 // @Summary        Get List seller
 // @Description    API for getting list seller
 // @Tags           seller
-// @Accept         json
-// @Produce        json
-// @Param		   seller query string false "sellers"
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param		   search query string false "search"
 // @Param		   page query int false "page"
 // @Param		   limit query int false "limit"
+// @Param		   cursor query string false "cursor from next_cursor of the previous page"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   shop_id query string false "filter by shop ID"
+// @Param		   fields query string false "comma separated fields to return"
+// @Param		   lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success 200    {object} user_service.GetListSellerResponse
 // @Failure 404    {object} models.ResponseError
 // @Failure 500    {object} models.ResponseError
@@ -67,22 +86,22 @@ func (h *handler) GetListSeller(c *gin.Context) {
 		resp *user_service.GetListSellerResponse
 		err  error
 	)
-	req.Search = c.Query("search")
-
-	page, err := strconv.ParseUint(c.DefaultQuery("page", "1"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing page")
+	params, ok := h.parseListParams(c, sellerSortFields)
+	if !ok {
 		return
 	}
 
-	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "10"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing limit")
-		return
-	}
+	req.Page = params.Page
+	req.Limit = params.Limit
+	req.Search = params.Search
+	req.Cursor = params.Cursor
+	req.Sort = params.Sort
+	req.CreatedAtFrom = params.CreatedAtFrom
+	req.CreatedAtTo = params.CreatedAtTo
+	req.IncludeDeleted = params.IncludeDeleted
+	req.OnlyDeleted = params.OnlyDeleted
 
-	req.Page = page
-	req.Limit = limit
+	req.ShopId = c.Query("shop_id")
 
 	resp, err = h.grpcClient.SellerService().GetList(c, &req)
 	if err != nil {
@@ -90,7 +109,8 @@ func (h *handler) GetListSeller(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	setLinkHeader(c, params, resp.Count, resp.NextCursor)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -98,10 +118,14 @@ func (h *handler) GetListSeller(c *gin.Context) {
 // @Summary        Get a single seller by ID
 // @Description    API for getting a single seller by ID
 // @Tags           seller
-// @Accept         json
-// @Produce        json
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
 // @Param          id path string true "seller ID"
+// @Param          If-None-Match header string false "etag of the cached copy"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success        200 {object} user_service.Seller
+// @Success        304 "not modified"
 // @Failure        404 {object} models.ResponseError
 // @Failure        500 {object} models.ResponseError
 func (h *handler) GetSellerByID(c *gin.Context) {
@@ -121,7 +145,11 @@ func (h *handler) GetSellerByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	if WriteETag(c, resp.UpdatedAt) {
+		return
+	}
+
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -129,11 +157,14 @@ func (h *handler) GetSellerByID(c *gin.Context) {
 // @Summary         Update a seller by ID
 // @Description     API for updating a seller by ID
 // @Tags            seller
-// @Accept          json
-// @Produce         json
+// @Accept          json,application/x-protobuf,application/msgpack
+// @Produce         json,application/x-protobuf,application/msgpack
 // @Param           id path string true "seller ID"
 // @Param           seller body user_service.UpdateSeller true "seller"
+// @Param           If-Match header string false "etag the seller must still have"
 // @Success         200 {object} user_service.UpdateSeller
+// @Failure         400 {object} models.ResponseError
+// @Failure         412 {object} models.ResponseError
 // @Failure         404 {object} models.ResponseError
 // @Failure         500 {object} models.ResponseError
 func (h *handler) UpdateSeller(c *gin.Context) {
@@ -142,30 +173,42 @@ func (h *handler) UpdateSeller(c *gin.Context) {
 		req  user_service.UpdateSeller
 		resp *user_service.Seller
 		err  error
+		ok   bool
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
 		return
 	}
 
 	if err := helpers.ValidateEmailAddress(req.Email); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "erroe while validating email"+req.Email)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating email "+req.Email)
+		return
+	}
+
+	if err := h.newShopChecker(c.Request.Context()).check(req.ShopId); err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "error while checking shop")
+		return
 	}
 
 	req.Id = id
-	resp, err = h.grpcClient.SellerService().Update(c, &req)
+	req.ExpectedUpdatedAt, ok = ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	resp, err = h.grpcClient.SellerService().Update(c.Request.Context(), &req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -173,10 +216,12 @@ func (h *handler) UpdateSeller(c *gin.Context) {
 // @Summary       Delete a seller by ID
 // @Description   API for deleting a seller by ID
 // @Tags          seller
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         id path string true "seller ID"
+// @Param         If-Match header string false "etag the seller must still have"
 // @Success       200 {object} user_service.Empty2
+// @Failure       412 {object} models.ResponseError
 // @Failure       404 {object} models.ResponseError
 // @Failure       500 {object} models.ResponseError
 func (h *handler) DeleteSeller(c *gin.Context) {
@@ -186,8 +231,14 @@ func (h *handler) DeleteSeller(c *gin.Context) {
 		resp *user_service.Empty2
 	)
 
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	req := &user_service.SellerPrimaryKey{
-		Id: id,
+		Id:                id,
+		ExpectedUpdatedAt: expectedUpdatedAt,
 	}
 
 	resp, err = h.grpcClient.SellerService().Delete(c, req)
@@ -196,5 +247,333 @@ func (h *handler) DeleteSeller(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/sellers/{id}/restore [POST]
+// @Summary       Restore a deleted seller
+// @Description   API for undoing the soft delete of a seller whose shop still exists; restore the shop first otherwise
+// @Tags          seller
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "seller ID"
+// @Param         If-Match header string false "etag the deleted seller must still have"
+// @Success       200 {object} user_service.Seller
+// @Failure       400 {object} models.ResponseError
+// @Failure       412 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) RestoreSeller(c *gin.Context) {
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.grpcClient.SellerService().Restore(c.Request.Context(), &user_service.SellerPrimaryKey{
+		Id:                c.Param("id"),
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to restore seller")
+		return
+	}
+
+	// The seller's shop_id is only known once it is restored; a seller of a
+	// deleted shop goes back to the trash.
+	if err := h.newShopChecker(c.Request.Context()).check(resp.ShopId); err != nil {
+		_, derr := h.grpcClient.SellerService().Delete(context.WithoutCancel(c.Request.Context()), &user_service.SellerPrimaryKey{
+			Id:                resp.Id,
+			ExpectedUpdatedAt: resp.UpdatedAt,
+		})
+		if derr != nil {
+			h.log.Error("failed to delete seller restored without its shop", logger.String("id", resp.Id), logger.Error(derr))
+		}
+		handleGrpcErrWithDescription(c, h.log, err, "error while checking shop, restore it first")
+		return
+	}
+
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/admin/sellers/{id} [DELETE]
+// @Summary       Purge a seller
+// @Description   API for permanently deleting a seller; admin only. The seller is gone for good and cannot be restored
+// @Tags          seller
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "seller ID"
+// @Param         confirm query string true "the seller ID again"
+// @Success       200 {object} user_service.Empty2
+// @Failure       400 {object} models.ResponseError
+// @Failure       401 {object} models.ResponseError
+// @Failure       403 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) PurgeSeller(c *gin.Context) {
+	id := c.Param("id")
+	if !h.confirmPurge(c, id) {
+		return
+	}
+
+	resp, err := h.grpcClient.SellerService().Purge(c.Request.Context(), &user_service.SellerPrimaryKey{Id: id})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to purge seller")
+		return
+	}
+
+	h.logPurge(c, "seller", id)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /BulkCreateSeller [post]
+// @Summary       Bulk create sellers
+// @Description   API for creating many sellers; with atomic=true all are created or none
+// @Tags          seller
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         sellers body user_service.BulkCreateSellerRequest true "sellers"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkCreateSeller(c *gin.Context) {
+	var req user_service.BulkCreateSellerRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	shops := h.newShopChecker(c.Request.Context())
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_create_sellers",
+		event: "seller.created",
+		count: len(req.Sellers),
+		validate: func(i int) error {
+			if err := validatePhoneAndEmail(req.Sellers[i].Phone, req.Sellers[i].Email); err != nil {
+				return err
+			}
+			return plainError(shops.check(req.Sellers[i].ShopId))
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			resp, err := h.grpcClient.SellerService().Create(ctx, req.Sellers[i])
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			resp, err := h.grpcClient.SellerService().BulkCreate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]string, 0, len(resp.Sellers))
+			for _, item := range resp.Sellers {
+				ids = append(ids, item.Id)
+			}
+			return ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /BulkUpdateSeller [put]
+// @Summary       Bulk update sellers
+// @Description   API for updating many sellers by ID; with atomic=true all are updated or none
+// @Tags          seller
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         sellers body user_service.BulkUpdateSellerRequest true "sellers"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkUpdateSeller(c *gin.Context) {
+	var req user_service.BulkUpdateSellerRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	shops := h.newShopChecker(c.Request.Context())
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_update_sellers",
+		event: "seller.updated",
+		count: len(req.Sellers),
+		validate: func(i int) error {
+			if req.Sellers[i].Id == "" {
+				return errors.New("id is required")
+			}
+			if err := validatePhoneAndEmail(req.Sellers[i].Phone, req.Sellers[i].Email); err != nil {
+				return err
+			}
+			return plainError(shops.check(req.Sellers[i].ShopId))
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			resp, err := h.grpcClient.SellerService().Update(ctx, req.Sellers[i])
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			resp, err := h.grpcClient.SellerService().BulkUpdate(ctx, &req)
+			if err != nil {
+				return nil, err
+			}
+
+			ids := make([]string, 0, len(resp.Sellers))
+			for _, item := range resp.Sellers {
+				ids = append(ids, item.Id)
+			}
+			return ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /BulkDeleteSeller [delete]
+// @Summary       Bulk delete sellers
+// @Description   API for deleting many sellers by ID; with atomic=true all are deleted or none
+// @Tags          seller
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json
+// @Param         ids body user_service.BulkDeleteSellerRequest true "seller IDs"
+// @Param         atomic query boolean false "all-or-nothing mode"
+// @Param         async query boolean false "run in a background job, which needs an access token"
+// @Success 200   {object} models.BulkResponse
+// @Success 202   {object} jobs.Job
+// @Success 207   {object} models.BulkResponse
+// @Failure 400   {object} models.ResponseError
+// @Failure 500   {object} models.ResponseError
+func (h *handler) BulkDeleteSeller(c *gin.Context) {
+	var req user_service.BulkDeleteSellerRequest
+
+	if !h.bindRequest(c, &req) {
+		return
+	}
+
+	h.runBulk(c, bulkOperation{
+		name:  "bulk_delete_sellers",
+		event: "seller.deleted",
+		count: len(req.Ids),
+		validate: func(i int) error {
+			if req.Ids[i] == "" {
+				return errors.New("id is required")
+			}
+			return nil
+		},
+		single: func(ctx context.Context, i int) (string, error) {
+			_, err := h.grpcClient.SellerService().Delete(ctx, &user_service.SellerPrimaryKey{Id: req.Ids[i]})
+			return req.Ids[i], err
+		},
+		atomic: func(ctx context.Context) ([]string, error) {
+			if _, err := h.grpcClient.SellerService().BulkDelete(ctx, &req); err != nil {
+				return nil, err
+			}
+			return req.Ids, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router         /ExportSeller [GET]
+// @Summary        Export sellers
+// @Description    API for exporting sellers as CSV or XLSX, streamed page by page
+// @Tags           seller
+// @Produce        text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		   format query string false "csv (default) or xlsx"
+// @Param		   columns query string false "comma separated fields, default: id, name, phone, email, shop_id, created_at"
+// @Param		   lang query string false "header language: uz, ru or en (default)"
+// @Param		   search query string false "search"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   shop_id query string false "filter by shop ID"
+// @Param		   async query boolean false "build the file in a background job, which needs an access token"
+// @Success 200    {file} file
+// @Success 202    {object} jobs.Job
+// @Failure 400    {object} models.ResponseError
+// @Failure 500    {object} models.ResponseError
+func (h *handler) ExportSeller(c *gin.Context) {
+	params, ok := h.parseListParams(c, sellerSortFields)
+	if !ok {
+		return
+	}
+	shopID := c.Query("shop_id")
+
+	h.runExport(c, exportSource{
+		name:    "sellers",
+		item:    (&user_service.Seller{}).ProtoReflect().Descriptor(),
+		columns: []string{"id", "name", "phone", "email", "shop_id", "created_at"},
+		fetch: func(ctx context.Context, page, limit uint64, cursor string) (exportPage, error) {
+			resp, err := h.grpcClient.SellerService().GetList(ctx, &user_service.GetListSellerRequest{
+				Page:           page,
+				Limit:          limit,
+				Cursor:         cursor,
+				Search:         params.Search,
+				Sort:           params.Sort,
+				CreatedAtFrom:  params.CreatedAtFrom,
+				CreatedAtTo:    params.CreatedAtTo,
+				IncludeDeleted: params.IncludeDeleted,
+				OnlyDeleted:    params.OnlyDeleted,
+				ShopId:         shopID,
+			})
+			if err != nil {
+				return exportPage{}, err
+			}
+
+			result := exportPage{count: resp.Count, nextCursor: resp.NextCursor}
+			for _, item := range resp.Sellers {
+				result.items = append(result.items, item)
+			}
+			return result, nil
+		},
+	})
+}
+
+// @Security ApiKeyAuth
+// @Router        /ImportSeller [post]
+// @Summary       Import sellers from CSV
+// @Description   API for importing sellers from a CSV file whose header names CreateSeller fields (or exported column titles). mode=dry_run returns a row-by-row validation report; mode=commit creates the rows in a background job polled at /jobs/{id}
+// @Tags          seller
+// @Accept        mpfd
+// @Produce       json
+// @Param         file formData file true "CSV file"
+// @Param         mode query string false "dry_run (default) or commit, which runs in a background job and needs an access token"
+// @Success 200   {object} models.ImportReport
+// @Success 202   {object} models.ImportReport
+// @Failure 400   {object} models.ImportReport
+// @Failure 500   {object} models.ResponseError
+func (h *handler) ImportSeller(c *gin.Context) {
+	shops := h.newShopChecker(c.Request.Context())
+	h.runImport(c, importTarget{
+		name:    "sellers",
+		event:   "seller.created",
+		message: func() proto.Message { return &user_service.CreateSeller{} },
+		validate: func(msg proto.Message) error {
+			req := msg.(*user_service.CreateSeller)
+			if err := validatePhoneAndEmail(req.Phone, req.Email); err != nil {
+				return err
+			}
+			return plainError(shops.check(req.ShopId))
+		},
+		create: func(ctx context.Context, msg proto.Message) (string, error) {
+			resp, err := h.grpcClient.SellerService().Create(ctx, msg.(*user_service.CreateSeller))
+			if err != nil {
+				return "", err
+			}
+			return resp.Id, nil
+		},
+	})
 }
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"context"
 	"microservice/api/helpers"
+	"microservice/api/models"
 	"microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,13 +14,15 @@ import (
 // @Security ApiKeyAuth
 // @Router        /CreateShop [post]
 // @Summary       Create shop
-// @Description   API for creating shop
+// @Description   API for creating shop. Without a slug one is generated from name_en (or name_uz); a slug that is taken is rejected with suggestions
 // @Tags          shop
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         shop body user_service.CreateShop true "shop"
 // @Success 200   {object} user_service.Shop
+// @Failure 400   {object} models.ResponseError
 // @Failure 404   {object} models.ResponseError
+// @Failure 409   {object} models.SlugAvailability
 // @Failure 500   {object} models.ResponseError
 func (h *handler) CreateShop(c *gin.Context) {
 	var (
@@ -26,13 +30,20 @@ func (h *handler) CreateShop(c *gin.Context) {
 		resp *user_service.Shop
 		err  error
 	)
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number "+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
+		return
+	}
+
+	if req.Slug == "" {
+		if !h.generateShopSlug(c, &req) {
+			return
+		}
+	} else if !h.checkShopSlug(c, req.Slug, "") {
 		return
 	}
 
@@ -41,7 +52,8 @@ func (h *handler) CreateShop(c *gin.Context) {
 		handleGrpcErrWithDescription(c, h.log, err, "failed to create shop")
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	setAuditResourceID(c, resp.Id)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -49,11 +61,20 @@ func (h *handler) CreateShop(c *gin.Context) {
 // @Summary        Get list of shops
 // @Description    API for getting list of shops
 // @Tags           shop
-// @Accept         json
-// @Produce        json
-// @Param		   shop query string false "shops"
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param		   search query string false "search"
 // @Param		   page query int false "page"
 // @Param		   limit query int false "limit"
+// @Param		   cursor query string false "cursor from next_cursor of the previous page"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   currency query string false "filter by currency"
+// @Param		   fields query string false "comma separated fields to return"
+// @Param		   lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success 200    {object} user_service.GetListShopResponse
 // @Failure 404    {object} models.ResponseError
 // @Failure 500    {object} models.ResponseError
@@ -65,30 +86,32 @@ func (h *handler) GetListShop(c *gin.Context) {
 		err  error
 	)
 
-	req.Search = c.Query("search")
-
-	page, err := strconv.ParseUint(c.DefaultQuery("page", "1"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing page")
+	params, ok := h.parseListParams(c, shopSortFields)
+	if !ok {
 		return
 	}
 
-	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "10"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing limit")
-		return
-	}
+	req.Page = params.Page
+	req.Limit = params.Limit
+	req.Search = params.Search
+	req.Cursor = params.Cursor
+	req.Sort = params.Sort
+	req.CreatedAtFrom = params.CreatedAtFrom
+	req.CreatedAtTo = params.CreatedAtTo
+	req.IncludeDeleted = params.IncludeDeleted
+	req.OnlyDeleted = params.OnlyDeleted
 
-	req.Page = page
-	req.Limit = limit
+	req.Currency = c.Query("currency")
 
-	resp, err = h.grpcClient.ShopService().GetList(c.Request.Context(), &req)
+	resp, err = h.grpcClient.ShopService().GetList(readContext(c), &req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	setCacheControl(c, h.cfg.CacheShopTTL)
+	setLinkHeader(c, params, resp.Count, resp.NextCursor)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -96,10 +119,14 @@ func (h *handler) GetListShop(c *gin.Context) {
 // @Summary        Get a single shop by ID
 // @Description    API for getting a single shop by ID
 // @Tags           shop
-// @Accept         json
-// @Produce        json
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
 // @Param          id path string true "shop ID"
+// @Param          If-None-Match header string false "etag of the cached copy"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success        200 {object} user_service.Shop
+// @Success        304 "not modified"
 // @Failure        404 {object} models.ResponseError
 // @Failure        500 {object} models.ResponseError
 func (h *handler) GetShopByID(c *gin.Context) {
@@ -113,12 +140,93 @@ func (h *handler) GetShopByID(c *gin.Context) {
 		Id: id,
 	}
 
-	resp, err = h.grpcClient.ShopService().GetByID(c.Request.Context(), req)
+	resp, err = h.grpcClient.ShopService().GetByID(readContext(c), req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
+	setCacheControl(c, h.cfg.CacheShopTTL)
+	if WriteETag(c, resp.UpdatedAt) {
+		return
+	}
+
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router         /GetBySlugShop/{slug} [GET]
+// @Summary        Get a single shop by slug
+// @Description    API for resolving storefront URLs: getting a single shop by its slug
+// @Tags           shop
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param          slug path string true "shop slug"
+// @Param          If-None-Match header string false "etag of the cached copy"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
+// @Success        200 {object} user_service.Shop
+// @Success        304 "not modified"
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetShopBySlug(c *gin.Context) {
+	resp, err := h.shopBySlug(readContext(c), c.Param("slug"))
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to get shop by slug")
+		return
+	}
+
+	setCacheControl(c, h.cfg.CacheShopTTL)
+	if WriteETag(c, resp.UpdatedAt) {
+		return
+	}
+
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router         /SuggestShopSlug [GET]
+// @Summary        Check a shop slug and suggest free ones
+// @Description    API for checking whether a slug (or the slug generated from name) is free; when it is taken, free variants are suggested
+// @Tags           shop
+// @Produce        json
+// @Param          slug query string false "desired slug"
+// @Param          name query string false "shop name to generate the slug from when slug is empty"
+// @Param          id query string false "ID of the shop being edited, whose own slug counts as free"
+// @Success        200 {object} models.SlugAvailability
+// @Failure        400 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) SuggestShopSlug(c *gin.Context) {
+	slug := c.Query("slug")
+	if slug == "" {
+		slug = helpers.Slugify(c.Query("name"))
+	}
+	if err := helpers.ValidateSlug(slug); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating slug "+slug)
+		return
+	}
+
+	var (
+		ctx    = c.Request.Context()
+		except = c.Query("id")
+		resp   = models.SlugAvailability{Slug: slug, Suggestions: []string{}}
+		err    error
+	)
+
+	resp.Available, err = h.slugAvailable(ctx, slug, except)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to check slug")
+		return
+	}
+
+	if !resp.Available {
+		resp.Suggestions, err = h.suggestSlugs(ctx, slug, except)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "failed to suggest slugs")
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -127,11 +235,14 @@ func (h *handler) GetShopByID(c *gin.Context) {
 // @Summary         Update a shop by ID
 // @Description     API for updating a shop by ID
 // @Tags            shop
-// @Accept          json
-// @Produce         json
+// @Accept          json,application/x-protobuf,application/msgpack
+// @Produce         json,application/x-protobuf,application/msgpack
 // @Param           id path string true "shop ID"
 // @Param           shop body user_service.UpdateShop true "shop"
+// @Param           If-Match header string false "etag the shop must still have"
 // @Success         200 {object} user_service.Shop
+// @Failure         409 {object} models.SlugAvailability
+// @Failure         412 {object} models.ResponseError
 // @Failure         404 {object} models.ResponseError
 // @Failure         500 {object} models.ResponseError
 func (h *handler) UpdateShop(c *gin.Context) {
@@ -140,37 +251,52 @@ func (h *handler) UpdateShop(c *gin.Context) {
 		req  user_service.UpdateShop
 		resp *user_service.Shop
 		err  error
+		ok   bool
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number "+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
+		return
+	}
+
+	if req.Slug != "" && !h.checkShopSlug(c, req.Slug, id) {
 		return
 	}
 
 	req.Id = id
+	req.ExpectedUpdatedAt, ok = ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	resp, err = h.grpcClient.ShopService().Update(c.Request.Context(), &req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
 // @Router        /DeleteShop/{id} [DELETE]
 // @Summary       Delete a shop by ID
-// @Description   API for deleting a shop by ID
+// @Description   API for deleting a shop by ID. A shop that still has sellers or branches is not deleted (409 lists them) unless cascade=true, which deletes them after the shop (restoring it if they cannot be deleted); SHOP_DELETE_CASCADE sets the default
 // @Tags          shop
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         id path string true "shop ID"
+// @Param         cascade query boolean false "also delete the shop's sellers and branches"
+// @Param         If-Match header string false "etag the shop must still have"
 // @Success       200 {object} user_service.Empty4
+// @Failure       400 {object} models.ResponseError
+// @Failure       409 {object} models.ShopDependents
+// @Failure       412 {object} models.ResponseError
 // @Failure       404 {object} models.ResponseError
 // @Failure       500 {object} models.ResponseError
 func (h *handler) DeleteShop(c *gin.Context) {
@@ -180,15 +306,175 @@ func (h *handler) DeleteShop(c *gin.Context) {
 		resp *user_service.Empty4
 	)
 
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	cascade, err := parseBoolQueryParam(c, "cascade")
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing cascade")
+		return
+	}
+	if cascade == nil {
+		cascade = &h.cfg.ShopDeleteCascade
+	}
+
 	req := &user_service.ShopPrimaryKey{
-		Id: id,
+		Id:                id,
+		ExpectedUpdatedAt: expectedUpdatedAt,
 	}
 
-	resp, err = h.grpcClient.ShopService().Delete(c.Request.Context(), req)
+	if *cascade {
+		resp, err = h.deleteShopCascade(c.Request.Context(), req)
+	} else {
+		var deps models.ShopDependents
+		deps, err = h.shopDependents(grpc_client.WithoutCache(c.Request.Context()), id, false)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "failed to list shop dependents")
+			return
+		}
+		if deps.SellerCount > 0 || deps.BranchCount > 0 {
+			writeShopDependents(c, deps, "delete them first or pass cascade=true")
+			return
+		}
+
+		resp, err = h.grpcClient.ShopService().Delete(c.Request.Context(), req)
+	}
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/shops/{id}/restore [POST]
+// @Summary       Restore a deleted shop
+// @Description   API for undoing the soft delete of a shop
+// @Tags          shop
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "shop ID"
+// @Param         If-Match header string false "etag the deleted shop must still have"
+// @Success       200 {object} user_service.Shop
+// @Failure       412 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) RestoreShop(c *gin.Context) {
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.grpcClient.ShopService().Restore(c.Request.Context(), &user_service.ShopPrimaryKey{
+		Id:                c.Param("id"),
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to restore shop")
+		return
+	}
+
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/admin/shops/{id} [DELETE]
+// @Summary       Purge a shop
+// @Description   API for permanently deleting a shop; admin only. The shop is gone for good and cannot be restored. A shop that still has sellers or branches, deleted ones included, is not purged (409)
+// @Tags          shop
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "shop ID"
+// @Param         confirm query string true "the shop ID again"
+// @Success       200 {object} user_service.Empty4
+// @Failure       400 {object} models.ResponseError
+// @Failure       401 {object} models.ResponseError
+// @Failure       403 {object} models.ResponseError
+// @Failure       409 {object} models.ShopDependents
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) PurgeShop(c *gin.Context) {
+	id := c.Param("id")
+	if !h.confirmPurge(c, id) {
+		return
+	}
+
+	deps, err := h.shopDependents(grpc_client.WithoutCache(c.Request.Context()), id, true)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to list shop dependents")
+		return
+	}
+	if deps.SellerCount > 0 || deps.BranchCount > 0 {
+		writeShopDependents(c, deps, "purge them first")
+		return
+	}
+
+	resp, err := h.grpcClient.ShopService().Purge(c.Request.Context(), &user_service.ShopPrimaryKey{Id: id})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to purge shop")
+		return
+	}
+
+	h.logPurge(c, "shop", id)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router         /ExportShop [GET]
+// @Summary        Export shops
+// @Description    API for exporting shops as CSV or XLSX, streamed page by page
+// @Tags           shop
+// @Produce        text/csv,application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param		   format query string false "csv (default) or xlsx"
+// @Param		   columns query string false "comma separated fields, default: id, slug, name_uz, name_ru, name_en, phone, location, currency, payment_types, created_at"
+// @Param		   lang query string false "header language: uz, ru or en (default)"
+// @Param		   search query string false "search"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   currency query string false "filter by currency"
+// @Param		   async query boolean false "build the file in a background job, which needs an access token"
+// @Success 200    {file} file
+// @Success 202    {object} jobs.Job
+// @Failure 400    {object} models.ResponseError
+// @Failure 500    {object} models.ResponseError
+func (h *handler) ExportShop(c *gin.Context) {
+	params, ok := h.parseListParams(c, shopSortFields)
+	if !ok {
+		return
+	}
+	currency := c.Query("currency")
+
+	h.runExport(c, exportSource{
+		name:    "shops",
+		item:    (&user_service.Shop{}).ProtoReflect().Descriptor(),
+		columns: []string{"id", "slug", "name_uz", "name_ru", "name_en", "phone", "location", "currency", "payment_types", "created_at"},
+		fetch: func(ctx context.Context, page, limit uint64, cursor string) (exportPage, error) {
+			resp, err := h.grpcClient.ShopService().GetList(ctx, &user_service.GetListShopRequest{
+				Page:           page,
+				Limit:          limit,
+				Cursor:         cursor,
+				Search:         params.Search,
+				Sort:           params.Sort,
+				CreatedAtFrom:  params.CreatedAtFrom,
+				CreatedAtTo:    params.CreatedAtTo,
+				IncludeDeleted: params.IncludeDeleted,
+				OnlyDeleted:    params.OnlyDeleted,
+				Currency:       currency,
+			})
+			if err != nil {
+				return exportPage{}, err
+			}
+
+			result := exportPage{count: resp.Count, nextCursor: resp.NextCursor}
+			for _, item := range resp.Shops {
+				result.items = append(result.items, item)
+			}
+			return result, nil
+		},
+	})
 }
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"context"
+	"microservice/api/helpers"
+	"microservice/api/models"
+	"microservice/genproto/user_service"
+	"microservice/pkg/logger"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// @Security ApiKeyAuth
+// @Router         /v1/shops/{id}/full [GET]
+// @Summary        Get a shop with its sellers and branches
+// @Description    API for getting a shop, its sellers and its branches in one document. The three are fetched in parallel; a section whose call failed carries an error instead of data and partial is set. Sellers and branches are the first MAX_PAGE_SIZE of each, count tells how many there are
+// @Tags           shop
+// @Produce        json
+// @Param          id path string true "shop ID"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
+// @Success        200 {object} models.ShopDetail
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetShopFull(c *gin.Context) {
+	var (
+		id   = c.Param("id")
+		lang = c.Query("lang")
+		wg   sync.WaitGroup
+
+		shop     *user_service.Shop
+		sellers  *user_service.GetListSellerResponse
+		branches *user_service.GetListBranchResponse
+
+		shopErr, sellersErr, branchesErr error
+	)
+
+	if lang != "" {
+		if err := helpers.ValidateLanguage(lang); err != nil {
+			handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing lang")
+			return
+		}
+	}
+
+	ctx := readContext(c)
+	if h.cfg.ShopDetailTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.cfg.ShopDetailTimeout)
+		defer cancel()
+	}
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		shop, shopErr = h.grpcClient.ShopService().GetByID(ctx, &user_service.ShopPrimaryKey{Id: id})
+	}()
+	go func() {
+		defer wg.Done()
+		sellers, sellersErr = h.grpcClient.SellerService().GetList(ctx, &user_service.GetListSellerRequest{
+			Page:   1,
+			Limit:  h.cfg.MaxPageSize,
+			ShopId: id,
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		branches, branchesErr = h.grpcClient.BranchService().GetList(ctx, &user_service.GetListBranchRequest{
+			Page:   1,
+			Limit:  h.cfg.MaxPageSize,
+			ShopId: id,
+		})
+	}()
+	wg.Wait()
+
+	// Without the shop there is no page to render, and with nothing at all
+	// there is no document.
+	if status.Code(shopErr) == codes.NotFound || (shopErr != nil && sellersErr != nil && branchesErr != nil) {
+		handleGrpcErrWithDescription(c, h.log, shopErr, "failed to get shop")
+		return
+	}
+
+	if branchesErr == nil {
+		now := time.Now()
+		for _, branch := range branches.Branches {
+			h.decorateBranch(branch, now)
+		}
+	}
+
+	doc := models.ShopDetail{
+		ID:       id,
+		Shop:     h.shopDetailSection(shop, shopErr, lang, "failed to get shop"),
+		Sellers:  h.shopDetailSection(sellers, sellersErr, lang, "failed to get shop sellers"),
+		Branches: h.shopDetailSection(branches, branchesErr, lang, "failed to get shop branches"),
+	}
+	doc.Partial = doc.Shop.Error != nil || doc.Sellers.Error != nil || doc.Branches.Error != nil
+
+	c.JSON(http.StatusOK, doc)
+}
+
+func (h *handler) shopDetailSection(msg proto.Message, err error, lang, message string) models.ShopDetailSection {
+	if err == nil {
+		var data []byte
+		if data, err = h.renderer.Marshal(msg, nil, lang); err == nil {
+			return models.ShopDetailSection{Data: data}
+		}
+	}
+
+	h.log.Error(message, logger.Error(err))
+	return models.ShopDetailSection{Error: bulkError(err)}
+}
@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microservice/api/helpers"
+	"microservice/api/models"
+	"microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	slugSuggestions       = 5
+	slugSuggestionRetries = 20
+)
+
+// shopBySlug looks a shop up by slug. When the user service does not
+// implement GetBySlug it searches the first list page for an exact match.
+func (h *handler) shopBySlug(ctx context.Context, slug string) (*user_service.Shop, error) {
+	shop, err := h.grpcClient.ShopService().GetBySlug(ctx, &user_service.ShopSlug{Slug: slug})
+	if status.Code(err) != codes.Unimplemented {
+		return shop, err
+	}
+
+	list, err := h.grpcClient.ShopService().GetList(ctx, &user_service.GetListShopRequest{
+		Page:   1,
+		Limit:  h.cfg.MaxPageSize,
+		Search: slug,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, shop := range list.Shops {
+		if shop.Slug == slug {
+			return shop, nil
+		}
+	}
+
+	return nil, status.Errorf(codes.NotFound, "shop with slug %s not found", slug)
+}
+
+// slugAvailable reports whether no shop other than exceptID uses slug. It
+// skips the read cache so that recently created shops are seen.
+func (h *handler) slugAvailable(ctx context.Context, slug, exceptID string) (bool, error) {
+	shop, err := h.shopBySlug(grpc_client.WithoutCache(ctx), slug)
+	if status.Code(err) == codes.NotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return shop.Id == exceptID, nil
+}
+
+// suggestSlugs proposes available variants of slug: slug-2, slug-3 and so on.
+func (h *handler) suggestSlugs(ctx context.Context, slug, exceptID string) ([]string, error) {
+	suggestions := []string{}
+
+	for n := 2; len(suggestions) < slugSuggestions && n < slugSuggestionRetries+2; n++ {
+		candidate := numberedSlug(slug, n)
+		available, err := h.slugAvailable(ctx, candidate, exceptID)
+		if err != nil {
+			return nil, err
+		}
+		if available {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+
+	return suggestions, nil
+}
+
+// numberedSlug appends -n to slug, shortening slug to keep the result
+// within MaxSlugLength without a double hyphen.
+func numberedSlug(slug string, n int) string {
+	suffix := fmt.Sprintf("-%d", n)
+	if len(slug)+len(suffix) > helpers.MaxSlugLength {
+		slug = strings.TrimRight(slug[:helpers.MaxSlugLength-len(suffix)], "-")
+	}
+	return slug + suffix
+}
+
+// slugTakenError refuses a slug another shop uses. It is an Aborted status,
+// which GraphQL answers like the 409 of the REST routes, and carries the
+// free variants as the error's extensions.
+type slugTakenError struct {
+	slug        string
+	suggestions []string
+}
+
+func (e *slugTakenError) Error() string {
+	return fmt.Sprintf("slug %q is taken, try one of: %s", e.slug, strings.Join(e.suggestions, ", "))
+}
+
+func (e *slugTakenError) GRPCStatus() *status.Status {
+	return status.New(codes.Aborted, e.Error())
+}
+
+func (e *slugTakenError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":        http.StatusConflict,
+		"slug":        e.slug,
+		"suggestions": e.suggestions,
+	}
+}
+
+// validateShopSlug validates slug and makes sure no other shop than
+// exceptID uses it, returning a *slugTakenError with suggestions if one
+// does.
+func (h *handler) validateShopSlug(ctx context.Context, slug, exceptID string) error {
+	if err := helpers.ValidateSlug(slug); err != nil {
+		return invalidArgument(err)
+	}
+
+	available, err := h.slugAvailable(ctx, slug, exceptID)
+	if err != nil || available {
+		return err
+	}
+
+	suggestions, err := h.suggestSlugs(ctx, slug, exceptID)
+	if err != nil {
+		return err
+	}
+	return &slugTakenError{slug: slug, suggestions: suggestions}
+}
+
+// newShopSlug derives a free slug from name_en, or name_uz if it is empty,
+// returning a *slugTakenError when neither it nor any variant is free.
+func (h *handler) newShopSlug(ctx context.Context, req *user_service.CreateShop) (string, error) {
+	name := req.NameEn
+	if name == "" {
+		name = req.NameUz
+	}
+
+	slug := helpers.Slugify(name)
+	if slug == "" {
+		return "", invalidArgument(errors.New("slug is required when name_en and name_uz have no letters or digits"))
+	}
+
+	available, err := h.slugAvailable(ctx, slug, "")
+	if err != nil || available {
+		return slug, err
+	}
+
+	suggestions, err := h.suggestSlugs(ctx, slug, "")
+	if err != nil {
+		return "", err
+	}
+	if len(suggestions) == 0 {
+		return "", &slugTakenError{slug: slug, suggestions: suggestions}
+	}
+	return suggestions[0], nil
+}
+
+// checkShopSlug is validateShopSlug for REST handlers. On failure it writes
+// 400, or 409 with suggestions, and returns false.
+func (h *handler) checkShopSlug(c *gin.Context, slug, exceptID string) bool {
+	err := h.validateShopSlug(c.Request.Context(), slug, exceptID)
+	if err != nil {
+		h.handleSlugErr(c, err, "failed to check slug "+slug)
+	}
+	return err == nil
+}
+
+// generateShopSlug sets req.Slug with newShopSlug. On failure it writes the
+// error response and returns false.
+func (h *handler) generateShopSlug(c *gin.Context, req *user_service.CreateShop) bool {
+	slug, err := h.newShopSlug(c.Request.Context(), req)
+	if err != nil {
+		h.handleSlugErr(c, err, "failed to generate slug")
+		return false
+	}
+
+	req.Slug = slug
+	return true
+}
+
+func (h *handler) handleSlugErr(c *gin.Context, err error, message string) {
+	var taken *slugTakenError
+	if errors.As(err, &taken) {
+		c.JSON(http.StatusConflict, models.SlugAvailability{
+			Slug:        taken.slug,
+			Available:   false,
+			Suggestions: taken.suggestions,
+		})
+		return
+	}
+
+	handleGrpcErrWithDescription(c, h.log, err, message)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"microservice/api/helpers"
+	"strings"
+	"testing"
+)
+
+func TestNumberedSlug(t *testing.T) {
+	long := strings.Repeat("a", 61) + "-bc"
+	tests := []struct {
+		slug string
+		n    int
+		want string
+	}{
+		{"shop", 2, "shop-2"},
+		{strings.Repeat("a", 64), 3, strings.Repeat("a", 62) + "-3"},
+		{long, 2, strings.Repeat("a", 61) + "-2"},
+		{long, 12, strings.Repeat("a", 61) + "-12"},
+	}
+
+	for _, tt := range tests {
+		got := numberedSlug(tt.slug, tt.n)
+		if got != tt.want {
+			t.Errorf("numberedSlug(%q, %d) = %q, want %q", tt.slug, tt.n, got, tt.want)
+		}
+		if err := helpers.ValidateSlug(got); err != nil {
+			t.Errorf("numberedSlug(%q, %d) = %q: %v", tt.slug, tt.n, got, err)
+		}
+	}
+}
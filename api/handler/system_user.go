@@ -4,7 +4,6 @@ import (
 	"microservice/api/helpers"
 	"microservice/genproto/user_service"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,10 +13,11 @@ import (
 // @Summary       Create user
 // @Description   API for creating user
 // @Tags          user
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param order   body     user_service.CreateUs true "user"
 // @Success 200   {object} user_service.CreateUs
+// @Failure 400   {object} models.ResponseError
 // @Failure 404   {object} models.ResponseError
 // @Failure 500   {object} models.ResponseError
 func (h *handler) CreateUser(c *gin.Context) {
@@ -26,18 +26,18 @@ func (h *handler) CreateUser(c *gin.Context) {
 		resp *user_service.Us
 		err  error
 	)
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
 		return
 	}
 
 	if err := helpers.ValidateEmailAddress(req.Gmail); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "erroe while validating email"+req.Gmail)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating email "+req.Gmail)
+		return
 	}
 
 	System_user := h.grpcClient.SystemUserService()
@@ -51,7 +51,8 @@ func (h *handler) CreateUser(c *gin.Context) {
 		handleGrpcErrWithDescription(c, h.log, err, "failed to create customer")
 		return
 	}
-	c.JSON(http.StatusOK, resp)
+	setAuditResourceID(c, resp.Id)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -59,11 +60,20 @@ func (h *handler) CreateUser(c *gin.Context) {
 // @Summary        Get List user
 // @Description    API for getting list user
 // @Tags           user
-// @Accept         json
-// @Produce        json
-// @Param		   system_user query string false "system_users"
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
+// @Param		   search query string false "search"
 // @Param		   page query int false "page"
 // @Param		   limit query int false "limit"
+// @Param		   cursor query string false "cursor from next_cursor of the previous page"
+// @Param		   sort query string false "field:asc|desc, comma separated"
+// @Param		   created_at_from query string false "created at or after, 2006-01-02 15:04:05"
+// @Param		   created_at_to query string false "created before, 2006-01-02 15:04:05"
+// @Param		   include_deleted query boolean false "also list soft-deleted records; admin only"
+// @Param		   only_deleted query boolean false "list only soft-deleted records; admin only"
+// @Param		   role query string false "filter by role"
+// @Param		   fields query string false "comma separated fields to return"
+// @Param		   lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success 200    {object} user_service.GetListUsResponse
 // @Failure 404    {object} models.ResponseError
 // @Failure 500    {object} models.ResponseError
@@ -74,22 +84,22 @@ func (h *handler) GetListUser(c *gin.Context) {
 		err  error
 	)
 
-	req.Search = c.Query("search")
-
-	page, err := strconv.ParseUint(c.DefaultQuery("page", "1"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing page")
+	params, ok := h.parseListParams(c, userSortFields)
+	if !ok {
 		return
 	}
 
-	limit, err := strconv.ParseUint(c.DefaultQuery("limit", "10"), 10, 64)
-	if err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while parsing limit")
-		return
-	}
+	req.Page = params.Page
+	req.Limit = params.Limit
+	req.Search = params.Search
+	req.Cursor = params.Cursor
+	req.Sort = params.Sort
+	req.CreatedAtFrom = params.CreatedAtFrom
+	req.CreatedAtTo = params.CreatedAtTo
+	req.IncludeDeleted = params.IncludeDeleted
+	req.OnlyDeleted = params.OnlyDeleted
 
-	req.Page = page
-	req.Limit = limit
+	req.Role = c.Query("role")
 
 	System_user := h.grpcClient.SystemUserService()
 	if System_user == nil {
@@ -103,7 +113,8 @@ func (h *handler) GetListUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	setLinkHeader(c, params, resp.Count, resp.NextCursor)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -111,10 +122,14 @@ func (h *handler) GetListUser(c *gin.Context) {
 // @Summary        Get a single user by ID
 // @Description    API for getting a single user by ID
 // @Tags           user
-// @Accept         json
-// @Produce        json
+// @Accept         json,application/x-protobuf,application/msgpack
+// @Produce        json,application/x-protobuf,application/msgpack
 // @Param          id path string true "user ID"
+// @Param          If-None-Match header string false "etag of the cached copy"
+// @Param          fields query string false "comma separated fields to return"
+// @Param          lang query string false "uz, ru or en; collapses name_* and description_*"
 // @Success        200 {object} user_service.Us
+// @Success        304 "not modified"
 // @Failure        404 {object} models.ResponseError
 // @Failure        500 {object} models.ResponseError
 func (h *handler) GetUserByID(c *gin.Context) {
@@ -140,7 +155,11 @@ func (h *handler) GetUserByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	if WriteETag(c, resp.UpdatedAt) {
+		return
+	}
+
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -148,11 +167,14 @@ func (h *handler) GetUserByID(c *gin.Context) {
 // @Summary         Update a user by ID
 // @Description     API for updating a user by ID
 // @Tags            user
-// @Accept          json
-// @Produce         json
+// @Accept          json,application/x-protobuf,application/msgpack
+// @Produce         json,application/x-protobuf,application/msgpack
 // @Param           id path string true "user ID"
 // @Param           category body user_service.UpdateUs true "user"
+// @Param           If-Match header string false "etag the user must still have"
 // @Success         200 {object} user_service.Us
+// @Failure         400 {object} models.ResponseError
+// @Failure         412 {object} models.ResponseError
 // @Failure         404 {object} models.ResponseError
 // @Failure         500 {object} models.ResponseError
 func (h *handler) UpdateUser(c *gin.Context) {
@@ -161,20 +183,21 @@ func (h *handler) UpdateUser(c *gin.Context) {
 		req  user_service.UpdateUs
 		resp *user_service.Us
 		err  error
+		ok   bool
 	)
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "invalid request body")
+	if !h.bindRequest(c, &req) {
 		return
 	}
 
 	if err := helpers.ValidatePhone(req.Phone); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "error while validating phone number"+req.Phone)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating phone number "+req.Phone)
 		return
 	}
 
 	if err := helpers.ValidateEmailAddress(req.Gmail); err != nil {
-		handleGrpcErrWithDescription(c, h.log, err, "erroe while validating email"+req.Gmail)
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating email "+req.Gmail)
+		return
 	}
 
 	System_user := h.grpcClient.SystemUserService()
@@ -184,13 +207,19 @@ func (h *handler) UpdateUser(c *gin.Context) {
 	}
 
 	req.Id = id
+	req.ExpectedUpdatedAt, ok = ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	resp, err = System_user.Update(c.Request.Context(), &req)
 	if err != nil {
 		handleGrpcErrWithDescription(c, h.log, err, "internal server error")
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
 }
 
 // @Security ApiKeyAuth
@@ -198,10 +227,12 @@ func (h *handler) UpdateUser(c *gin.Context) {
 // @Summary       Delete a user by ID
 // @Description   API for deleting a user by ID
 // @Tags          user
-// @Accept        json
-// @Produce       json
+// @Accept        json,application/x-protobuf,application/msgpack
+// @Produce       json,application/x-protobuf,application/msgpack
 // @Param         id path string true "user ID"
+// @Param         If-Match header string false "etag the user must still have"
 // @Success       200 {object} user_service.Empty
+// @Failure       412 {object} models.ResponseError
 // @Failure       404 {object} models.ResponseError
 // @Failure       500 {object} models.ResponseError
 func (h *handler) DeleteUser(c *gin.Context) {
@@ -211,8 +242,14 @@ func (h *handler) DeleteUser(c *gin.Context) {
 		resp *user_service.Empty1
 	)
 
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
 	req := &user_service.UsPrimaryKey{
-		Id: id,
+		Id:                id,
+		ExpectedUpdatedAt: expectedUpdatedAt,
 	}
 
 	System_user := h.grpcClient.SystemUserService()
@@ -227,5 +264,66 @@ func (h *handler) DeleteUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, resp)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/users/{id}/restore [POST]
+// @Summary       Restore a deleted user
+// @Description   API for undoing the soft delete of a user
+// @Tags          user
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "user ID"
+// @Param         If-Match header string false "etag the deleted user must still have"
+// @Success       200 {object} user_service.Us
+// @Failure       412 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) RestoreUser(c *gin.Context) {
+	expectedUpdatedAt, ok := ParseIfMatchHeader(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.grpcClient.SystemUserService().Restore(c.Request.Context(), &user_service.UsPrimaryKey{
+		Id:                c.Param("id"),
+		ExpectedUpdatedAt: expectedUpdatedAt,
+	})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to restore user")
+		return
+	}
+
+	WriteETag(c, resp.UpdatedAt)
+	h.renderResponse(c, http.StatusOK, resp)
+}
+
+// @Security ApiKeyAuth
+// @Router        /v1/admin/users/{id} [DELETE]
+// @Summary       Purge a user
+// @Description   API for permanently deleting a user; admin only. The user is gone for good and cannot be restored
+// @Tags          user
+// @Produce       json,application/x-protobuf,application/msgpack
+// @Param         id path string true "user ID"
+// @Param         confirm query string true "the user ID again"
+// @Success       200 {object} user_service.Empty1
+// @Failure       400 {object} models.ResponseError
+// @Failure       401 {object} models.ResponseError
+// @Failure       403 {object} models.ResponseError
+// @Failure       404 {object} models.ResponseError
+// @Failure       500 {object} models.ResponseError
+func (h *handler) PurgeUser(c *gin.Context) {
+	id := c.Param("id")
+	if !h.confirmPurge(c, id) {
+		return
+	}
+
+	resp, err := h.grpcClient.SystemUserService().Purge(c.Request.Context(), &user_service.UsPrimaryKey{Id: id})
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, err, "failed to purge user")
+		return
+	}
+
+	h.logPurge(c, "user", id)
+	h.renderResponse(c, http.StatusOK, resp)
 }
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"fmt"
+	"microservice/pkg/transcode"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// transcodedPackage is the proto package whose annotated RPCs are exposed.
+const transcodedPackage = "user_service"
+
+// RegisterTranscodedRoutes adds a route for every user_service RPC with a
+// google.api.http annotation at the path the annotation gives. An RPC with a
+// hand-written handler is served by that handler, so its checks, such as
+// slug generation, If-Match and the dependents of a deleted shop, apply at
+// the annotated path too; the path parameters are named like the handler's.
+// Any other RPC is transcoded: its request is decoded from the body, path
+// and query as the annotation says and passed to the service as is, so
+// annotating an RPC is enough to expose it.
+func (h *handler) RegisterTranscodedRoutes(r gin.IRoutes) error {
+	routes, err := transcode.Routes(transcodedPackage)
+	if err != nil {
+		return err
+	}
+	return h.registerRoutes(r, routes, h.rpcHandlers())
+}
+
+// registerRoutes serves each route with the handler of its RPC in handlers,
+// or transcodes it.
+func (h *handler) registerRoutes(r gin.IRoutes, routes []transcode.Route, handlers map[protoreflect.FullName]gin.HandlerFunc) (err error) {
+	// gin panics on routes that conflict with those already registered.
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+	for _, route := range routes {
+		if handler, ok := handlers[route.RPC.FullName()]; ok {
+			r.Handle(route.Method, route.Path, handler)
+			continue
+		}
+		r.Handle(route.Method, route.Path, h.transcode(route))
+	}
+	return nil
+}
+
+// rpcHandlers returns the hand-written handlers by the RPC they call.
+func (h *handler) rpcHandlers() map[protoreflect.FullName]gin.HandlerFunc {
+	return map[protoreflect.FullName]gin.HandlerFunc{
+		"user_service.CustomerService.Create":  h.CreateCustomer,
+		"user_service.CustomerService.GetByID": h.GetCustomerByID,
+		"user_service.CustomerService.GetList": h.GetListCustomer,
+		"user_service.CustomerService.Update":  h.UpdateCustomer,
+		"user_service.CustomerService.Delete":  h.DeleteCustomer,
+
+		"user_service.UsService.Create":  h.CreateUser,
+		"user_service.UsService.GetByID": h.GetUserByID,
+		"user_service.UsService.GetList": h.GetListUser,
+		"user_service.UsService.Update":  h.UpdateUser,
+		"user_service.UsService.Delete":  h.DeleteUser,
+
+		"user_service.SellerService.Create":  h.CreateSeller,
+		"user_service.SellerService.GetByID": h.GetSellerByID,
+		"user_service.SellerService.GetList": h.GetListSeller,
+		"user_service.SellerService.Update":  h.UpdateSeller,
+		"user_service.SellerService.Delete":  h.DeleteSeller,
+
+		"user_service.BranchService.Create":  h.CreateBranch,
+		"user_service.BranchService.GetByID": h.GetBranchByID,
+		"user_service.BranchService.GetList": h.GetListBranch,
+		"user_service.BranchService.Update":  h.UpdateBranch,
+		"user_service.BranchService.Delete":  h.DeleteBranch,
+
+		"user_service.ShopService.Create":    h.CreateShop,
+		"user_service.ShopService.GetByID":   h.GetShopByID,
+		"user_service.ShopService.GetBySlug": h.GetShopBySlug,
+		"user_service.ShopService.GetList":   h.GetListShop,
+		"user_service.ShopService.Update":    h.UpdateShop,
+		"user_service.ShopService.Delete":    h.DeleteShop,
+	}
+}
+
+func (h *handler) transcode(route transcode.Route) gin.HandlerFunc {
+	method := string(route.RPC.FullName())
+
+	return func(c *gin.Context) {
+		req, err := route.NewRequest()
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "failed to create the request of "+method)
+			return
+		}
+
+		if body := route.Body(req); body != nil && !h.bindRequest(c, body) {
+			return
+		}
+		if err := route.Decode(req, c.Param, c.Request.URL.Query()); err != nil {
+			handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while decoding the request of "+method)
+			return
+		}
+
+		ctx := c.Request.Context()
+		if c.Request.Method == http.MethodGet {
+			ctx = readContext(c)
+		}
+
+		resp, err := h.grpcClient.Invoke(ctx, route.RPC, req)
+		if err != nil {
+			handleGrpcErrWithDescription(c, h.log, err, "failed to call "+method)
+			return
+		}
+		h.renderResponse(c, http.StatusOK, route.Response(resp))
+	}
+}
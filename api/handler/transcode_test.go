@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"microservice/api/helpers"
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
+	"microservice/pkg/logger"
+	"microservice/pkg/transcode"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// shopServer keeps one shop and counts the reads that reach it.
+type shopServer struct {
+	pb.UnimplementedShopServiceServer
+
+	mu    sync.Mutex
+	shop  *pb.Shop
+	reads int
+}
+
+func (s *shopServer) GetByID(ctx context.Context, in *pb.ShopPrimaryKey) (*pb.Shop, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reads++
+	if in.Id != s.shop.Id {
+		return nil, status.Error(codes.NotFound, "shop not found")
+	}
+	return s.shop, nil
+}
+
+func (s *shopServer) Update(ctx context.Context, in *pb.UpdateShop) (*pb.Shop, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.shop = &pb.Shop{Id: in.Id, Slug: in.Slug, NameEn: in.NameEn}
+	return s.shop, nil
+}
+
+func TestTranscodeWithoutHandler(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &shopServer{shop: &pb.Shop{Id: "1", Slug: "tea", NameEn: "Tea"}}
+	server := grpc.NewServer()
+	pb.RegisterShopServiceServer(server, srv)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	cfg := config.Config{UserServiceHost: "127.0.0.1", UserServicePort: port, CacheMaxEntries: 10, CacheShopTTL: time.Minute, MaxPageSize: 100}
+	client, err := grpc_client.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := &handler{log: logger.New(logger.LevelPanic, "test"), grpcClient: client, cfg: cfg, renderer: helpers.NewJSONRenderer(helpers.JSONOptions{UseProtoNames: true})}
+
+	// Without hand-written handlers every annotated RPC is still served.
+	routes, err := transcode.Routes(transcodedPackage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	if err := h.registerRoutes(r, routes, nil); err != nil {
+		t.Fatalf("registering annotated RPCs without handlers: %v", err)
+	}
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	if w := do("GET", "/v1/shops/1", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name_en":"Tea"`) {
+		t.Fatalf("GET /v1/shops/1 = %d %s", w.Code, w.Body)
+	}
+	if w := do("PUT", "/v1/shops/1", `{"slug":"tea","name_en":"Green Tea"}`); w.Code != http.StatusOK {
+		t.Fatalf("PUT /v1/shops/1 = %d %s", w.Code, w.Body)
+	}
+
+	// The transcoded write dropped the cached read.
+	if w := do("GET", "/v1/shops/1", ""); !strings.Contains(w.Body.String(), `"name_en":"Green Tea"`) || srv.reads != 2 {
+		t.Errorf("GET after PUT = %d %s after %d reads, want the updated shop read again", w.Code, w.Body, srv.reads)
+	}
+	if w := do("GET", "/v1/shops/9", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET /v1/shops/9 = %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"microservice/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Deleting a record only sets its deleted_at; Restore clears it again and
+// Purge removes the record for good. Purging is for admins and has to be
+// confirmed by repeating the ID in the confirm query parameter.
+
+// confirmPurge checks that the caller is an admin who confirmed purging id.
+// On failure it writes the response and returns false.
+func (h *handler) confirmPurge(c *gin.Context, id string) bool {
+	if !requireRole(c, adminRole) {
+		return false
+	}
+
+	if c.Query("confirm") != id {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(errors.New("confirm must repeat the ID to purge")), "error while confirming purge")
+		return false
+	}
+	return true
+}
+
+func (h *handler) logPurge(c *gin.Context, resource, id string) {
+	h.log.Warn("record purged", logger.String("resource", resource), logger.String("id", id), logger.String("by", principal(c)))
+}
@@ -0,0 +1,334 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"microservice/api/models"
+	"microservice/pkg/logger"
+	"microservice/pkg/webhook"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func validateWebhookRequest(req *models.WebhookRequest) error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+
+	if len(req.Events) == 0 {
+		return errors.New("events is required")
+	}
+	for _, event := range req.Events {
+		if err := validateEventPattern(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *handler) handleWebhookErr(c *gin.Context, err error, message string) {
+	code := http.StatusInternalServerError
+	if errors.Is(err, webhook.ErrNotFound) {
+		code = http.StatusNotFound
+	}
+
+	description := err.Error()
+	if code == http.StatusInternalServerError {
+		h.log.Error(message, logger.Error(err))
+		description = "Internal Server Error"
+	}
+
+	c.JSON(code, models.ErrorWithDescription{
+		Code:        code,
+		Description: description,
+	})
+}
+
+// bindWebhookRequest reads and validates the body of create and update.
+func (h *handler) bindWebhookRequest(c *gin.Context) (models.WebhookRequest, bool) {
+	var req models.WebhookRequest
+
+	if err := json.NewDecoder(c.Request.Body).Decode(&req); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while decoding webhook")
+		return req, false
+	}
+	if err := validateWebhookRequest(&req); err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while validating webhook")
+		return req, false
+	}
+	return req, true
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhooks [POST]
+// @Summary        Register a webhook
+// @Description    API for registering an endpoint for change events (<resource>.<action> with resource customer, user, seller, branch or shop and action created, updated, deleted, restored or purged; <resource>.* or * for all). Deliveries are POSTed as JSON with X-Webhook-ID, X-Webhook-Event, X-Webhook-Timestamp and X-Webhook-Signature: sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" with the secret>. The secret is generated unless given and is only returned here; admin only
+// @Tags           webhook
+// @Accept         json
+// @Produce        json
+// @Param          webhook body models.WebhookRequest true "endpoint"
+// @Success        201 {object} webhook.Endpoint
+// @Failure        400 {object} models.ResponseError
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) CreateWebhook(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	req, ok := h.bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	endpoint, err := webhook.NewEndpoint(req.URL, req.Events, req.Secret)
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to create webhook")
+		return
+	}
+	endpoint.Description = req.Description
+	if req.Active != nil {
+		endpoint.Active = *req.Active
+	}
+
+	if err = h.webhooks.Store().CreateEndpoint(c.Request.Context(), endpoint); err != nil {
+		h.handleWebhookErr(c, err, "failed to create webhook")
+		return
+	}
+
+	setAuditResourceID(c, endpoint.ID)
+	c.Header("Location", "/admin/webhooks/"+endpoint.ID)
+	c.JSON(http.StatusCreated, endpoint)
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhooks [GET]
+// @Summary        List webhooks
+// @Description    API for listing the registered webhook endpoints, without their secrets; admin only
+// @Tags           webhook
+// @Produce        json
+// @Success        200 {object} models.WebhookList
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) ListWebhooks(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	endpoints, err := h.webhooks.Store().ListEndpoints(c.Request.Context())
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to list webhooks")
+		return
+	}
+	for i := range endpoints {
+		endpoints[i].Secret = ""
+	}
+
+	c.JSON(http.StatusOK, models.WebhookList{Endpoints: endpoints})
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhooks/{id} [GET]
+// @Summary        Get a webhook
+// @Description    API for reading a webhook endpoint, without its secret; admin only
+// @Tags           webhook
+// @Produce        json
+// @Param          id path string true "webhook ID"
+// @Success        200 {object} webhook.Endpoint
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetWebhook(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	endpoint, err := h.webhooks.Store().GetEndpoint(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to get webhook")
+		return
+	}
+	endpoint.Secret = ""
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhooks/{id} [PUT]
+// @Summary        Update a webhook
+// @Description    API for replacing the url, events, description and active flag of a webhook endpoint; the secret is only changed when one is given. Admin only
+// @Tags           webhook
+// @Accept         json
+// @Produce        json
+// @Param          id path string true "webhook ID"
+// @Param          webhook body models.WebhookRequest true "endpoint"
+// @Success        200 {object} webhook.Endpoint
+// @Failure        400 {object} models.ResponseError
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) UpdateWebhook(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	req, ok := h.bindWebhookRequest(c)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.webhooks.Store().GetEndpoint(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to get webhook")
+		return
+	}
+
+	endpoint.URL = req.URL
+	endpoint.Events = req.Events
+	endpoint.Description = req.Description
+	endpoint.Active = req.Active == nil || *req.Active
+	if req.Secret != "" {
+		endpoint.Secret = req.Secret
+	}
+	endpoint.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+
+	if err := h.webhooks.Store().UpdateEndpoint(c.Request.Context(), endpoint); err != nil {
+		h.handleWebhookErr(c, err, "failed to update webhook")
+		return
+	}
+	endpoint.Secret = ""
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhooks/{id} [DELETE]
+// @Summary        Delete a webhook
+// @Description    API for deleting a webhook endpoint and its deliveries; admin only
+// @Tags           webhook
+// @Produce        json
+// @Param          id path string true "webhook ID"
+// @Success        204
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) DeleteWebhook(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	if err := h.webhooks.Store().DeleteEndpoint(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleWebhookErr(c, err, "failed to delete webhook")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhooks/{id}/deliveries [GET]
+// @Summary        List webhook deliveries
+// @Description    API for listing the deliveries of a webhook endpoint with every attempt, newest first; admin only
+// @Tags           webhook
+// @Produce        json
+// @Param          id path string true "webhook ID"
+// @Param          page query int false "page"
+// @Param          limit query int false "limit"
+// @Success        200 {object} models.WebhookDeliveries
+// @Failure        400 {object} models.ResponseError
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) ListWebhookDeliveries(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	page, err := ParsePageQueryParam(c)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing page")
+		return
+	}
+	limit, err := ParseLimitQueryParam(c, h.cfg.DefaultPageSize, h.cfg.MaxPageSize)
+	if err != nil {
+		handleGrpcErrWithDescription(c, h.log, invalidArgument(err), "error while parsing limit")
+		return
+	}
+
+	id := c.Param("id")
+	if _, err := h.webhooks.Store().GetEndpoint(c.Request.Context(), id); err != nil {
+		h.handleWebhookErr(c, err, "failed to get webhook")
+		return
+	}
+
+	deliveries, err := h.webhooks.Store().ListDeliveries(c.Request.Context(), id, int(limit), int((page-1)*limit))
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to list webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.WebhookDeliveries{Deliveries: deliveries})
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhook-deliveries/{id} [GET]
+// @Summary        Get a webhook delivery
+// @Description    API for reading a delivery with its event and attempts; admin only
+// @Tags           webhook
+// @Produce        json
+// @Param          id path string true "delivery ID"
+// @Success        200 {object} webhook.Delivery
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) GetWebhookDelivery(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	delivery, err := h.webhooks.Store().GetDelivery(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to get webhook delivery")
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// @Security ApiKeyAuth
+// @Router         /admin/webhook-deliveries/{id}/redeliver [POST]
+// @Summary        Redeliver a webhook event
+// @Description    API for sending the event of a delivery again; the new delivery is returned and sent in the background. Admin only
+// @Tags           webhook
+// @Produce        json
+// @Param          id path string true "delivery ID"
+// @Success        202 {object} webhook.Delivery
+// @Failure        401 {object} models.ResponseError
+// @Failure        403 {object} models.ResponseError
+// @Failure        404 {object} models.ResponseError
+// @Failure        500 {object} models.ResponseError
+func (h *handler) RedeliverWebhook(c *gin.Context) {
+	if !requireRole(c, adminRole) {
+		return
+	}
+
+	delivery, err := h.webhooks.Redeliver(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleWebhookErr(c, err, "failed to redeliver webhook")
+		return
+	}
+
+	c.Header("Location", "/admin/webhook-deliveries/"+delivery.ID)
+	c.JSON(http.StatusAccepted, delivery)
+}
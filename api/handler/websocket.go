@@ -0,0 +1,319 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"microservice/pkg/events"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsWriteWait      = 10 * time.Second
+	wsMaxMessageSize = 4 << 10
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	// Connections authenticate with a token rather than cookies, so pages on
+	// any origin may connect, as with the CORS settings of the HTTP routes.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsRequest is a message from the client: subscribe, unsubscribe or ping.
+type wsRequest struct {
+	Type       string   `json:"type"`
+	ID         string   `json:"id"`
+	Events     []string `json:"events"`
+	ResourceID string   `json:"resource_id"`
+	ShopID     string   `json:"shop_id"`
+
+	// err is set when the message is not valid JSON.
+	err error
+}
+
+// wsMessage is a message to the client: subscribed, unsubscribed, event,
+// error or pong. Events list the IDs of the subscriptions they match.
+type wsMessage struct {
+	Type          string        `json:"type"`
+	ID            string        `json:"id,omitempty"`
+	Subscriptions []string      `json:"subscriptions,omitempty"`
+	Event         *events.Event `json:"event,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// wsSubscription selects events by type and, optionally, by the resource
+// or by the shop they belong to.
+type wsSubscription struct {
+	events     []string
+	resourceID string
+	shopID     string
+}
+
+func (s wsSubscription) matches(ev events.Event) bool {
+	if !events.Match(s.events, ev.Type) {
+		return false
+	}
+	if s.resourceID != "" && ev.ResourceID != s.resourceID {
+		return false
+	}
+	return s.shopID == "" || eventShopID(ev) == s.shopID
+}
+
+// eventShopID returns the shop an event is about: the shop itself or the
+// shop of a seller or branch.
+func eventShopID(ev events.Event) string {
+	if ev.ResourceType == "shop" {
+		return ev.ResourceID
+	}
+
+	var object struct {
+		ShopID string `json:"shop_id"`
+	}
+	json.Unmarshal(ev.Object, &object)
+	return object.ShopID
+}
+
+// connLimiter counts open connections, in total and per principal.
+type connLimiter struct {
+	mu    sync.Mutex
+	total int
+	per   map[string]int
+}
+
+func newConnLimiter() *connLimiter {
+	return &connLimiter{per: make(map[string]int)}
+}
+
+// acquire takes a connection for key unless max connections, or maxPer
+// for key, are open; non-positive limits are unlimited. On failure it
+// returns the status to answer with.
+func (l *connLimiter) acquire(key string, max, maxPer int) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if maxPer > 0 && l.per[key] >= maxPer {
+		return http.StatusTooManyRequests, fmt.Errorf("at most %d connections per user may be open", maxPer)
+	}
+	if max > 0 && l.total >= max {
+		return http.StatusServiceUnavailable, errors.New("too many open connections")
+	}
+
+	l.total++
+	l.per[key]++
+	return 0, nil
+}
+
+func (l *connLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.total--
+	if l.per[key]--; l.per[key] <= 0 {
+		delete(l.per, key)
+	}
+}
+
+// @Security ApiKeyAuth
+// @Router         /v1/events/ws [GET]
+// @Summary        Subscribe to entity changes over WebSocket
+// @Description    WebSocket carrying the events of /v1/events for the subscriptions the client makes at runtime. Client messages: {"type":"subscribe","id":"s1","events":["seller.*"],"shop_id":"42"} (events as in /v1/events, all when empty; resource_id selects one resource, shop_id a shop and its sellers and branches), {"type":"unsubscribe","id":"s1"} and {"type":"ping"}. Server messages: subscribed, unsubscribed, pong, error (with id and error) and {"type":"event","subscriptions":["s1"],"event":{...}}. Admins may subscribe to every resource, other callers to shops, sellers and branches. A client that falls behind is disconnected with close code 1013 and should reconnect and reload. The token may be passed as ?access_token=
+// @Tags           event
+// @Param          access_token query string false "access token, if the Authorization header is not set"
+// @Success        101
+// @Failure        401 {object} models.ResponseError
+// @Failure        429 {object} models.ResponseError
+// @Failure        503 {object} models.ResponseError
+func (h *handler) EventsWebSocket(c *gin.Context) {
+	claims, ok := streamClaims(c)
+	if !ok {
+		return
+	}
+
+	if h.events == nil {
+		abortWithError(c, http.StatusServiceUnavailable, "the event stream is disabled")
+		return
+	}
+
+	key := "user:" + claims.Subject
+	if code, err := h.wsConns.acquire(key, h.cfg.WSMaxConnections, h.cfg.WSMaxConnectionsPerUser); err != nil {
+		abortWithError(c, code, err.Error())
+		return
+	}
+	defer h.wsConns.release(key)
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade has answered the request.
+		return
+	}
+	defer conn.Close()
+
+	s := &wsSession{h: h, conn: conn, claims: claims, subs: make(map[string]wsSubscription)}
+	s.run()
+}
+
+// wsSession serves one connection. Only run writes to the connection.
+type wsSession struct {
+	h      *handler
+	conn   *websocket.Conn
+	claims tokenClaims
+	subs   map[string]wsSubscription
+}
+
+func (s *wsSession) run() {
+	interval := s.h.cfg.EventHeartbeat
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+
+	sub, _, _ := s.h.events.Subscribe(0, s.h.cfg.EventClientBuffer)
+	defer sub.Close()
+
+	// The reader hands requests over one at a time, so a client that sends
+	// faster than it is answered is slowed down by TCP.
+	requests := make(chan wsRequest)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		defer close(done)
+
+		s.conn.SetReadLimit(wsMaxMessageSize)
+		s.conn.SetReadDeadline(time.Now().Add(2 * interval))
+		s.conn.SetPongHandler(func(string) error {
+			return s.conn.SetReadDeadline(time.Now().Add(2 * interval))
+		})
+		for {
+			_, data, err := s.conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var req wsRequest
+			if err := json.Unmarshal(data, &req); err != nil {
+				req.err = err
+			}
+			select {
+			case requests <- req:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(interval)
+	defer ping.Stop()
+
+	var expired <-chan time.Time
+	if s.claims.ExpiresAt != 0 {
+		timer := time.NewTimer(time.Until(time.Unix(s.claims.ExpiresAt, 0)))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	for {
+		var err error
+		select {
+		case req := <-requests:
+			err = s.write(s.handle(req))
+		case ev, ok := <-sub.C():
+			if !ok {
+				s.close(websocket.CloseTryAgainLater, "too slow, reconnect")
+				return
+			}
+			err = s.event(ev)
+		case <-ping.C:
+			err = s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait))
+		case <-expired:
+			s.close(websocket.ClosePolicyViolation, "token expired")
+			return
+		case <-done:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (s *wsSession) handle(req wsRequest) wsMessage {
+	if req.err != nil {
+		return wsMessage{Type: "error", Error: "invalid message: " + req.err.Error()}
+	}
+
+	switch req.Type {
+	case "ping":
+		return wsMessage{Type: "pong", ID: req.ID}
+	case "unsubscribe":
+		if _, ok := s.subs[req.ID]; !ok {
+			return wsMessage{Type: "error", ID: req.ID, Error: "unknown subscription"}
+		}
+		delete(s.subs, req.ID)
+		return wsMessage{Type: "unsubscribed", ID: req.ID}
+	case "subscribe":
+		if err := s.subscribe(req); err != nil {
+			return wsMessage{Type: "error", ID: req.ID, Error: err.Error()}
+		}
+		return wsMessage{Type: "subscribed", ID: req.ID}
+	}
+	return wsMessage{Type: "error", ID: req.ID, Error: fmt.Sprintf("unknown message type %q", req.Type)}
+}
+
+func (s *wsSession) subscribe(req wsRequest) error {
+	if req.ID == "" {
+		return errors.New("id is required")
+	}
+	if _, ok := s.subs[req.ID]; !ok && s.h.cfg.WSMaxSubscriptions > 0 && len(s.subs) >= s.h.cfg.WSMaxSubscriptions {
+		return fmt.Errorf("at most %d subscriptions are allowed", s.h.cfg.WSMaxSubscriptions)
+	}
+
+	for _, pattern := range req.Events {
+		if err := validateEventPattern(pattern); err != nil {
+			return err
+		}
+		resource, _, _ := strings.Cut(pattern, ".")
+		if pattern != "*" && !canSeeEvent(s.claims.Role, resource) {
+			return fmt.Errorf("only %s can subscribe to %s events", adminRole, resource)
+		}
+	}
+
+	s.subs[req.ID] = wsSubscription{events: req.Events, resourceID: req.ResourceID, shopID: req.ShopID}
+	return nil
+}
+
+// event sends ev if it matches a subscription.
+func (s *wsSession) event(ev events.Event) error {
+	if !canSeeEvent(s.claims.Role, ev.ResourceType) {
+		return nil
+	}
+
+	var matched []string
+	for id, sub := range s.subs {
+		if sub.matches(ev) {
+			matched = append(matched, id)
+		}
+	}
+	if len(matched) == 0 {
+		return nil
+	}
+	sort.Strings(matched)
+
+	return s.write(wsMessage{Type: "event", Subscriptions: matched, Event: &ev})
+}
+
+func (s *wsSession) write(msg wsMessage) error {
+	s.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+	return s.conn.WriteJSON(msg)
+}
+
+func (s *wsSession) close(code int, reason string) {
+	s.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(wsWriteWait))
+}
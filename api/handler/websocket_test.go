@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"microservice/pkg/events"
+)
+
+func TestConnLimiter(t *testing.T) {
+	l := newConnLimiter()
+
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{"a", 0},
+		{"a", 0},
+		{"a", http.StatusTooManyRequests},
+		{"b", 0},
+		{"c", http.StatusServiceUnavailable},
+	}
+	for i, tt := range tests {
+		if code, _ := l.acquire(tt.key, 3, 2); code != tt.want {
+			t.Errorf("acquire #%d for %s = %d, want %d", i, tt.key, code, tt.want)
+		}
+	}
+
+	l.release("a")
+	if code, err := l.acquire("c", 3, 2); err != nil {
+		t.Errorf("acquire after release = %d %v, want a connection", code, err)
+	}
+	l.release("b")
+	if _, ok := l.per["b"]; ok || l.total != 2 {
+		t.Errorf("after releasing b: total %d, per %v", l.total, l.per)
+	}
+	if code, err := l.acquire("d", 0, 0); err != nil {
+		t.Errorf("acquire without limits = %d %v", code, err)
+	}
+}
+
+func TestWSSubscriptionMatches(t *testing.T) {
+	seller := events.Event{Type: "seller.updated", ResourceType: "seller", ResourceID: "7", Object: json.RawMessage(`{"id":"7","shop_id":"1"}`)}
+	shop := events.Event{Type: "shop.deleted", ResourceType: "shop", ResourceID: "1"}
+
+	tests := []struct {
+		sub  wsSubscription
+		ev   events.Event
+		want bool
+	}{
+		{wsSubscription{}, seller, true},
+		{wsSubscription{events: []string{"seller.*"}}, seller, true},
+		{wsSubscription{events: []string{"seller.created"}}, seller, false},
+		{wsSubscription{resourceID: "7"}, seller, true},
+		{wsSubscription{resourceID: "8"}, seller, false},
+		{wsSubscription{shopID: "1"}, seller, true},
+		{wsSubscription{shopID: "2"}, seller, false},
+		{wsSubscription{shopID: "1"}, shop, true},
+		{wsSubscription{events: []string{"shop.*"}, shopID: "1"}, seller, false},
+		{wsSubscription{shopID: "1"}, events.Event{Type: "seller.deleted", ResourceType: "seller", ResourceID: "7"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sub.matches(tt.ev); got != tt.want {
+			t.Errorf("%+v matches %s %s = %v, want %v", tt.sub, tt.ev.Type, tt.ev.Object, got, tt.want)
+		}
+	}
+}
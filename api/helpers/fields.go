@@ -0,0 +1,154 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Languages lists the suffixes of localized fields such as name_uz.
+var Languages = []string{"uz", "ru", "en"}
+
+// localizedFields are the base names of fields that exist once per language.
+var localizedFields = []string{"name", "description"}
+
+// ParseFields splits a fields= query value for msg and, when lang is set,
+// expands "name"/"description" to their per-language columns so that the
+// language fallback still has data to work with. Only messages that have the
+// per-language fields (shops) are expanded; for the others, such as sellers,
+// name is a field of its own.
+func ParseFields(msg proto.Message, fields, lang string) []string {
+	if fields == "" {
+		return nil
+	}
+
+	md := msg.ProtoReflect().Descriptor()
+	if list := listField(md); list != nil {
+		md = list.Message()
+	}
+
+	var result []string
+	for _, field := range strings.Split(fields, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		if lang != "" && isLocalized(md, field) {
+			for _, l := range Languages {
+				result = append(result, field+"_"+l)
+			}
+			continue
+		}
+
+		result = append(result, field)
+	}
+
+	return result
+}
+
+// PruneFields clears every field of msg that is not listed in fields. For
+// list responses, which wrap a single repeated message field, the fields are
+// applied to the list items and the remaining top-level fields (count,
+// next_cursor) are kept.
+func PruneFields(msg proto.Message, fields []string) error {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	m := msg.ProtoReflect()
+
+	list := listField(m.Descriptor())
+	if list == nil {
+		if err := checkFields(m.Descriptor(), fields); err != nil {
+			return err
+		}
+		pruneMessage(m, fields)
+		return nil
+	}
+
+	if err := checkFields(list.Message(), fields); err != nil {
+		return err
+	}
+
+	items := m.Get(list).List()
+	for i := 0; i < items.Len(); i++ {
+		pruneMessage(items.Get(i).Message(), fields)
+	}
+
+	return nil
+}
+
+// ValidateLanguage ...
+func ValidateLanguage(lang string) error {
+	for _, l := range Languages {
+		if l == lang {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("lang %s is not supported, use one of: %s", lang, strings.Join(Languages, ", "))
+}
+
+// isLocalized reports whether md stores field once per language instead of
+// as a field of its own.
+func isLocalized(md protoreflect.MessageDescriptor, field string) bool {
+	if md.Fields().ByName(protoreflect.Name(field)) != nil {
+		return false
+	}
+	for _, base := range localizedFields {
+		if field != base {
+			continue
+		}
+		for _, l := range Languages {
+			if md.Fields().ByName(protoreflect.Name(field+"_"+l)) == nil {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// listField returns the repeated message field of a list response. Entities,
+// which have an id, are never list responses even if they hold nested lists
+// such as a branch's schedule.
+func listField(md protoreflect.MessageDescriptor) protoreflect.FieldDescriptor {
+	if md.Fields().ByName("id") != nil {
+		return nil
+	}
+
+	fds := md.Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		if fd.IsList() && fd.Kind() == protoreflect.MessageKind {
+			return fd
+		}
+	}
+	return nil
+}
+
+func checkFields(md protoreflect.MessageDescriptor, fields []string) error {
+	for _, field := range fields {
+		if md.Fields().ByName(protoreflect.Name(field)) == nil {
+			return fmt.Errorf("unknown field %s", field)
+		}
+	}
+	return nil
+}
+
+func pruneMessage(m protoreflect.Message, fields []string) {
+	keep := make(map[protoreflect.Name]bool, len(fields))
+	for _, field := range fields {
+		keep[protoreflect.Name(field)] = true
+	}
+
+	fds := m.Descriptor().Fields()
+	for i := 0; i < fds.Len(); i++ {
+		if fd := fds.Get(i); !keep[fd.Name()] {
+			m.Clear(fd)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package helpers
+
+import (
+	"reflect"
+	"testing"
+
+	"microservice/genproto/user_service"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    proto.Message
+		fields string
+		lang   string
+		want   []string
+	}{
+		{"empty", &user_service.Shop{}, "", "en", nil},
+		{"no lang", &user_service.Shop{}, "id, slug,", "", []string{"id", "slug"}},
+		{"shop name with lang", &user_service.Shop{}, "id,name", "en", []string{"id", "name_uz", "name_ru", "name_en"}},
+		{"shop list name with lang", &user_service.GetListShopResponse{}, "name", "ru", []string{"name_uz", "name_ru", "name_en"}},
+		{"seller name with lang", &user_service.Seller{}, "name", "en", []string{"name"}},
+		{"branch name with lang", &user_service.GetListBranchResponse{}, "id,name", "uz", []string{"id", "name"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseFields(tt.msg, tt.fields, tt.lang); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFields(%q, %q) = %v, want %v", tt.fields, tt.lang, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneFields(t *testing.T) {
+	seller := &user_service.Seller{Id: "1", Name: "Ann", Phone: "+998901234567", ShopId: "2"}
+	if err := PruneFields(seller, ParseFields(seller, "name", "en")); err != nil {
+		t.Fatalf("PruneFields(seller, name) with lang: %v", err)
+	}
+	if seller.Name != "Ann" || seller.Id != "" || seller.Phone != "" {
+		t.Errorf("seller = %v, want only name", seller)
+	}
+
+	list := &user_service.GetListShopResponse{Count: 1, Shops: []*user_service.Shop{{Id: "1", Slug: "a", NameEn: "A"}}}
+	if err := PruneFields(list, ParseFields(list, "name", "en")); err != nil {
+		t.Fatalf("PruneFields(shops, name): %v", err)
+	}
+	if shop := list.Shops[0]; shop.NameEn != "A" || shop.Slug != "" || list.Count != 1 {
+		t.Errorf("list = %v, want shop names and count only", list)
+	}
+
+	if err := PruneFields(&user_service.Shop{}, []string{"nope"}); err == nil {
+		t.Error("PruneFields with an unknown field: want an error")
+	}
+}
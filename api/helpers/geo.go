@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// ValidateCoordinates checks that lat/lng are a point on the Earth.
+func ValidateCoordinates(lat, lng float64) error {
+	if math.IsNaN(lat) || lat < -90 || lat > 90 {
+		return fmt.Errorf("latitude %v is invalid, expected -90 to 90", lat)
+	}
+	if math.IsNaN(lng) || lng < -180 || lng > 180 {
+		return fmt.Errorf("longitude %v is invalid, expected -180 to 180", lng)
+	}
+	return nil
+}
+
+// ParseLatLng reads a "lat,lng" string, the form most legacy branch
+// locations were entered in.
+func ParseLatLng(value string) (float64, float64, bool) {
+	latStr, lngStr, ok := strings.Cut(value, ",")
+	if !ok {
+		return 0, 0, false
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(lngStr), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+
+	if ValidateCoordinates(lat, lng) != nil {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// Haversine returns the great-circle distance in meters between two points.
+func Haversine(lat1, lng1, lat2, lng2 float64) float64 {
+	const rad = math.Pi / 180
+
+	dLat := (lat2 - lat1) * rad
+	dLng := (lng2 - lng1) * rad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// LatitudeSpan returns how many degrees of latitude a distance in meters
+// covers. Points farther apart in latitude are farther apart than distance.
+func LatitudeSpan(distance float64) float64 {
+	return distance / EarthRadius * 180 / math.Pi
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestValidateCoordinates(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		ok       bool
+	}{
+		{41.3111, 69.2797, true},
+		{-90, 180, true},
+		{90.5, 0, false},
+		{0, -180.1, false},
+		{math.NaN(), 0, false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateCoordinates(tt.lat, tt.lng); (err == nil) != tt.ok {
+			t.Errorf("ValidateCoordinates(%v, %v) = %v, want ok %v", tt.lat, tt.lng, err, tt.ok)
+		}
+	}
+}
+
+func TestParseLatLng(t *testing.T) {
+	tests := []struct {
+		value    string
+		lat, lng float64
+		ok       bool
+	}{
+		{"41.3111,69.2797", 41.3111, 69.2797, true},
+		{" 41.3 , 69.2 ", 41.3, 69.2, true},
+		{"Tashkent", 0, 0, false},
+		{"41.3;69.2", 0, 0, false},
+		{"91,69", 0, 0, false},
+		{"41.3,east", 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		lat, lng, ok := ParseLatLng(tt.value)
+		if lat != tt.lat || lng != tt.lng || ok != tt.ok {
+			t.Errorf("ParseLatLng(%q) = %v, %v, %v, want %v, %v, %v", tt.value, lat, lng, ok, tt.lat, tt.lng, tt.ok)
+		}
+	}
+}
+
+func TestHaversine(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 41.3111, 69.2797, 41.3111, 69.2797, 0},
+		{"one degree of latitude", 0, 0, 1, 0, 111195},
+		{"antipodes", 0, 0, 0, 180, math.Pi * EarthRadius},
+	}
+
+	for _, tt := range tests {
+		got := Haversine(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+		if math.Abs(got-tt.want) > 1 {
+			t.Errorf("%s: Haversine = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if span := LatitudeSpan(111195); math.Abs(span-1) > 1e-4 {
+		t.Errorf("LatitudeSpan(111195) = %v, want 1", span)
+	}
+}
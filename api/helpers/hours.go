@@ -0,0 +1,184 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DateLayout is the format of holiday dates.
+	DateLayout = "2006-01-02"
+	// nextOpenHorizon bounds the search for the next opening time.
+	nextOpenHorizon = 60
+)
+
+// DayHours are the opening hours of one day as HH:MM local times. Close
+// before Open means the branch closes after midnight; Open equal to Close
+// means it is open around the clock.
+type DayHours struct {
+	Open   string
+	Close  string
+	Closed bool
+}
+
+// OpeningHours is a weekly schedule with per-weekday overrides and holiday
+// exceptions, evaluated in Timezone.
+type OpeningHours struct {
+	Timezone string
+	Default  DayHours
+	Weekdays map[time.Weekday]DayHours
+	Holidays map[string]DayHours
+}
+
+// span is a parsed DayHours; open and close are offsets from midnight.
+type span struct {
+	open, close time.Duration
+	closed      bool
+}
+
+// Schedule is OpeningHours parsed for evaluation.
+type Schedule struct {
+	location *time.Location
+	fallback span
+	weekdays map[time.Weekday]span
+	holidays map[string]span
+}
+
+// ParseTimeOfDay parses HH:MM (or HH:MM:SS) into an offset from midnight.
+func ParseTimeOfDay(value string) (time.Duration, error) {
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+		}
+	}
+
+	return 0, fmt.Errorf("time %s is invalid, expected format 15:04", value)
+}
+
+// ValidateTimezone checks that tz is an IANA time zone name.
+func ValidateTimezone(tz string) error {
+	if _, err := time.LoadLocation(tz); err != nil || tz == "" {
+		return fmt.Errorf("timezone %s is invalid, expected a name such as Asia/Tashkent", tz)
+	}
+
+	return nil
+}
+
+func parseDayHours(d DayHours) (span, error) {
+	if d.Closed || (d.Open == "" && d.Close == "") {
+		return span{closed: true}, nil
+	}
+	if d.Open == "" || d.Close == "" {
+		return span{}, errors.New("both open_time and close_time are required")
+	}
+
+	open, err := ParseTimeOfDay(d.Open)
+	if err != nil {
+		return span{}, err
+	}
+	close, err := ParseTimeOfDay(d.Close)
+	if err != nil {
+		return span{}, err
+	}
+
+	if close <= open {
+		// Overnight, or around the clock when both are equal.
+		close += 24 * time.Hour
+	}
+
+	return span{open: open, close: close}, nil
+}
+
+// ParseOpeningHours validates h and prepares it for evaluation.
+func ParseOpeningHours(h OpeningHours) (Schedule, error) {
+	location, err := time.LoadLocation(h.Timezone)
+	if err != nil || h.Timezone == "" {
+		return Schedule{}, ValidateTimezone(h.Timezone)
+	}
+
+	s := Schedule{
+		location: location,
+		weekdays: make(map[time.Weekday]span, len(h.Weekdays)),
+		holidays: make(map[string]span, len(h.Holidays)),
+	}
+
+	if s.fallback, err = parseDayHours(h.Default); err != nil {
+		return Schedule{}, err
+	}
+
+	for day, hours := range h.Weekdays {
+		if day < time.Sunday || day > time.Saturday {
+			return Schedule{}, fmt.Errorf("weekday %d is invalid, expected 0 (Sunday) to 6 (Saturday)", day)
+		}
+		if s.weekdays[day], err = parseDayHours(hours); err != nil {
+			return Schedule{}, fmt.Errorf("%s: %w", day, err)
+		}
+	}
+
+	for date, hours := range h.Holidays {
+		if _, err := time.Parse(DateLayout, date); err != nil {
+			return Schedule{}, fmt.Errorf("holiday date %s is invalid, expected format %s", date, DateLayout)
+		}
+		if s.holidays[date], err = parseDayHours(hours); err != nil {
+			return Schedule{}, fmt.Errorf("holiday %s: %w", date, err)
+		}
+	}
+
+	return s, nil
+}
+
+// day returns the hours that apply to the calendar day starting at midnight.
+func (s Schedule) day(midnight time.Time) span {
+	if hours, ok := s.holidays[midnight.Format(DateLayout)]; ok {
+		return hours
+	}
+	if hours, ok := s.weekdays[midnight.Weekday()]; ok {
+		return hours
+	}
+	return s.fallback
+}
+
+// interval returns when the branch is open on the day starting at midnight.
+func (s Schedule) interval(midnight time.Time) (time.Time, time.Time, bool) {
+	hours := s.day(midnight)
+	if hours.closed {
+		return time.Time{}, time.Time{}, false
+	}
+
+	// time.Date normalizes the seconds as wall-clock time, so hours stay
+	// local on days when the clocks change.
+	y, m, d := midnight.Date()
+	open := time.Date(y, m, d, 0, 0, int(hours.open/time.Second), 0, s.location)
+	close := time.Date(y, m, d, 0, 0, int(hours.close/time.Second), 0, s.location)
+	return open, close, true
+}
+
+func (s Schedule) midnight(t time.Time, days int) time.Time {
+	y, m, d := t.In(s.location).Date()
+	return time.Date(y, m, d+days, 0, 0, 0, 0, s.location)
+}
+
+// IsOpen reports whether the branch is open at t. Yesterday's hours are
+// checked too because they may run past midnight.
+func (s Schedule) IsOpen(t time.Time) bool {
+	for _, days := range []int{-1, 0} {
+		open, close, ok := s.interval(s.midnight(t, days))
+		if ok && !t.Before(open) && t.Before(close) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextOpen returns the next time after t at which the branch opens, looking
+// up to nextOpenHorizon days ahead.
+func (s Schedule) NextOpen(t time.Time) (time.Time, bool) {
+	for days := 0; days <= nextOpenHorizon; days++ {
+		open, _, ok := s.interval(s.midnight(t, days))
+		if ok && open.After(t) {
+			return open.In(s.location), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,115 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimeOfDay(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"09:00", 9 * time.Hour, false},
+		{"23:59:30", 23*time.Hour + 59*time.Minute + 30*time.Second, false},
+		{"00:00", 0, false},
+		{"24:00", 0, true},
+		{"9am", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTimeOfDay(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTimeOfDay(%q) = %v, %v, want %v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestParseOpeningHours(t *testing.T) {
+	tests := []struct {
+		name    string
+		hours   OpeningHours
+		wantErr bool
+	}{
+		{"valid", OpeningHours{Timezone: "Asia/Tashkent", Default: DayHours{Open: "09:00", Close: "18:00"}}, false},
+		{"closed by default", OpeningHours{Timezone: "Asia/Tashkent"}, false},
+		{"no timezone", OpeningHours{Default: DayHours{Open: "09:00", Close: "18:00"}}, true},
+		{"unknown timezone", OpeningHours{Timezone: "Mars/Olympus"}, true},
+		{"open without close", OpeningHours{Timezone: "UTC", Default: DayHours{Open: "09:00"}}, true},
+		{"invalid time", OpeningHours{Timezone: "UTC", Default: DayHours{Open: "25:00", Close: "18:00"}}, true},
+		{"invalid weekday", OpeningHours{Timezone: "UTC", Weekdays: map[time.Weekday]DayHours{7: {Closed: true}}}, true},
+		{"invalid weekday hours", OpeningHours{Timezone: "UTC", Weekdays: map[time.Weekday]DayHours{time.Monday: {Close: "18:00"}}}, true},
+		{"invalid holiday date", OpeningHours{Timezone: "UTC", Holidays: map[string]DayHours{"08.03.2026": {Closed: true}}}, true},
+	}
+
+	for _, tt := range tests {
+		if _, err := ParseOpeningHours(tt.hours); (err != nil) != tt.wantErr {
+			t.Errorf("%s: ParseOpeningHours error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	tashkent, _ := time.LoadLocation("Asia/Tashkent")
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	at := func(loc *time.Location, date string, hour, min int) time.Time {
+		d, _ := time.Parse(DateLayout, date)
+		return time.Date(d.Year(), d.Month(), d.Day(), hour, min, 0, 0, loc)
+	}
+
+	shop := OpeningHours{
+		Timezone: "Asia/Tashkent",
+		Default:  DayHours{Open: "09:00", Close: "18:00"},
+		Weekdays: map[time.Weekday]DayHours{
+			time.Saturday: {Open: "22:00", Close: "02:00"},
+			time.Sunday:   {Closed: true},
+		},
+		Holidays: map[string]DayHours{
+			"2026-03-02": {Closed: true},
+			"2026-03-08": {Open: "10:00", Close: "14:00"},
+		},
+	}
+	dst := OpeningHours{Timezone: "Europe/Berlin", Default: DayHours{Open: "01:00", Close: "04:00"}}
+	always := OpeningHours{Timezone: "UTC", Default: DayHours{Open: "00:00", Close: "00:00"}}
+	never := OpeningHours{Timezone: "UTC", Default: DayHours{Closed: true}}
+
+	tests := []struct {
+		name  string
+		hours OpeningHours
+		now   time.Time
+		open  bool
+		next  time.Time
+	}{
+		{"weekday", shop, at(tashkent, "2026-03-03", 10, 0), true, time.Time{}},
+		{"at closing", shop, at(tashkent, "2026-03-03", 18, 0), false, at(tashkent, "2026-03-04", 9, 0)},
+		{"closed holiday", shop, at(tashkent, "2026-03-02", 10, 0), false, at(tashkent, "2026-03-03", 9, 0)},
+		{"saturday override", shop, at(tashkent, "2026-03-07", 12, 0), false, at(tashkent, "2026-03-07", 22, 0)},
+		{"overnight", shop, at(tashkent, "2026-03-07", 23, 0), true, time.Time{}},
+		{"after midnight", shop, at(tashkent, "2026-03-08", 1, 59), true, time.Time{}},
+		{"holiday over closed weekday", shop, at(tashkent, "2026-03-08", 2, 0), false, at(tashkent, "2026-03-08", 10, 0)},
+		{"after the holiday", shop, at(tashkent, "2026-03-08", 15, 0), false, at(tashkent, "2026-03-09", 9, 0)},
+		{"in utc", shop, at(tashkent, "2026-03-03", 10, 0).UTC(), true, time.Time{}},
+		{"clocks forward", dst, at(berlin, "2026-03-29", 3, 30), true, time.Time{}},
+		{"before clocks forward", dst, at(berlin, "2026-03-28", 5, 0), false, at(berlin, "2026-03-29", 1, 0)},
+		{"around the clock", always, time.Date(2026, 3, 3, 23, 59, 0, 0, time.UTC), true, time.Time{}},
+		{"never open", never, time.Date(2026, 3, 3, 12, 0, 0, 0, time.UTC), false, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		s, err := ParseOpeningHours(tt.hours)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := s.IsOpen(tt.now); got != tt.open {
+			t.Errorf("%s: IsOpen(%v) = %v, want %v", tt.name, tt.now, got, tt.open)
+		}
+		if tt.open {
+			continue
+		}
+		next, ok := s.NextOpen(tt.now)
+		if ok != !tt.next.IsZero() || !next.Equal(tt.next) {
+			t.Errorf("%s: NextOpen(%v) = %v, %v, want %v", tt.name, tt.now, next, ok, tt.next)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// JSONOptions ...
+type JSONOptions struct {
+	// EmitUnpopulated writes zero values such as "active": false.
+	EmitUnpopulated bool
+	// UseProtoNames writes snake_case proto field names instead of lowerCamel.
+	UseProtoNames bool
+	// Int64AsString keeps the protojson encoding of 64-bit integers as
+	// strings; otherwise they are written as JSON numbers.
+	Int64AsString bool
+}
+
+// JSONRenderer encodes protobuf messages with protojson and applies response
+// shaping (sparse fieldsets and language collapsing) on the encoded object.
+type JSONRenderer struct {
+	marshal       protojson.MarshalOptions
+	useProtoNames bool
+	int64AsString bool
+}
+
+// NewJSONRenderer ...
+func NewJSONRenderer(opts JSONOptions) *JSONRenderer {
+	return &JSONRenderer{
+		marshal: protojson.MarshalOptions{
+			EmitUnpopulated: opts.EmitUnpopulated,
+			UseProtoNames:   opts.UseProtoNames,
+		},
+		useProtoNames: opts.UseProtoNames,
+		int64AsString: opts.Int64AsString,
+	}
+}
+
+// Marshal encodes msg. fields limits the output to the listed proto field
+// names (on the list items for list responses) and lang collapses localized
+// fields into name/description.
+func (r *JSONRenderer) Marshal(msg proto.Message, fields []string, lang string) ([]byte, error) {
+	if len(fields) == 0 && lang == "" && r.int64AsString {
+		body, err := r.marshal.Marshal(msg)
+		if err != nil {
+			return nil, err
+		}
+
+		// protojson randomizes whitespace to keep callers from depending on
+		// its exact output; compacting gives stable responses.
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, body); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	v, err := r.Object(msg, fields, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(v)
+}
+
+// Object returns msg shaped like Marshal does, as a decoded JSON object with
+// numbers kept as json.Number. It is the input for non-JSON encoders.
+func (r *JSONRenderer) Object(msg proto.Message, fields []string, lang string) (map[string]interface{}, error) {
+	body, err := r.marshal.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	var v map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	md := msg.ProtoReflect().Descriptor()
+	if list := listField(md); list != nil {
+		r.shape(v, md, nil, lang)
+		if items, ok := v[r.key(list)].([]interface{}); ok {
+			for _, item := range items {
+				if obj, ok := item.(map[string]interface{}); ok {
+					r.shape(obj, list.Message(), fields, lang)
+				}
+			}
+		}
+	} else {
+		r.shape(v, md, fields, lang)
+	}
+
+	return v, nil
+}
+
+func (r *JSONRenderer) shape(v map[string]interface{}, md protoreflect.MessageDescriptor, fields []string, lang string) {
+	keep := make(map[protoreflect.Name]bool, len(fields))
+	for _, field := range fields {
+		keep[protoreflect.Name(field)] = true
+	}
+
+	fds := md.Fields()
+	for i := 0; i < fds.Len(); i++ {
+		fd := fds.Get(i)
+		key := r.key(fd)
+
+		value, ok := v[key]
+		if !ok {
+			continue
+		}
+
+		if len(keep) > 0 && !keep[fd.Name()] {
+			delete(v, key)
+			continue
+		}
+
+		switch {
+		case is64Bit(fd) && !r.int64AsString:
+			v[key] = int64Number(value)
+		case fd.Kind() == protoreflect.MessageKind && !fd.IsList():
+			if obj, ok := value.(map[string]interface{}); ok {
+				r.shape(obj, fd.Message(), nil, lang)
+			}
+		}
+	}
+
+	if lang != "" {
+		r.collapseLanguage(v, md, lang)
+	}
+}
+
+// collapseLanguage replaces name_uz/name_ru/name_en (and description_*) with
+// a single name in lang, falling back to the other languages when that one
+// is empty.
+func (r *JSONRenderer) collapseLanguage(v map[string]interface{}, md protoreflect.MessageDescriptor, lang string) {
+	for _, base := range localizedFields {
+		var (
+			found bool
+			value interface{}
+		)
+
+		for _, l := range append([]string{lang}, Languages...) {
+			fd := md.Fields().ByName(protoreflect.Name(base + "_" + l))
+			if fd == nil {
+				continue
+			}
+
+			localized, ok := v[r.key(fd)]
+			if !ok {
+				continue
+			}
+
+			found = true
+			if s, _ := localized.(string); s != "" && value == nil {
+				value = s
+			}
+		}
+
+		if !found {
+			continue
+		}
+
+		for _, l := range Languages {
+			if fd := md.Fields().ByName(protoreflect.Name(base + "_" + l)); fd != nil {
+				delete(v, r.key(fd))
+			}
+		}
+		if value == nil {
+			value = ""
+		}
+		v[base] = value
+	}
+}
+
+func (r *JSONRenderer) key(fd protoreflect.FieldDescriptor) string {
+	if r.useProtoNames {
+		return string(fd.Name())
+	}
+	return fd.JSONName()
+}
+
+func is64Bit(fd protoreflect.FieldDescriptor) bool {
+	switch fd.Kind() {
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return true
+	}
+	return false
+}
+
+func int64Number(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return json.Number(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = int64Number(v[i])
+		}
+	}
+	return value
+}
+
+// NativeNumbers replaces json.Number values in a decoded JSON value with
+// int64 or float64 so that binary encoders write them as numbers.
+func NativeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = NativeNumbers(v[k])
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = NativeNumbers(v[i])
+		}
+	}
+	return value
+}
+
+// StringKeys converts the map[interface{}]interface{} values produced by
+// generic decoders such as msgpack into map[string]interface{}, so the result
+// can be re-encoded as JSON.
+func StringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = StringKeys(val)
+		}
+		return m
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = StringKeys(v[k])
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = StringKeys(v[i])
+		}
+	case []byte:
+		return string(v)
+	}
+	return value
+}
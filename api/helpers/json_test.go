@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"microservice/genproto/user_service"
+)
+
+func TestJSONRenderer(t *testing.T) {
+	list := &user_service.GetListShopResponse{
+		Count: 12,
+		Shops: []*user_service.Shop{{Id: "1", Slug: "tea", NameEn: "Tea", NameRu: "Чай"}},
+	}
+
+	tests := []struct {
+		name   string
+		opts   JSONOptions
+		fields []string
+		lang   string
+		want   string
+	}{
+		{
+			name: "sparse",
+			opts: JSONOptions{UseProtoNames: true},
+			want: `{"count":12,"shops":[{"id":"1","name_en":"Tea","name_ru":"Чай","slug":"tea"}]}`,
+		},
+		{
+			name: "int64 as string",
+			opts: JSONOptions{UseProtoNames: true, Int64AsString: true},
+			want: `{"count":"12","shops":[{"id":"1","slug":"tea","name_ru":"Чай","name_en":"Tea"}]}`,
+		},
+		{
+			name:   "lowerCamel with fields",
+			opts:   JSONOptions{},
+			fields: []string{"id", "name_en"},
+			want:   `{"count":12,"shops":[{"id":"1","nameEn":"Tea"}]}`,
+		},
+		{
+			name:   "lang",
+			opts:   JSONOptions{UseProtoNames: true},
+			fields: []string{"name_uz", "name_ru", "name_en"},
+			lang:   "uz",
+			want:   `{"count":12,"shops":[{"name":"Чай"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := NewJSONRenderer(tt.opts).Marshal(list, tt.fields, tt.lang)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: Marshal = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// Unpopulated fields are written with their zero values.
+	got, err := NewJSONRenderer(JSONOptions{EmitUnpopulated: true, UseProtoNames: true}).Marshal(&user_service.Branch{}, []string{"active", "deleted_at"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"active":false,"deleted_at":0}`; string(got) != want {
+		t.Errorf("Marshal with EmitUnpopulated = %s, want %s", got, want)
+	}
+}
+
+func TestNativeNumbers(t *testing.T) {
+	in := map[string]interface{}{
+		"count": json.Number("12"),
+		"items": []interface{}{json.Number("1.5"), "x"},
+	}
+	want := map[string]interface{}{
+		"count": int64(12),
+		"items": []interface{}{1.5, "x"},
+	}
+	if got := NativeNumbers(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("NativeNumbers = %#v, want %#v", got, want)
+	}
+}
+
+func TestStringKeys(t *testing.T) {
+	in := map[interface{}]interface{}{
+		"name": "tea",
+		1:      []interface{}{map[interface{}]interface{}{"id": "2"}},
+	}
+	want := map[string]interface{}{
+		"name": "tea",
+		"1":    []interface{}{map[string]interface{}{"id": "2"}},
+	}
+	if got := StringKeys(in); !reflect.DeepEqual(got, want) {
+		t.Errorf("StringKeys = %#v, want %#v", got, want)
+	}
+}
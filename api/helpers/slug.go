@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxSlugLength ...
+const MaxSlugLength = 64
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+
+// cyrillicToLatin follows the Uzbek Latin alphabet, which also covers the
+// Russian letters used in shop names.
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "j", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "x", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sh", 'ъ': "",
+	'ы': "i", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya", 'ў': "o", 'қ': "q",
+	'ғ': "g", 'ҳ': "h",
+}
+
+// ValidateSlug checks that slug is lowercase letters and digits separated by
+// single hyphens.
+func ValidateSlug(slug string) error {
+	if len(slug) > MaxSlugLength {
+		return errors.New("slug is too long")
+	}
+	if !slugRegex.MatchString(slug) {
+		return errors.New("slug must contain only lowercase latin letters, digits and single hyphens")
+	}
+
+	return nil
+}
+
+// Slugify turns a name into a slug: Cyrillic is transliterated, accents and
+// apostrophes (as in o'zbek) are dropped and everything else becomes a
+// hyphen.
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+
+	for _, r := range strings.ToLower(name) {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			hyphen = false
+			continue
+		}
+
+		// Decomposing splits letters such as é into e and a combining accent.
+		for _, d := range norm.NFD.String(string(r)) {
+			switch {
+			case d < unicode.MaxASCII && (unicode.IsLetter(d) || unicode.IsDigit(d)):
+				b.WriteRune(d)
+				hyphen = false
+			case unicode.Is(unicode.Mn, d), d == '\'', d == '‘', d == '’', d == 'ʻ', d == 'ʼ', d == '`':
+				// Accents and apostrophes do not split words.
+			case !hyphen && b.Len() > 0:
+				b.WriteByte('-')
+				hyphen = true
+			}
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > MaxSlugLength {
+		slug = strings.TrimRight(slug[:MaxSlugLength], "-")
+	}
+	return slug
+}
@@ -0,0 +1,54 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"Coffee Shop", "coffee-shop"},
+		{"  Café -- Crème ", "cafe-creme"},
+		{"O'zbek Milliy Taomlari", "ozbek-milliy-taomlari"},
+		{"Чайхана №1", "chayxana-1"},
+		{"Қўқон ғишт", "qoqon-gisht"},
+		{"!!!", ""},
+		{strings.Repeat("ab ", 40), strings.TrimRight(strings.Repeat("ab-", 22)[:64], "-")},
+	}
+
+	for _, tt := range tests {
+		got := Slugify(tt.name)
+		if got != tt.want {
+			t.Errorf("Slugify(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+		if got != "" {
+			if err := ValidateSlug(got); err != nil {
+				t.Errorf("Slugify(%q) = %q: %v", tt.name, got, err)
+			}
+		}
+	}
+}
+
+func TestValidateSlug(t *testing.T) {
+	tests := []struct {
+		slug  string
+		valid bool
+	}{
+		{"shop", true},
+		{"shop-2", true},
+		{"Shop", false},
+		{"shop--2", false},
+		{"-shop", false},
+		{"shop-", false},
+		{"", false},
+		{strings.Repeat("a", 65), false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateSlug(tt.slug); (err == nil) != tt.valid {
+			t.Errorf("ValidateSlug(%q) = %v, want valid %v", tt.slug, err, tt.valid)
+		}
+	}
+}
@@ -48,6 +48,14 @@ func ValidateDates(startDate, endDate string) error {
 	return nil
 }
 
+func ValidateDateTime(date string) error {
+	if _, err := time.Parse("2006-01-02 15:04:05", date); err != nil {
+		return fmt.Errorf("date %s is invalid, expected format 2006-01-02 15:04:05", date)
+	}
+
+	return nil
+}
+
 func ValidatePassword(password string) error {
 	if password == "" {
 		return errors.New("password cannot be blank")
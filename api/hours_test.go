@@ -0,0 +1,82 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+)
+
+func TestBranchHours(t *testing.T) {
+	s := newTestServer(t)
+	s.branches.Add(&pb.Branch{Name: "always", OpenTime: "00:00", CloseTime: "00:00", Timezone: "Asia/Tashkent"})
+	s.branches.Add(&pb.Branch{Name: "never"})
+	s.branches.Add(&pb.Branch{Name: "broken", OpenTime: "9am", CloseTime: "6pm"})
+
+	tests := []struct {
+		path     string
+		contains []string
+		excludes []string
+	}{
+		{"/getbyidbranch/1", []string{`"is_open_now":true`, `"next_open_at":""`}, nil},
+		{"/GetListBranch?open_now=true", []string{`"count":1`, `"name":"always"`}, []string{`"name":"never"`}},
+		{"/GetListBranch?open_now=false&limit=1&page=2", []string{`"count":2`, `"name":"broken"`, `"is_open_now":false`}, []string{`"name":"always"`, `"name":"never"`}},
+	}
+	for _, tt := range tests {
+		w := s.do("GET", tt.path, "", "Authorization", admin)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s = %d %s", tt.path, w.Code, w.Body)
+		}
+		for _, want := range tt.contains {
+			if !strings.Contains(w.Body.String(), want) {
+				t.Errorf("GET %s = %s, want %s", tt.path, w.Body, want)
+			}
+		}
+		for _, unwanted := range tt.excludes {
+			if strings.Contains(w.Body.String(), unwanted) {
+				t.Errorf("GET %s = %s, want no %s", tt.path, w.Body, unwanted)
+			}
+		}
+	}
+
+	invalid := []struct {
+		name, method, path, body string
+	}{
+		{"unknown timezone", "POST", "/createBranch", `{"name":"A","phone":"+998901234567","timezone":"Mars/Olympus"}`},
+		{"close without open", "POST", "/createBranch", `{"name":"A","phone":"+998901234567","close_time":"18:00"}`},
+		{"weekday twice", "PUT", "/updateBranch/1", `{"name":"A","phone":"+998901234567","schedule":[{"weekday":1,"closed":true},{"weekday":1,"closed":true}]}`},
+		{"invalid holiday", "PUT", "/updateBranch/1", `{"name":"A","phone":"+998901234567","holidays":[{"date":"08.03.2026","closed":true}]}`},
+		{"open_now with cursor", "GET", "/GetListBranch?open_now=true&cursor=abc", ""},
+	}
+	for _, tt := range invalid {
+		if w := s.do(tt.method, tt.path, tt.body, "Authorization", admin); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: %s %s = %d %s, want 400", tt.name, tt.method, tt.path, w.Code, w.Body)
+		}
+	}
+
+	w := s.do("POST", "/createBranch", `{"name":"B","phone":"+998901234567","open_time":"22:00","close_time":"02:00",`+
+		`"schedule":[{"weekday":0,"closed":true}],"holidays":[{"date":"2026-03-08","open_time":"10:00","close_time":"14:00"}]}`, "Authorization", admin)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"is_open_now"`) {
+		t.Errorf("createBranch with a schedule = %d %s", w.Code, w.Body)
+	}
+}
+
+func TestOpenNowScanLimit(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.BranchScanLimit = 2
+		cfg.MaxPageSize = 1
+	})
+	for _, shop := range []string{"1", "1", "2"} {
+		s.branches.Add(&pb.Branch{Name: "always", ShopId: shop, OpenTime: "00:00", CloseTime: "00:00"})
+	}
+
+	// Three branches cannot be filtered within the limit; two can.
+	if w := s.do("GET", "/GetListBranch?open_now=true", "", "Authorization", admin); w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "narrow the filter") {
+		t.Errorf("open_now over the limit = %d %s, want 400", w.Code, w.Body)
+	}
+	if w := s.do("GET", "/GetListBranch?open_now=true&shop_id=1", "", "Authorization", admin); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"count":2`) {
+		t.Errorf("open_now within the limit = %d %s, want both branches", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+)
+
+func TestIdempotency(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.IdempotencyMaxBody = 256 })
+	body := `{"phone":"+998901234567","email":"a@example.com","firstname":"Ann"}`
+
+	first := s.do("POST", "/createCustomer", body, "Authorization", seller, "Idempotency-Key", "k1")
+	if first.Code >= 300 {
+		t.Fatalf("first POST = %d: %s", first.Code, first.Body)
+	}
+
+	retry := s.do("POST", "/createCustomer", body, "Authorization", seller, "Idempotency-Key", "k1")
+	if retry.Code != first.Code || retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("retry = %d %q replayed=%q, want the first response replayed", retry.Code, retry.Body, retry.Header().Get("Idempotent-Replayed"))
+	}
+	if n := s.customers.Calls("Create"); n != 1 {
+		t.Errorf("Create called %d times, want 1", n)
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		headers []string
+		want    int
+	}{
+		{"different body", strings.Replace(body, "Ann", "Bob", 1), []string{"Authorization", seller, "Idempotency-Key", "k1"}, http.StatusConflict},
+		{"anonymous", body, []string{"Idempotency-Key", "k2"}, http.StatusUnauthorized},
+		{"too long key", body, []string{"Authorization", seller, "Idempotency-Key", strings.Repeat("k", 256)}, http.StatusBadRequest},
+		{"too large body", `{"firstname":"` + strings.Repeat("a", 300) + `"}`, []string{"Authorization", seller, "Idempotency-Key", "k3"}, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := s.do("POST", "/createCustomer", tt.body, tt.headers...); w.Code != tt.want {
+				t.Errorf("POST = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	// Keys are scoped to the caller: another one with the same key gets its
+	// own request.
+	other := s.do("POST", "/createCustomer", body, "Authorization", bearer("seller-2", "seller"), "Idempotency-Key", "k1")
+	if other.Code >= 300 || other.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("POST by another caller = %d replayed=%q, want a new request", other.Code, other.Header().Get("Idempotent-Replayed"))
+	}
+	if n := s.customers.Calls("Create"); n != 2 {
+		t.Errorf("Create called %d times, want 2", n)
+	}
+}
+
+func TestIdempotencyReplaysHeaders(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "tea", NameEn: "Tea", Phone: "+998901234567"})
+	if w := s.do("DELETE", "/DeleteShop/1", "", "Authorization", admin); w.Code != http.StatusOK {
+		t.Fatalf("DELETE = %d: %s", w.Code, w.Body)
+	}
+
+	first := s.do("POST", "/v1/shops/1/restore", "", "Authorization", admin, "Idempotency-Key", "r1")
+	if first.Code != http.StatusOK || first.Header().Get("ETag") == "" {
+		t.Fatalf("restore = %d with ETag %q: %s", first.Code, first.Header().Get("ETag"), first.Body)
+	}
+
+	retry := s.do("POST", "/v1/shops/1/restore", "", "Authorization", admin, "Idempotency-Key", "r1")
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Fatalf("retry was not replayed: %d %s", retry.Code, retry.Body)
+	}
+	for _, name := range []string{"ETag", "Vary", "Content-Type"} {
+		if got, want := retry.Header().Values(name), first.Header().Values(name); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("replayed %s = %q, want %q", name, got, want)
+		}
+	}
+
+	// A retry asking for another representation is another request.
+	if w := s.do("POST", "/v1/shops/1/restore", "", "Authorization", admin, "Idempotency-Key", "r1", "Accept", "application/x-protobuf"); w.Code != http.StatusConflict {
+		t.Errorf("retry accepting protobuf = %d, want 409: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"microservice/api/models"
+	"microservice/config"
+	"microservice/pkg/jobs"
+)
+
+// upload returns a multipart body with csv as the file field and its
+// Content-Type.
+func upload(t *testing.T, csv string) (string, string) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "import.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write([]byte(csv))
+	if err := mw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String(), mw.FormDataContentType()
+}
+
+func TestImport(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.ImportMaxRows = 3 })
+
+	// Localized export headers are accepted, so exported files import back.
+	const file = "\xEF\xBB\xBFТелефон,email,firstname\n" +
+		"+998901234567,a@example.com,Ann\n" +
+		"123,b@example.com,Bob\n"
+
+	body, contentType := upload(t, file)
+	w := s.do("POST", "/importCustomer", body, "Authorization", admin, "Content-Type", contentType)
+	var report models.ImportReport
+	decode(t, w, &report)
+	if w.Code != http.StatusOK || !report.DryRun || report.Total != 2 || report.Valid != 1 || report.Invalid != 1 ||
+		report.Rows[1].Row != 3 || report.Rows[1].Valid || s.customers.Len() != 0 {
+		t.Errorf("dry run = %d %+v with %d customers, want row 3 invalid and nothing created", w.Code, report, s.customers.Len())
+	}
+
+	if w := s.do("POST", "/importCustomer?mode=commit", body, "Authorization", admin, "Content-Type", contentType); w.Code != http.StatusBadRequest || s.customers.Len() != 0 {
+		t.Errorf("commit with an invalid row = %d %s, want 400 and nothing created", w.Code, w.Body)
+	}
+
+	body, contentType = upload(t, "phone,email,firstname\n+998901234567,a@example.com,Ann\n+998901234568,b@example.com,=Bob\n")
+	w = s.do("POST", "/importCustomer?mode=commit", body, "Authorization", admin, "Content-Type", contentType)
+	report = models.ImportReport{}
+	decode(t, w, &report)
+	if w.Code != http.StatusAccepted || report.JobID == "" {
+		t.Fatalf("commit = %d %s, want 202 with a job", w.Code, w.Body)
+	}
+	var job jobs.Job
+	eventually(t, func() bool {
+		decode(t, s.do("GET", "/jobs/"+report.JobID, "", "Authorization", admin), &job)
+		return job.Status.Finished()
+	}, "import job did not finish")
+	var rows []models.ImportRow
+	if err := json.Unmarshal(job.Result, &rows); err != nil || job.Status != jobs.StatusSucceeded || len(rows) != 2 || rows[0].ID == "" || rows[1].Row != 3 {
+		t.Errorf("import job = %+v with result %s, want two rows created", job, job.Result)
+	}
+	if customer, _ := s.customers.Get(rows[1].ID); customer.GetFirstname() != "=Bob" {
+		t.Errorf("imported firstname = %q, want =Bob", customer.GetFirstname())
+	}
+
+	tests := []struct {
+		name, path, csv string
+		want            int
+	}{
+		{"unknown column", "/importCustomer", "phone,password\n", http.StatusBadRequest},
+		{"empty file", "/ImportSeller", "", http.StatusBadRequest},
+		{"too many rows", "/importBranch", "name\nA\nB\nC\nD\n", http.StatusBadRequest},
+		{"unknown mode", "/importBranch?mode=apply", "name\nA\n", http.StatusBadRequest},
+		{"branch dry run", "/importBranch", "name,phone,active\nA,+998901234567,yes\n", http.StatusOK},
+	}
+	for _, tt := range tests {
+		body, contentType := upload(t, tt.csv)
+		if w := s.do("POST", tt.path, body, "Authorization", admin, "Content-Type", contentType); w.Code != tt.want {
+			t.Errorf("%s: POST %s = %d %s, want %d", tt.name, tt.path, w.Code, w.Body, tt.want)
+		}
+	}
+	if w := s.do("POST", "/importCustomer", "", "Authorization", admin); w.Code != http.StatusBadRequest {
+		t.Errorf("import without a file = %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSellerShopCheck(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.CoalesceReads = false })
+	s.shops.Add(&pb.Shop{Slug: "a"})
+	s.shops.Add(&pb.Shop{Slug: "gone", DeletedAt: "2026-01-01 00:00:00"})
+
+	tests := []struct {
+		name   string
+		shopID string
+		want   int
+	}{
+		{"existing shop", "1", http.StatusOK},
+		{"no shop", "", http.StatusBadRequest},
+		{"unknown shop", "9", http.StatusBadRequest},
+		{"deleted shop", "2", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		body := `{"name":"Ann","phone":"+998901234567","email":"ann@example.com","shop_id":"` + tt.shopID + `"}`
+		if w := s.do("POST", "/CreateSeller", body, "Authorization", admin); w.Code != tt.want {
+			t.Errorf("%s: CreateSeller = %d %s, want %d", tt.name, w.Code, w.Body, tt.want)
+		}
+		if w := s.do("PUT", "/UpdateSeller/1", body, "Authorization", admin); tt.want != http.StatusOK && w.Code != tt.want {
+			t.Errorf("%s: UpdateSeller = %d %s, want %d", tt.name, w.Code, w.Body, tt.want)
+		}
+	}
+
+	// The shop is checked only once the rest of the request is valid.
+	calls := s.shops.Calls("GetByID")
+	body := `{"name":"Ann","phone":"+998901234567","email":"ann","shop_id":"1"}`
+	for _, w := range []*httptest.ResponseRecorder{
+		s.do("POST", "/CreateSeller", body, "Authorization", admin),
+		s.do("PUT", "/UpdateSeller/1", body, "Authorization", admin),
+	} {
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("seller with an invalid email = %d %s, want 400", w.Code, w.Body)
+		}
+	}
+	if n := s.shops.Calls("GetByID") - calls; n != 0 {
+		t.Errorf("sellers with an invalid email looked their shop up %d times", n)
+	}
+
+	// A bulk request looks each shop up once, and not from the cache.
+	calls = s.shops.Calls("GetByID")
+	body = `{"sellers":[` +
+		`{"name":"A","phone":"+998901234567","email":"a@example.com","shop_id":"1"},` +
+		`{"name":"B","phone":"+998901234568","email":"b@example.com","shop_id":"1"}]}`
+	if w := s.do("POST", "/BulkCreateSeller", body, "Authorization", admin); w.Code != http.StatusOK {
+		t.Fatalf("BulkCreateSeller = %d %s", w.Code, w.Body)
+	}
+	if n := s.shops.Calls("GetByID") - calls; n != 1 {
+		t.Errorf("GetByID called %d times for two sellers of one shop, want 1", n)
+	}
+}
+
+func TestDeleteShopDependents(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "a"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.branches.Add(&pb.Branch{Name: "A", ShopId: "1"})
+	s.branches.Add(&pb.Branch{Name: "other", ShopId: "2"})
+
+	var deps struct {
+		SellerCount int64    `json:"seller_count"`
+		BranchCount int64    `json:"branch_count"`
+		SellerIDs   []string `json:"seller_ids"`
+	}
+	w := s.do("DELETE", "/DeleteShop/1", "", "Authorization", admin)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("DeleteShop with dependents = %d %s, want 409", w.Code, w.Body)
+	}
+	decode(t, w, &deps)
+	if deps.SellerCount != 1 || deps.BranchCount != 1 || len(deps.SellerIDs) != 1 {
+		t.Errorf("dependents = %+v, want one seller and one branch", deps)
+	}
+
+	if w := s.do("DELETE", "/DeleteShop/1?cascade=true", "", "Authorization", admin); w.Code != http.StatusOK {
+		t.Fatalf("DeleteShop?cascade=true = %d %s", w.Code, w.Body)
+	}
+	if s.shops.Len() != 0 || s.sellers.Len() != 0 || s.branches.Len() != 1 {
+		t.Errorf("after cascade: %d shops, %d sellers, %d branches, want 0, 0, 1", s.shops.Len(), s.sellers.Len(), s.branches.Len())
+	}
+}
+
+func TestDeleteShopCascadeGivesUp(t *testing.T) {
+	// With MAX_PAGE_SIZE=0 the cascade must still stop on dependents that
+	// never go away.
+	s := newTestServer(t, func(cfg *config.Config) { cfg.MaxPageSize = 0 })
+	s.shops.Add(&pb.Shop{Slug: "a"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.sellers.Fail("Delete", status.Error(codes.NotFound, "no rows in result set"))
+
+	if w := s.do("DELETE", "/DeleteShop/1?cascade=true", "", "Authorization", admin); w.Code != http.StatusInternalServerError {
+		t.Fatalf("DeleteShop?cascade=true = %d %s, want 500", w.Code, w.Body)
+	}
+	if s.shops.Len() != 1 {
+		t.Error("shop was deleted although its seller was not")
+	}
+}
+
+func TestDeleteShopCascadeVersion(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "a"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.branches.Add(&pb.Branch{Name: "A", ShopId: "1"})
+
+	// A stale If-Match deletes nothing.
+	if w := s.do("DELETE", "/DeleteShop/1?cascade=true", "", "Authorization", admin, "If-Match", `"MjAyMC0wMS0wMSAwMDowMDowMA"`); w.Code != http.StatusPreconditionFailed {
+		t.Fatalf("DeleteShop with a stale If-Match = %d %s, want 412", w.Code, w.Body)
+	}
+	if s.shops.Len() != 1 || s.sellers.Len() != 1 || s.branches.Len() != 1 {
+		t.Errorf("after a stale cascade: %d shops, %d sellers, %d branches, want 1 of each", s.shops.Len(), s.sellers.Len(), s.branches.Len())
+	}
+
+	// A branch that cannot be deleted brings back the shop and its seller.
+	s.branches.Fail("Delete", status.Error(codes.Unavailable, "connection refused"))
+	if w := s.do("DELETE", "/DeleteShop/1?cascade=true", "", "Authorization", admin); w.Code != http.StatusInternalServerError {
+		t.Fatalf("DeleteShop with a failing branch = %d %s, want 500", w.Code, w.Body)
+	}
+	if s.shops.Len() != 1 || s.sellers.Len() != 1 || s.branches.Len() != 1 {
+		t.Errorf("after a failed cascade: %d shops, %d sellers, %d branches, want 1 of each", s.shops.Len(), s.sellers.Len(), s.branches.Len())
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/jobs"
+)
+
+func TestJobs(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "a", NameEn: "=HYPERLINK(\"http://x\")"})
+
+	if w := s.do("GET", "/ExportShop?async=true", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("anonymous async export = %d, want 401", w.Code)
+	}
+
+	w := s.do("GET", "/ExportShop?async=true&columns=id,name_en", "", "Authorization", seller)
+	location := w.Header().Get("Location")
+	if w.Code != http.StatusAccepted || location == "" {
+		t.Fatalf("async export = %d with Location %q, want 202", w.Code, location)
+	}
+
+	var job jobs.Job
+	eventually(t, func() bool {
+		decode(t, s.do("GET", location, "", "Authorization", seller), &job)
+		return job.Status.Finished()
+	}, "export job did not finish")
+	if job.Status != jobs.StatusSucceeded {
+		t.Fatalf("job = %+v, want succeeded", job)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		auth   string
+		want   int
+	}{
+		{"anonymous get", "GET", location, "", http.StatusUnauthorized},
+		{"other get", "GET", location, bearer("seller-2", "seller"), http.StatusNotFound},
+		{"other download", "GET", location + "/download", bearer("seller-2", "seller"), http.StatusNotFound},
+		{"other cancel", "POST", location + "/cancel", bearer("seller-2", "seller"), http.StatusNotFound},
+		{"admin get", "GET", location, admin, http.StatusOK},
+		{"owner cancel finished", "POST", location + "/cancel", seller, http.StatusConflict},
+		{"unknown", "GET", "/jobs/nope", seller, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers []string
+			if tt.auth != "" {
+				headers = []string{"Authorization", tt.auth}
+			}
+			if w := s.do(tt.method, tt.path, "", headers...); w.Code != tt.want {
+				t.Errorf("%s %s = %d, want %d: %s", tt.method, tt.path, w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	w = s.do("GET", location+"/download", "", "Authorization", seller)
+	if w.Code != http.StatusOK || !strings.Contains(w.Header().Get("Content-Disposition"), "shops.csv") {
+		t.Fatalf("download = %d %q", w.Code, w.Header().Get("Content-Disposition"))
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"'=HYPERLINK(""http://x"")"`) {
+		t.Errorf("export = %q, want the formula escaped", body)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+)
+
+func TestListParams(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) { cfg.MaxPageSize = 3 })
+	for _, gender := range []string{"male", "female", "male", "male", "male"} {
+		s.customers.Add(&pb.Customer{Firstname: "A", Gender: gender})
+	}
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.sellers.Add(&pb.Seller{Name: "B", ShopId: "2"})
+
+	var list struct {
+		Count     int64         `json:"count"`
+		Customers []pb.Customer `json:"customers"`
+	}
+	w := s.do("GET", "/getlistcustomer?gender=male&limit=50&sort=created_at:desc", "", "Authorization", admin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GetListCustomer = %d %s", w.Code, w.Body)
+	}
+	decode(t, w, &list)
+	if list.Count != 4 || len(list.Customers) != 3 {
+		t.Errorf("GetListCustomer?gender=male&limit=50 = %d of %d customers, want 3 of 4", len(list.Customers), list.Count)
+	}
+	if link := w.Header().Get("Link"); !strings.Contains(link, `limit=50&page=2&sort=created_at%3Adesc>; rel="next"`) {
+		t.Errorf("Link = %s, want a next page", link)
+	}
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/getlistcustomer?sort=password", http.StatusBadRequest},
+		{"/getlistcustomer?sort=created_at:up", http.StatusBadRequest},
+		{"/getlistcustomer?page=x", http.StatusBadRequest},
+		{"/getlistcustomer?created_at_from=2026-02-01%2000:00:00&created_at_to=2026-01-01%2000:00:00", http.StatusBadRequest},
+		{"/GetListBranch?active=maybe", http.StatusBadRequest},
+		{"/GetListShop?sort=slug:desc,name_en", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if w := s.do("GET", tt.path, "", "Authorization", admin); w.Code != tt.want {
+			t.Errorf("GET %s = %d %s, want %d", tt.path, w.Code, w.Body, tt.want)
+		}
+	}
+
+	w = s.do("GET", "/GetListSeller?shop_id=2", "", "Authorization", admin)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"B"`) || strings.Contains(w.Body.String(), `"name":"A"`) {
+		t.Errorf("GetListSeller?shop_id=2 = %d %s, want seller B only", w.Code, w.Body)
+	}
+}
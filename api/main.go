@@ -3,10 +3,15 @@ package api
 import (
 	"microservice/api/handler"
 	"microservice/config"
-	"net/http"
-	"microservice/pkg/logger"
+	"microservice/pkg/audit"
+	"microservice/pkg/events"
 	"microservice/pkg/grpc_client"
-
+	"microservice/pkg/idempotency"
+	"microservice/pkg/jobs"
+	"microservice/pkg/logger"
+	"microservice/pkg/saga"
+	"microservice/pkg/webhook"
+	"net/http"
 
 	_ "microservice/api/docs" //for swagger
 
@@ -18,11 +23,26 @@ import (
 
 // Config ...
 type Config struct {
-	Logger     logger.Logger
-	GrpcClient *grpc_client.GrpcClient
-	Cfg        config.Config
+	Logger      logger.Logger
+	GrpcClient  *grpc_client.GrpcClient
+	Cfg         config.Config
+	Jobs        *jobs.Manager
+	Idempotency idempotency.Store
+	Sagas       *saga.Coordinator
+	Audit       audit.Sink
+	Webhooks    *webhook.Dispatcher
+	Events      *events.Hub
 }
 
+// @description Responses are protobuf messages encoded with protojson. Field names are the
+// @description snake_case proto names (lowerCamel when JSON_USE_PROTO_NAMES=false), unset
+// @description fields are written with their zero values (JSON_EMIT_UNPOPULATED) and 64-bit
+// @description integers are numbers unless JSON_INT64_AS_STRING=true.
+// @description Authorized POST requests may send an Idempotency-Key header; retries with the same
+// @description key and body get the stored first response (marked with Idempotent-Replayed: true).
+// @description user_service RPCs with a google.api.http annotation in protos/user_service are
+// @description also served at the annotated paths, e.g. GET /v1/shops/{id}, without being listed here;
+// @description those with a route here get the same handler, checks and response at both paths.
 // @securityDefinitions.apikey ApiKeyAuth
 // @in header
 // @name Authorization
@@ -31,22 +51,32 @@ func New(cnf Config) *gin.Engine {
 
 	r.Static("/images", "./static/images")
 
-	r.Use(gin.Logger())
+	r.Use(handler.RequestLogger())
 
 	r.Use(gin.Recovery())
 
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowHeaders = append(config.AllowHeaders, "*")
+	config.ExposeHeaders = append(config.ExposeHeaders, "ETag", "Link", "Location", "Idempotent-Replayed", "X-Request-ID")
 	// config.AllowOrigins = cnf.Cfg.AllowOrigins
 	r.Use(cors.New(config))
 
 	handler := handler.New(&handler.HandlerConfig{
-		Logger:     cnf.Logger,
-		GrpcClient: cnf.GrpcClient,
-		Cfg:        cnf.Cfg,
+		Logger:      cnf.Logger,
+		GrpcClient:  cnf.GrpcClient,
+		Cfg:         cnf.Cfg,
+		Jobs:        cnf.Jobs,
+		Idempotency: cnf.Idempotency,
+		Sagas:       cnf.Sagas,
+		Audit:       cnf.Audit,
+		Webhooks:    cnf.Webhooks,
+		Events:      cnf.Events,
 	})
 
+	r.Use(handler.Audit())
+	r.Use(handler.Idempotency())
+
 	r.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"data": "Api gateway"})
 	})
@@ -56,6 +86,11 @@ func New(cnf Config) *gin.Engine {
 	r.GET("/getbyidcustomer/:id", handler.GetCustomerByID)
 	r.PUT("/updateCustomer/:id", handler.UpdateCustomer)
 	r.DELETE("/deleteCustomer/:id", handler.DeleteCustomer)
+	r.POST("/bulkCreateCustomer", handler.BulkCreateCustomer)
+	r.PUT("/bulkUpdateCustomer", handler.BulkUpdateCustomer)
+	r.DELETE("/bulkDeleteCustomer", handler.BulkDeleteCustomer)
+	r.GET("/exportCustomer", handler.ExportCustomer)
+	r.POST("/importCustomer", handler.ImportCustomer)
 
 	r.POST("/CreateUser", handler.CreateUser)
 	r.GET("/GetListUser", handler.GetListUser)
@@ -68,19 +103,77 @@ func New(cnf Config) *gin.Engine {
 	r.GET("/GetByIdSeller/:id", handler.GetSellerByID)
 	r.PUT("/UpdateSeller/:id", handler.UpdateSeller)
 	r.DELETE("/DeleteSeller/:id", handler.DeleteSeller)
+	r.POST("/BulkCreateSeller", handler.BulkCreateSeller)
+	r.PUT("/BulkUpdateSeller", handler.BulkUpdateSeller)
+	r.DELETE("/BulkDeleteSeller", handler.BulkDeleteSeller)
+	r.GET("/ExportSeller", handler.ExportSeller)
+	r.POST("/ImportSeller", handler.ImportSeller)
 
 	r.POST("/createBranch", handler.CreateBranch)
 	r.GET("/GetListBranch", handler.GetListBranch)
 	r.GET("/getbyidbranch/:id", handler.GetBranchByID)
 	r.PUT("/updateBranch/:id", handler.UpdateBranch)
 	r.DELETE("/deleteBranch/:id", handler.DeleteBranch)
+	r.POST("/bulkCreateBranch", handler.BulkCreateBranch)
+	r.PUT("/bulkUpdateBranch", handler.BulkUpdateBranch)
+	r.DELETE("/bulkDeleteBranch", handler.BulkDeleteBranch)
+	r.POST("/importBranch", handler.ImportBranch)
+	r.GET("/branches/nearby", handler.NearbyBranches)
 
 	r.POST("/CreateShop", handler.CreateShop)
 	r.GET("/GetListShop", handler.GetListShop)
 	r.GET("/GetByIdShop/:id", handler.GetShopByID)
+	r.GET("/GetBySlugShop/:slug", handler.GetShopBySlug)
+	r.GET("/SuggestShopSlug", handler.SuggestShopSlug)
 	r.PUT("/UpdateShop/:id", handler.UpdateShop)
 	r.DELETE("/DeleteShop/:id", handler.DeleteShop)
-	
+	r.GET("/ExportShop", handler.ExportShop)
+
+	r.POST("/graphql", handler.GraphQL)
+	r.GET("/graphql", handler.GraphQL)
+
+	v1 := r.Group("/v1")
+	v1.GET("/shops/:id/full", handler.GetShopFull)
+	v1.POST("/onboarding", handler.OnboardSeller)
+	v1.GET("/onboarding/:id", handler.GetOnboarding)
+	v1.GET("/events", handler.StreamEvents)
+	v1.GET("/events/ws", handler.EventsWebSocket)
+
+	v1.POST("/customers/:id/restore", handler.RestoreCustomer)
+	v1.POST("/users/:id/restore", handler.RestoreUser)
+	v1.POST("/sellers/:id/restore", handler.RestoreSeller)
+	v1.POST("/branches/:id/restore", handler.RestoreBranch)
+	v1.POST("/shops/:id/restore", handler.RestoreShop)
+
+	// Annotated user_service RPCs at their annotated paths, e.g. GET /v1/shops/:id.
+	if err := handler.RegisterTranscodedRoutes(r); err != nil {
+		cnf.Logger.Fatal("failed to register transcoded routes", logger.Error(err))
+	}
+
+	admin := v1.Group("/admin")
+	admin.DELETE("/customers/:id", handler.PurgeCustomer)
+	admin.DELETE("/users/:id", handler.PurgeUser)
+	admin.DELETE("/sellers/:id", handler.PurgeSeller)
+	admin.DELETE("/branches/:id", handler.PurgeBranch)
+	admin.DELETE("/shops/:id", handler.PurgeShop)
+
+	r.GET("/admin/audit", handler.GetAuditLog)
+
+	r.POST("/admin/webhooks", handler.CreateWebhook)
+	r.GET("/admin/webhooks", handler.ListWebhooks)
+	r.GET("/admin/webhooks/:id", handler.GetWebhook)
+	r.PUT("/admin/webhooks/:id", handler.UpdateWebhook)
+	r.DELETE("/admin/webhooks/:id", handler.DeleteWebhook)
+	r.GET("/admin/webhooks/:id/deliveries", handler.ListWebhookDeliveries)
+	r.GET("/admin/webhook-deliveries/:id", handler.GetWebhookDelivery)
+	r.POST("/admin/webhook-deliveries/:id/redeliver", handler.RedeliverWebhook)
+
+	r.GET("/debug/vars", handler.DebugVars)
+
+	r.GET("/jobs/:id", handler.GetJob)
+	r.POST("/jobs/:id/cancel", handler.CancelJob)
+	r.GET("/jobs/:id/download", handler.DownloadJob)
+
 	// Shipper endpoints
 	url := ginSwagger.URL("swagger/doc.json") // The url pointing to API definition
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
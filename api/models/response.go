@@ -1,5 +1,12 @@
 package models
 
+import (
+	"encoding/json"
+	"microservice/genproto/user_service"
+	"microservice/pkg/audit"
+	"microservice/pkg/webhook"
+)
+
 // ResponseSuccess ...
 type ResponseSuccess struct {
 	Metadata interface{}
@@ -44,3 +51,129 @@ type ErrorReason struct {
 type ResponseResult struct {
 	Result string `json:"result"`
 }
+
+// BulkItemResult is the outcome of one item of a bulk request. Index is the
+// item's position in the request.
+type BulkItemResult struct {
+	Index   int                   `json:"index"`
+	Success bool                  `json:"success"`
+	ID      string                `json:"id,omitempty"`
+	Error   *ErrorWithDescription `json:"error,omitempty"`
+}
+
+// BulkResponse ...
+type BulkResponse struct {
+	Atomic    bool             `json:"atomic"`
+	Succeeded int              `json:"succeeded"`
+	Failed    int              `json:"failed"`
+	Results   []BulkItemResult `json:"results"`
+}
+
+// ImportRow is the validation or creation outcome of one CSV row. Row is the
+// line number in the file, the header being line 1.
+type ImportRow struct {
+	Row    int      `json:"row"`
+	Valid  bool     `json:"valid"`
+	ID     string   `json:"id,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReport ...
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Total   int         `json:"total"`
+	Valid   int         `json:"valid"`
+	Invalid int         `json:"invalid"`
+	Rows    []ImportRow `json:"rows"`
+	JobID   string      `json:"job_id,omitempty"`
+}
+
+// SlugAvailability tells whether a slug is free and proposes free variants
+// when it is not.
+type SlugAvailability struct {
+	Slug        string   `json:"slug"`
+	Available   bool     `json:"available"`
+	Suggestions []string `json:"suggestions"`
+}
+
+// ShopDetailSection is one upstream response of a ShopDetail. Data is the
+// protojson encoded response; when the call failed Error is set instead.
+type ShopDetailSection struct {
+	Data  json.RawMessage       `json:"data,omitempty" swaggertype:"object"`
+	Error *ErrorWithDescription `json:"error,omitempty"`
+}
+
+// ShopDetail is a shop with its sellers and branches. Partial is set when
+// some section failed.
+type ShopDetail struct {
+	ID       string            `json:"id"`
+	Partial  bool              `json:"partial"`
+	Shop     ShopDetailSection `json:"shop"`
+	Sellers  ShopDetailSection `json:"sellers"`
+	Branches ShopDetailSection `json:"branches"`
+}
+
+// ShopDependents is the 409 answer to deleting a shop that still has
+// sellers or branches. The ID lists hold at most one page.
+type ShopDependents struct {
+	Code        int      `json:"code"`
+	Description string   `json:"description"`
+	ShopID      string   `json:"shop_id"`
+	SellerCount int64    `json:"seller_count"`
+	SellerIDs   []string `json:"seller_ids"`
+	BranchCount int64    `json:"branch_count"`
+	BranchIDs   []string `json:"branch_ids"`
+}
+
+// WebhookRequest registers or replaces a webhook endpoint. Active defaults
+// to true; an empty secret is generated on create and kept on update.
+type WebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookList ...
+type WebhookList struct {
+	Endpoints []webhook.Endpoint `json:"endpoints"`
+}
+
+// WebhookDeliveries is a page of deliveries, newest first.
+type WebhookDeliveries struct {
+	Deliveries []webhook.Delivery `json:"deliveries"`
+}
+
+// AuditLog is a page of audit entries, newest first.
+type AuditLog struct {
+	Entries []audit.Entry `json:"entries"`
+}
+
+// OnboardingRequest is a merchant to onboard: a shop, its seller and the
+// seller's system user. The seller's shop_id and the user's role are set by
+// the gateway.
+type OnboardingRequest struct {
+	Shop   *user_service.CreateShop   `json:"shop"`
+	Seller *user_service.CreateSeller `json:"seller"`
+	User   *user_service.CreateUs     `json:"user"`
+}
+
+// GraphQLRequest is a GraphQL query with its variables.
+type GraphQLRequest struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName,omitempty" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse ...
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError ...
+type GraphQLError struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
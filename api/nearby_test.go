@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+)
+
+func TestNearbyBranches(t *testing.T) {
+	// The index is built a page at a time and past the open_now scan limit.
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.CoalesceReads = false
+		cfg.MaxPageSize = 2
+		cfg.BranchScanLimit = 1
+	})
+	s.branches.Add(&pb.Branch{Name: "far", Coordinates: &pb.GeoPoint{Latitude: 41.3111, Longitude: 69.2797}, Active: true})
+	s.branches.Add(&pb.Branch{Name: "near", Location: "41.3001,69.2401", Active: true})
+	s.branches.Add(&pb.Branch{Name: "closed", Location: "41.3002,69.2402"})
+	s.branches.Add(&pb.Branch{Name: "samarkand", Location: "39.6542,66.9597", Active: true})
+	s.branches.Add(&pb.Branch{Name: "no coordinates", Location: "Chilonzor 7", Active: true})
+
+	var resp struct {
+		Count    int
+		Branches []struct {
+			Name     string
+			Distance float64
+		}
+	}
+	w := s.do("GET", "/branches/nearby?lat=41.3&lng=69.24&radius=10000&active=true", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET = %d %s", w.Code, w.Body)
+	}
+	decode(t, w, &resp)
+	if resp.Count != 2 || len(resp.Branches) != 2 || resp.Branches[0].Name != "near" || resp.Branches[1].Name != "far" {
+		t.Fatalf("nearby = %+v, want near then far", resp)
+	}
+	if d := resp.Branches[1].Distance; d < 3000 || d > 4000 {
+		t.Errorf("distance to far = %v, want about 3.4km", d)
+	}
+
+	for _, query := range []string{"lng=69", "lat=91&lng=69", "lat=41&lng=69&radius=0", "lat=41&lng=69&radius=200000", "lat=41&lng=69&active=maybe"} {
+		if w := s.do("GET", "/branches/nearby?"+query, ""); w.Code != http.StatusBadRequest {
+			t.Errorf("GET ?%s = %d, want 400", query, w.Code)
+		}
+	}
+
+	// The index is rebuilt per CACHE_BRANCH_TTL, not per client request.
+	calls := s.branches.Calls("GetList")
+	s.do("GET", "/branches/nearby?lat=41.3&lng=69.24", "", "Cache-Control", "no-cache")
+	s.do("GET", "/branches/nearby?lat=41.3&lng=69.24", "", "Pragma", "no-cache")
+	if n := s.branches.Calls("GetList"); n != calls {
+		t.Errorf("GetList called %d more times after no-cache requests, want 0", n-calls)
+	}
+}
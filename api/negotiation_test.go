@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	s := newTestServer(t)
+
+	// Protobuf in, protobuf out.
+	body, err := proto.Marshal(&pb.CreateShop{Slug: "tea", NameEn: "Tea", Phone: "+998901234567"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := s.do("POST", "/CreateShop", string(body), "Authorization", admin,
+		"Content-Type", "application/x-protobuf", "Accept", "application/x-protobuf")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/x-protobuf" {
+		t.Fatalf("CreateShop in protobuf = %d %s: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	var shop pb.Shop
+	if err := proto.Unmarshal(w.Body.Bytes(), &shop); err != nil || shop.Id != "1" || shop.NameEn != "Tea" {
+		t.Errorf("CreateShop protobuf response = %v, %v", &shop, err)
+	}
+
+	// MessagePack in, MessagePack out, with numbers as numbers.
+	var (
+		handle codec.MsgpackHandle
+		buf    bytes.Buffer
+	)
+	if err := codec.NewEncoder(&buf, &handle).Encode(map[string]interface{}{"slug": "coffee", "name_en": "Coffee", "phone": "+998901234567"}); err != nil {
+		t.Fatal(err)
+	}
+	w = s.do("POST", "/CreateShop", buf.String(), "Authorization", admin,
+		"Content-Type", "application/msgpack", "Accept", "application/msgpack")
+	if w.Code != http.StatusOK {
+		t.Fatalf("CreateShop in MessagePack = %d: %s", w.Code, w.Body)
+	}
+	var got map[string]interface{}
+	if err := codec.NewDecoder(w.Body, &handle).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if string(got["slug"].([]byte)) != "coffee" {
+		t.Errorf("CreateShop MessagePack response = %v", got)
+	}
+	w = s.do("GET", "/GetListShop", "", "Accept", "application/msgpack")
+	got = nil
+	if err := codec.NewDecoder(w.Body, &handle).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["count"] != int64(2) {
+		t.Errorf("GetListShop MessagePack count = %#v, want int64 2", got["count"])
+	}
+
+	tests := []struct {
+		name    string
+		headers []string
+		want    int
+	}{
+		{"json by default", nil, http.StatusOK},
+		{"json by weight", []string{"Accept", "application/x-protobuf;q=0.5, application/json"}, http.StatusOK},
+		{"unsupported accept", []string{"Accept", "text/csv"}, http.StatusNotAcceptable},
+		{"unsupported content type", []string{"Content-Type", "text/plain"}, http.StatusUnsupportedMediaType},
+		{"invalid protobuf", []string{"Content-Type", "application/x-protobuf"}, http.StatusBadRequest},
+	}
+	for i, tt := range tests {
+		body := fmt.Sprintf(`{"slug":"juice-%d","name_en":"Juice","phone":"+998901234567"}`, i)
+		if w := s.do("POST", "/CreateShop", body, append([]string{"Authorization", admin}, tt.headers...)...); w.Code != tt.want {
+			t.Errorf("%s: CreateShop = %d %s, want %d", tt.name, w.Code, w.Body, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/saga"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const onboarding = `{
+	"shop": {"slug": "corner", "name_en": "Corner", "phone": "+998901234567"},
+	"seller": {"name": "Ann", "phone": "+998901234568", "email": "ann@example.com"},
+	"user": {"name": "Ann", "phone": "+998901234568", "gmail": "ann@gmail.com"}
+}`
+
+func TestOnboarding(t *testing.T) {
+	s := newTestServer(t)
+
+	var created saga.Saga
+	w := s.do("POST", "/v1/onboarding", onboarding, "Authorization", admin)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST = %d %s", w.Code, w.Body)
+	}
+	decode(t, w, &created)
+	seller, _ := s.sellers.Get(created.ResourceID("create_seller"))
+	user, _ := s.users.Get(created.ResourceID("create_user"))
+	if seller.ShopId != created.ResourceID("create_shop") || user.Role != "seller" {
+		t.Errorf("seller %v and user %v, want the seller in shop %s and a seller user", seller, user, created.ResourceID("create_shop"))
+	}
+
+	if w := s.do("GET", "/v1/onboarding/"+created.ID, "", "Authorization", admin); w.Code != http.StatusOK {
+		t.Errorf("GET = %d", w.Code)
+	}
+}
+
+func TestOnboardingCompensates(t *testing.T) {
+	s := newTestServer(t)
+	s.users.Fail("Create", status.Error(codes.InvalidArgument, "phone is taken"))
+
+	var failed saga.Saga
+	w := s.do("POST", "/v1/onboarding", onboarding, "Authorization", admin)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST = %d %s, want 422", w.Code, w.Body)
+	}
+	decode(t, w, &failed)
+	if failed.Status != saga.StatusCompensated || s.shops.Len() != 0 || s.sellers.Len() != 0 {
+		t.Errorf("saga %s left %d shops and %d sellers, want compensated with none", failed.Status, s.shops.Len(), s.sellers.Len())
+	}
+}
+
+// A saga interrupted by a restart is resumed without creating its records
+// twice: each step finds what the interrupted run created.
+func TestOnboardingResumes(t *testing.T) {
+	tests := []struct {
+		name        string
+		interrupted int
+	}{
+		{"in create_shop", 0},
+		{"in create_seller", 1},
+		{"in create_user", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			shop := s.shops.Add(&pb.Shop{Slug: "corner", NameEn: "Corner", Phone: "+998901234567"})
+			ids := []string{shop.Id}
+			if tt.interrupted >= 1 {
+				ids = append(ids, s.sellers.Add(&pb.Seller{Name: "Ann", Phone: "+998901234568", ShopId: shop.Id}).Id)
+			}
+			if tt.interrupted >= 2 {
+				ids = append(ids, s.users.Add(&pb.Us{Name: "Ann", Phone: "+998901234568", Role: "seller"}).Id)
+			}
+
+			old := time.Now().UTC().Add(-time.Hour).Truncate(time.Microsecond)
+			steps := []saga.StepState{{Name: "create_shop"}, {Name: "create_seller"}, {Name: "create_user"}}
+			for i := range steps {
+				steps[i].Status, steps[i].UpdatedAt = saga.StepPending, old
+				switch {
+				case i < tt.interrupted:
+					steps[i].Status, steps[i].ResourceID, steps[i].Attempts = saga.StepDone, ids[i], 1
+				case i == tt.interrupted:
+					steps[i].Status, steps[i].Attempts = saga.StepRunning, 1
+				}
+			}
+			err := s.sagaStore.Create(context.Background(), saga.Saga{
+				ID: "interrupted", Type: "seller_onboarding", Status: saga.StatusRunning,
+				Input: []byte(onboarding), Steps: steps, CreatedAt: old, UpdatedAt: old,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.sagas.Recover(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			resumed, _ := s.sagas.Get(context.Background(), "interrupted")
+			if resumed.Status != saga.StatusSucceeded || resumed.ResourceID("create_shop") != shop.Id {
+				t.Fatalf("resumed saga = %+v, want succeeded with shop %s", resumed, shop.Id)
+			}
+			if s.shops.Len() != 1 || s.sellers.Len() != 1 || s.users.Len() != 1 {
+				t.Errorf("%d shops, %d sellers and %d users, want one of each", s.shops.Len(), s.sellers.Len(), s.users.Len())
+			}
+		})
+	}
+}
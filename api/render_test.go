@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/config"
+	pb "microservice/genproto/user_service"
+)
+
+func TestRenderOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*config.Config)
+		contains  []string
+		excludes  []string
+	}{
+		{
+			name:      "defaults",
+			configure: func(*config.Config) {},
+			contains:  []string{`"name_en":"Tea"`, `"active":false`, `"count":1`, `"deleted_at":0`},
+		},
+		{
+			name: "lowerCamel, sparse, int64 as string",
+			configure: func(cfg *config.Config) {
+				cfg.JSONUseProtoNames = false
+				cfg.JSONEmitUnpopulated = false
+				cfg.JSONInt64AsString = true
+			},
+			contains: []string{`"nameEn":"Tea"`, `"count":"1"`},
+			excludes: []string{`"name_en"`, `"active"`, `"deletedAt"`},
+		},
+	}
+
+	for _, tt := range tests {
+		s := newTestServer(t, tt.configure)
+		s.shops.Add(&pb.Shop{Slug: "tea", NameEn: "Tea"})
+		s.branches.Add(&pb.Branch{Name: "A", ShopId: "1"})
+
+		var body string
+		for _, path := range []string{"/GetByIdShop/1", "/GetListBranch", "/getbyidbranch/1"} {
+			w := s.do("GET", path, "", "Authorization", admin)
+			if w.Code != http.StatusOK {
+				t.Fatalf("%s: GET %s = %d %s", tt.name, path, w.Code, w.Body)
+			}
+			body += w.Body.String()
+		}
+		for _, want := range tt.contains {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: responses %s lack %s", tt.name, body, want)
+			}
+		}
+		for _, unwanted := range tt.excludes {
+			if strings.Contains(body, unwanted) {
+				t.Errorf("%s: responses %s have %s", tt.name, body, unwanted)
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"microservice/api/models"
+	pb "microservice/genproto/user_service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestShopFull(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "tea", NameEn: "Tea", NameRu: "Чай"})
+	s.shops.Add(&pb.Shop{Slug: "other"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.sellers.Add(&pb.Seller{Name: "B", ShopId: "2"})
+	s.branches.Add(&pb.Branch{Name: "A", ShopId: "1", OpenTime: "00:00", CloseTime: "00:00"})
+
+	var doc models.ShopDetail
+	w := s.do("GET", "/v1/shops/1/full?lang=ru", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /v1/shops/1/full = %d %s", w.Code, w.Body)
+	}
+	decode(t, w, &doc)
+	if doc.Partial || doc.Shop.Error != nil || !strings.Contains(string(doc.Shop.Data), `"name":"Чай"`) ||
+		!strings.Contains(string(doc.Sellers.Data), `"count":1`) || strings.Contains(string(doc.Sellers.Data), `"name":"B"`) ||
+		!strings.Contains(string(doc.Branches.Data), `"is_open_now":true`) {
+		t.Errorf("shop detail = %s", w.Body)
+	}
+
+	// A failing section is marked and the rest is still served.
+	s.sellers.Fail("GetList", status.Error(codes.Unavailable, "down"))
+	doc = models.ShopDetail{}
+	w = s.do("GET", "/v1/shops/1/full", "")
+	decode(t, w, &doc)
+	if w.Code != http.StatusOK || !doc.Partial || doc.Sellers.Error == nil || doc.Sellers.Error.Code != http.StatusInternalServerError ||
+		doc.Sellers.Data != nil || doc.Shop.Data == nil || doc.Branches.Data == nil {
+		t.Errorf("shop detail with sellers down = %d %s", w.Code, w.Body)
+	}
+
+	// Without the shop there is no document. no-cache skips the cached shop
+	// and branches.
+	if w := s.do("GET", "/v1/shops/9/full", ""); w.Code != http.StatusNotFound {
+		t.Errorf("detail of an unknown shop = %d %s, want 404", w.Code, w.Body)
+	}
+	s.shops.Fail("GetByID", status.Error(codes.Unavailable, "down"))
+	s.branches.Fail("GetList", status.Error(codes.Unavailable, "down"))
+	if w := s.do("GET", "/v1/shops/1/full", "", "Cache-Control", "no-cache"); w.Code != http.StatusInternalServerError {
+		t.Errorf("detail with every section down = %d %s, want 500", w.Code, w.Body)
+	}
+	if w := s.do("GET", "/v1/shops/1/full?lang=de", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("detail with an unknown lang = %d, want 400", w.Code)
+	}
+}
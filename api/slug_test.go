@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"microservice/api/models"
+	pb "microservice/genproto/user_service"
+)
+
+func TestShopSlugs(t *testing.T) {
+	s := newTestServer(t)
+	long := strings.Repeat("a", 61) + "-bc"
+	s.shops.Add(&pb.Shop{Slug: "coffee", NameEn: "Coffee"})
+	s.shops.Add(&pb.Shop{Slug: "coffee-2", NameEn: "Coffee"})
+	s.shops.Add(&pb.Shop{Slug: long, NameEn: "Long"})
+
+	if w := s.do("GET", "/GetBySlugShop/coffee-2", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":"2"`) {
+		t.Errorf("GetBySlugShop = %d: %s", w.Code, w.Body)
+	}
+	if w := s.do("GET", "/GetBySlugShop/tea", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GetBySlugShop of a free slug = %d, want 404", w.Code)
+	}
+
+	tests := []struct {
+		query string
+		want  models.SlugAvailability
+	}{
+		{"slug=tea", models.SlugAvailability{Slug: "tea", Available: true, Suggestions: []string{}}},
+		{"name=Coffee", models.SlugAvailability{Slug: "coffee", Suggestions: []string{"coffee-3", "coffee-4", "coffee-5", "coffee-6", "coffee-7"}}},
+		{"slug=coffee&id=1", models.SlugAvailability{Slug: "coffee", Available: true, Suggestions: []string{}}},
+		{"slug=" + long, models.SlugAvailability{Slug: long, Suggestions: []string{
+			strings.Repeat("a", 61) + "-2", strings.Repeat("a", 61) + "-3", strings.Repeat("a", 61) + "-4",
+			strings.Repeat("a", 61) + "-5", strings.Repeat("a", 61) + "-6",
+		}}},
+	}
+	for _, tt := range tests {
+		w := s.do("GET", "/SuggestShopSlug?"+tt.query, "")
+		var got models.SlugAvailability
+		decode(t, w, &got)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("SuggestShopSlug?%s = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+
+	if w := s.do("GET", "/SuggestShopSlug?slug=Bad--Slug", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("SuggestShopSlug with an invalid slug = %d, want 400", w.Code)
+	}
+
+	w := s.do("POST", "/CreateShop", `{"slug":"coffee","name_en":"Coffee","phone":"+998901234567"}`, "Authorization", admin)
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "coffee-3") {
+		t.Errorf("CreateShop with a taken slug = %d: %s", w.Code, w.Body)
+	}
+	w = s.do("POST", "/CreateShop", `{"name_en":"Tea House","phone":"+998901234567"}`, "Authorization", admin)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"slug":"tea-house"`) {
+		t.Errorf("CreateShop without a slug = %d: %s", w.Code, w.Body)
+	}
+}
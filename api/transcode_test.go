@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+	"microservice/pkg/grpc_client"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+func TestAnnotatedRoutes(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "coffee", NameEn: "Coffee", Phone: "+998901234567"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1"})
+	s.branches.Add(&pb.Branch{Name: "A", ShopId: "1", Location: "41.3001,69.2401", OpenTime: "09:00", CloseTime: "18:00"})
+
+	// Reads get the same response and ETag at both paths.
+	for annotated, handwritten := range map[string]string{
+		"/v1/shops/1":           "/GetByIdShop/1",
+		"/v1/shops/slug/coffee": "/GetBySlugShop/coffee",
+		"/v1/branches/1":        "/getbyidbranch/1",
+		"/v1/sellers/1":         "/GetByIdSeller/1",
+	} {
+		got, want := s.do("GET", annotated, "", "Authorization", admin), s.do("GET", handwritten, "", "Authorization", admin)
+		if got.Code != http.StatusOK || got.Body.String() != want.Body.String() || got.Header().Get("ETag") != want.Header().Get("ETag") {
+			t.Errorf("GET %s = %d %s with ETag %q, want %s from %s with ETag %q",
+				annotated, got.Code, got.Body, got.Header().Get("ETag"), want.Body, handwritten, want.Header().Get("ETag"))
+		}
+	}
+
+	// Writes get the checks of the hand-written handlers.
+	tests := []struct {
+		name, method, path, body string
+		headers                  []string
+		want                     int
+		contains                 string
+	}{
+		{"slug generated", "POST", "/v1/shops", `{"name_en":"Tea House","phone":"+998901234567"}`, nil, http.StatusOK, `"slug":"tea-house"`},
+		{"slug taken", "POST", "/v1/shops", `{"slug":"coffee","name_en":"Coffee","phone":"+998901234567"}`, nil, http.StatusConflict, "coffee-2"},
+		{"stale if-match", "PUT", "/v1/shops/1", `{"slug":"coffee","name_en":"B","phone":"+998901234567"}`, []string{"If-Match", `"MjAyMC0wMS0wMSAwMDowMDowMA"`}, http.StatusPreconditionFailed, ""},
+		{"unknown shop", "POST", "/v1/sellers", `{"name":"B","phone":"+998901234567","email":"b@example.com","shop_id":"9"}`, nil, http.StatusBadRequest, ""},
+		{"invalid hours", "POST", "/v1/branches", `{"name":"B","phone":"+998901234567","shop_id":"1","open_time":"25:00","close_time":"18:00"}`, nil, http.StatusBadRequest, ""},
+		{"invalid coordinates", "PUT", "/v1/branches/1", `{"name":"B","phone":"+998901234567","shop_id":"1","coordinates":{"latitude":100,"longitude":0}}`, nil, http.StatusBadRequest, ""},
+		{"shop with dependents", "DELETE", "/v1/shops/1", "", nil, http.StatusConflict, `"seller_count":1`},
+	}
+	for _, tt := range tests {
+		w := s.do(tt.method, tt.path, tt.body, append([]string{"Authorization", admin}, tt.headers...)...)
+		if w.Code != tt.want || !strings.Contains(w.Body.String(), tt.contains) {
+			t.Errorf("%s: %s %s = %d %s, want %d with %s", tt.name, tt.method, tt.path, w.Code, w.Body, tt.want, tt.contains)
+		}
+	}
+	if w := s.do("DELETE", "/v1/shops/1?cascade=true", "", "Authorization", admin); w.Code != http.StatusOK || s.sellers.Len() != 0 || s.branches.Len() != 0 {
+		t.Errorf("DELETE /v1/shops/1?cascade=true = %d %s, left %d sellers and %d branches", w.Code, w.Body, s.sellers.Len(), s.branches.Len())
+	}
+}
+
+func TestInvoke(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "coffee"})
+
+	client, err := grpc_client.New(s.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	method := func(name protoreflect.FullName) protoreflect.MethodDescriptor {
+		d, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d.(protoreflect.MethodDescriptor)
+	}
+
+	resp, err := client.Invoke(context.Background(), method("user_service.ShopService.GetBySlug"), &pb.ShopSlug{Slug: "coffee"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shop, ok := resp.(*pb.Shop); !ok || shop.Id != "1" {
+		t.Errorf("GetBySlug = %T %v, want shop 1", resp, resp)
+	}
+	if _, err := client.Invoke(context.Background(), method("user_service.ShopService.GetByID"), &pb.ShopPrimaryKey{Id: "9"}); err == nil {
+		t.Error("GetByID of an unknown shop succeeded")
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+)
+
+func TestListDeleted(t *testing.T) {
+	s := newTestServer(t)
+	s.sellers.Add(&pb.Seller{Name: "kept", ShopId: "1"})
+	s.sellers.Add(&pb.Seller{Name: "trashed", ShopId: "1", DeletedAt: 1767225600})
+
+	tests := []struct {
+		name  string
+		query string
+		auth  string
+		code  int
+		count int
+	}{
+		{"default", "", "", http.StatusOK, 1},
+		{"include anonymous", "include_deleted=true", "", http.StatusUnauthorized, 0},
+		{"only as seller", "only_deleted=1", seller, http.StatusForbidden, 0},
+		{"include as admin", "include_deleted=true", admin, http.StatusOK, 2},
+		{"only as admin", "only_deleted=true", admin, http.StatusOK, 1},
+		{"false is not a trash request", "include_deleted=false", "", http.StatusOK, 1},
+	}
+	for _, tt := range tests {
+		var headers []string
+		if tt.auth != "" {
+			headers = []string{"Authorization", tt.auth}
+		}
+		w := s.do("GET", "/GetListSeller?"+tt.query, "", headers...)
+		if w.Code != tt.code {
+			t.Errorf("%s: GET = %d %s, want %d", tt.name, w.Code, w.Body, tt.code)
+			continue
+		}
+		if w.Code == http.StatusOK {
+			var resp struct{ Count int }
+			decode(t, w, &resp)
+			if resp.Count != tt.count {
+				t.Errorf("%s: count = %d, want %d", tt.name, resp.Count, tt.count)
+			}
+		}
+	}
+
+	query := `{"query":"{ sellers(only_deleted: true) { count } }"}`
+	if w := s.do("POST", "/graphql", query, "Authorization", seller); !strings.Contains(w.Body.String(), "can list deleted records") {
+		t.Errorf("GraphQL trash as seller = %s, want an error", w.Body)
+	}
+	if w := s.do("POST", "/graphql", query, "Authorization", admin); !strings.Contains(w.Body.String(), `"count":1`) {
+		t.Errorf("GraphQL trash as admin = %s, want count 1", w.Body)
+	}
+}
+
+func TestRestoreSellerChecksShop(t *testing.T) {
+	s := newTestServer(t)
+	s.shops.Add(&pb.Shop{Slug: "open"})
+	s.shops.Add(&pb.Shop{Slug: "closed", DeletedAt: "2026-01-01 00:00:00"})
+	s.sellers.Add(&pb.Seller{Name: "A", ShopId: "1", DeletedAt: 1767225600})
+	s.sellers.Add(&pb.Seller{Name: "B", ShopId: "2", DeletedAt: 1767225600})
+
+	if w := s.do("POST", "/v1/sellers/1/restore", "", "Authorization", admin); w.Code != http.StatusOK {
+		t.Errorf("restore seller of an open shop = %d %s, want 200", w.Code, w.Body)
+	}
+
+	w := s.do("POST", "/v1/sellers/2/restore", "", "Authorization", admin)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "does not exist") {
+		t.Errorf("restore seller of a deleted shop = %d %s, want 400", w.Code, w.Body)
+	}
+	if b, _ := s.sellers.Get("2"); b.DeletedAt == 0 {
+		t.Error("seller of a deleted shop was left restored")
+	}
+}
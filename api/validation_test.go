@@ -0,0 +1,30 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestInvalidEmail(t *testing.T) {
+	s := newTestServer(t)
+
+	tests := []struct {
+		method, path, body string
+		calls              func(method string) int
+	}{
+		{"POST", "/createCustomer", `{"phone":"+998901234567","email":"ann"}`, s.customers.Calls},
+		{"PUT", "/updateCustomer/1", `{"phone":"+998901234567","email":"ann"}`, s.customers.Calls},
+		{"POST", "/CreateSeller", `{"phone":"+998901234567","email":"ann","shop_id":"1"}`, s.sellers.Calls},
+		{"PUT", "/UpdateSeller/1", `{"phone":"+998901234567","email":"ann","shop_id":"1"}`, s.sellers.Calls},
+		{"POST", "/CreateUser", `{"phone":"+998901234567","gmail":"ann"}`, s.users.Calls},
+		{"PUT", "/UpdateUser/1", `{"phone":"+998901234567","gmail":"ann"}`, s.users.Calls},
+	}
+	for _, tt := range tests {
+		if w := s.do(tt.method, tt.path, tt.body, "Authorization", admin); w.Code != http.StatusBadRequest {
+			t.Errorf("%s %s = %d %s, want 400", tt.method, tt.path, w.Code, w.Body)
+		}
+		if n := tt.calls("Create") + tt.calls("Update"); n != 0 {
+			t.Errorf("%s %s reached the user service %d times", tt.method, tt.path, n)
+		}
+	}
+}
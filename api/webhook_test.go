@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"microservice/pkg/webhook"
+)
+
+// webhookReceiver collects the events delivered to it whose signature
+// checks out with secret.
+type webhookReceiver struct {
+	*httptest.Server
+	secret string
+
+	mu     sync.Mutex
+	events []webhook.Event
+	forged int
+}
+
+func newWebhookReceiver(t *testing.T, secret string) *webhookReceiver {
+	r := &webhookReceiver{secret: secret}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		unix, _ := strconv.ParseInt(req.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		valid := hmac.Equal([]byte(req.Header.Get(webhook.HeaderSignature)), []byte(webhook.Sign(r.secret, time.Unix(unix, 0), body)))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if !valid {
+			r.forged++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var ev webhook.Event
+		json.Unmarshal(body, &ev)
+		r.events = append(r.events, ev)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *webhookReceiver) received() ([]webhook.Event, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhook.Event(nil), r.events...), r.forged
+}
+
+func TestWebhooks(t *testing.T) {
+	s := newTestServer(t)
+	r := newWebhookReceiver(t, "whsec_test")
+
+	if w := s.do("POST", "/admin/webhooks", `{"url":"`+r.URL+`","events":["shop.*"]}`, "Authorization", seller); w.Code != http.StatusForbidden {
+		t.Errorf("register as seller = %d, want 403", w.Code)
+	}
+	var endpoint webhook.Endpoint
+	w := s.do("POST", "/admin/webhooks", `{"url":"`+r.URL+`","events":["shop.*"],"secret":"whsec_test"}`, "Authorization", admin)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("register = %d %s", w.Code, w.Body)
+	}
+	decode(t, w, &endpoint)
+
+	// A retry with the same Idempotency-Key is replayed without publishing
+	// the change or auditing it again.
+	body := `{"slug":"corner","name_en":"Corner","phone":"+998901234567"}`
+	for i := 0; i < 2; i++ {
+		if w := s.do("POST", "/CreateShop", body, "Authorization", admin, "Idempotency-Key", "shop-1"); w.Code != http.StatusOK {
+			t.Fatalf("CreateShop = %d %s", w.Code, w.Body)
+		}
+	}
+	s.do("POST", "/createCustomer", `{"phone":"+998901234567","email":"a@example.com","firstname":"Ann"}`, "Authorization", admin)
+
+	eventually(t, func() bool { events, _ := r.received(); return len(events) > 0 }, "no shop event delivered")
+	time.Sleep(50 * time.Millisecond)
+	events, forged := r.received()
+	if len(events) != 1 || events[0].Type != "shop.created" || forged != 0 {
+		t.Fatalf("received %+v and %d forged, want one shop.created", events, forged)
+	}
+	audited := 0
+	for _, e := range s.auditEntries() {
+		if e.Route == "/CreateShop" {
+			audited++
+		}
+	}
+	if audited != 1 {
+		t.Errorf("%d audit entries for CreateShop, want 1", audited)
+	}
+
+	deliveries, _ := s.webhooks.Store().ListDeliveries(context.Background(), endpoint.ID, 10, 0)
+	if len(deliveries) != 1 {
+		t.Fatalf("%d deliveries, want 1", len(deliveries))
+	}
+	eventually(t, func() bool {
+		d, _ := s.webhooks.Store().GetDelivery(context.Background(), deliveries[0].ID)
+		return d.Status == webhook.DeliverySucceeded
+	}, "delivery did not succeed")
+
+	if w := s.do("POST", "/admin/webhook-deliveries/"+deliveries[0].ID+"/redeliver", "", "Authorization", admin); w.Code >= 300 {
+		t.Fatalf("redeliver = %d %s", w.Code, w.Body)
+	}
+	eventually(t, func() bool { events, _ := r.received(); return len(events) == 2 }, "redelivery not received")
+	if events, _ := r.received(); events[1].ID != events[0].ID {
+		t.Errorf("redelivered event %s, want %s again", events[1].ID, events[0].ID)
+	}
+	if w := s.do("POST", "/admin/webhook-deliveries/missing/redeliver", "", "Authorization", admin); w.Code != http.StatusNotFound {
+		t.Errorf("redeliver missing = %d, want 404", w.Code)
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+func TestWebhookRandomSourceFails(t *testing.T) {
+	s := newTestServer(t)
+
+	reader := rand.Reader
+	rand.Reader = failingReader{}
+	w := s.do("POST", "/admin/webhooks", `{"url":"http://example.com","events":["shop.*"]}`, "Authorization", admin, "X-Request-ID", "req-1")
+	rand.Reader = reader
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("register with a failing random source = %d %s, want 500", w.Code, w.Body)
+	}
+
+	var list struct{ Endpoints []webhook.Endpoint }
+	decode(t, s.do("GET", "/admin/webhooks", "", "Authorization", admin), &list)
+	if len(list.Endpoints) != 0 {
+		t.Errorf("%d webhooks registered, want none", len(list.Endpoints))
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"microservice/config"
+	"microservice/pkg/events"
+
+	"github.com/gorilla/websocket"
+)
+
+// wsReply is a server message on /v1/events/ws.
+type wsReply struct {
+	Type          string        `json:"type"`
+	ID            string        `json:"id"`
+	Subscriptions []string      `json:"subscriptions"`
+	Event         *events.Event `json:"event"`
+	Error         string        `json:"error"`
+}
+
+func dialEvents(t *testing.T, url, authorization string) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+
+	header := http.Header{}
+	if authorization != "" {
+		header.Set("Authorization", authorization)
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(url, "http")+"/v1/events/ws", header)
+	if err == nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// send writes msg and returns the next message the server sends.
+func send(t *testing.T, conn *websocket.Conn, msg string) wsReply {
+	t.Helper()
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	return receive(t, conn)
+}
+
+func receive(t *testing.T, conn *websocket.Conn) wsReply {
+	t.Helper()
+
+	var reply wsReply
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatal(err)
+	}
+	return reply
+}
+
+func TestEventsWebSocket(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.WSMaxSubscriptions = 2
+	})
+	srv := httptest.NewServer(s.router)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := dialEvents(t, srv.URL, seller)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		msg  string
+		want wsReply
+	}{
+		{`{"type":"ping","id":"p"}`, wsReply{Type: "pong", ID: "p"}},
+		{`{"type":"subscribe","id":"shop","events":["shop.*"],"shop_id":"1"}`, wsReply{Type: "subscribed", ID: "shop"}},
+		{`{"type":"subscribe","id":"sellers","events":["seller.created"]}`, wsReply{Type: "subscribed", ID: "sellers"}},
+		{`{"type":"subscribe","id":"more"}`, wsReply{Type: "error", ID: "more", Error: "at most 2 subscriptions are allowed"}},
+		{`{"type":"subscribe"}`, wsReply{Type: "error", Error: "id is required"}},
+		{`{"type":"unsubscribe","id":"nope"}`, wsReply{Type: "error", ID: "nope", Error: "unknown subscription"}},
+		{`{"type":"shout","id":"x"}`, wsReply{Type: "error", ID: "x", Error: `unknown message type "shout"`}},
+	}
+	for _, tt := range tests {
+		if got := send(t, conn, tt.msg); got.Type != tt.want.Type || got.ID != tt.want.ID || got.Error != tt.want.Error {
+			t.Errorf("%s: got %+v, want %+v", tt.msg, got, tt.want)
+		}
+	}
+	if got := send(t, conn, `{`); got.Type != "error" || !strings.HasPrefix(got.Error, "invalid message: ") {
+		t.Errorf("invalid JSON: got %+v, want an error", got)
+	}
+	eventually(t, func() bool { return s.events.Subscribers() == 1 }, "no subscriber")
+
+	// Customer events are for admins only, shop 2 is not subscribed to, and
+	// the seller of shop 1 matches both subscriptions.
+	s.do("POST", "/createCustomer", `{"phone":"+998901234567","email":"a@example.com"}`, "Authorization", admin)
+	s.do("POST", "/CreateShop", `{"slug":"tea","name_en":"Tea","phone":"+998901234567"}`, "Authorization", admin)
+	s.do("POST", "/CreateShop", `{"slug":"coffee","name_en":"Coffee","phone":"+998901234567"}`, "Authorization", admin)
+	s.do("POST", "/CreateSeller", `{"name":"A","phone":"+998901234567","email":"a@example.com","shop_id":"1"}`, "Authorization", admin)
+
+	if got := receive(t, conn); got.Type != "event" || got.Event.Type != "shop.created" || got.Event.ResourceID != "1" || strings.Join(got.Subscriptions, ",") != "shop" {
+		t.Errorf("first event = %+v %+v, want shop.created 1 for shop", got, got.Event)
+	}
+	if got := receive(t, conn); got.Type != "event" || got.Event.Type != "seller.created" || strings.Join(got.Subscriptions, ",") != "sellers" {
+		t.Errorf("second event = %+v %+v, want seller.created for sellers", got, got.Event)
+	}
+
+	if got := send(t, conn, `{"type":"unsubscribe","id":"shop"}`); got.Type != "unsubscribed" || got.ID != "shop" {
+		t.Errorf("unsubscribe: got %+v", got)
+	}
+	if got := send(t, conn, `{"type":"subscribe","id":"customers","events":["customer.*"]}`); got.Type != "error" || got.Error != "only admin can subscribe to customer events" {
+		t.Errorf("seller subscribing to customers: got %+v, want an error", got)
+	}
+	if got := send(t, conn, `{"type":"subscribe","id":"bad","events":["shop"]}`); got.Type != "error" {
+		t.Errorf("invalid pattern: got %+v, want an error", got)
+	}
+}
+
+func TestEventsWebSocketLimits(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.WSMaxConnections = 2
+		cfg.WSMaxConnectionsPerUser = 1
+	})
+	srv := httptest.NewServer(s.router)
+	t.Cleanup(srv.Close)
+
+	if _, resp, err := dialEvents(t, srv.URL, ""); err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("anonymous dial = %v, want 401", err)
+	}
+
+	first, _, err := dialEvents(t, srv.URL, seller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, resp, err := dialEvents(t, srv.URL, seller); err == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("second dial by one user = %v, want 429", err)
+	}
+	if _, _, err := dialEvents(t, srv.URL, admin); err != nil {
+		t.Fatal(err)
+	}
+	if _, resp, err := dialEvents(t, srv.URL, bearer("seller-2", "seller")); err == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("dial over the total = %v, want 503", err)
+	}
+
+	// Closing a connection frees its slot.
+	first.Close()
+	eventually(t, func() bool {
+		conn, _, err := dialEvents(t, srv.URL, seller)
+		if err == nil {
+			conn.Close()
+		}
+		return err == nil
+	}, "slot not released")
+}
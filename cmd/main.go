@@ -1,16 +1,35 @@
 package main
 
 import (
-	"microservice/config"
+	"context"
 	"microservice/api"
+	"microservice/config"
+	"microservice/pkg/audit"
+	"microservice/pkg/events"
 	"microservice/pkg/grpc_client"
+	"microservice/pkg/idempotency"
+	"microservice/pkg/jobs"
 	"microservice/pkg/logger"
+	"microservice/pkg/postgres"
+	"microservice/pkg/saga"
+	"microservice/pkg/webhook"
+	"time"
+	_ "time/tzdata" // branch opening hours are evaluated in their own timezone
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
 	log        logger.Logger
 	cfg        config.Config
 	grpcClient *grpc_client.GrpcClient
+	jobManager *jobs.Manager
+	idemStore  idempotency.Store
+	sagas      *saga.Coordinator
+	auditSink  audit.Sink
+	webhooks   *webhook.Dispatcher
+	eventHub   *events.Hub
+	db         *pgxpool.Pool
 )
 
 func initDeps() {
@@ -22,16 +41,100 @@ func initDeps() {
 	if err != nil {
 		log.Error("grpc dial error", logger.Error(err))
 	}
+
+	if cfg.JobStore == "postgres" || cfg.IdempotencyStore == "postgres" || cfg.SagaStore == "postgres" || cfg.AuditSink == "postgres" ||
+		cfg.WebhookStore == "postgres" {
+		db, err = postgres.New(context.Background(), cfg)
+		if err != nil {
+			log.Fatal("postgres connection error", logger.Error(err))
+		}
+	}
+
+	var jobStore jobs.Store = jobs.NewMemoryStore()
+	if cfg.JobStore == "postgres" {
+		jobStore, err = jobs.NewPostgresStore(context.Background(), db)
+		if err != nil {
+			log.Fatal("job store error", logger.Error(err))
+		}
+	}
+
+	jobManager = jobs.NewManager(jobStore, log, jobs.Options{
+		Workers:   cfg.JobWorkers,
+		QueueSize: cfg.JobQueueSize,
+		Retention: cfg.JobRetention,
+		FileDir:   cfg.JobFileDir,
+	})
+
+	idemStore = idempotency.NewMemoryStore()
+	if cfg.IdempotencyStore == "postgres" {
+		idemStore, err = idempotency.NewPostgresStore(context.Background(), db)
+		if err != nil {
+			log.Fatal("idempotency store error", logger.Error(err))
+		}
+	}
+	go idempotency.RunCleanup(idemStore, time.Hour, log)
+
+	var sagaStore saga.Store = saga.NewMemoryStore()
+	if cfg.SagaStore == "postgres" {
+		sagaStore, err = saga.NewPostgresStore(context.Background(), db)
+		if err != nil {
+			log.Fatal("saga store error", logger.Error(err))
+		}
+	}
+
+	sagas = saga.NewCoordinator(sagaStore, log, saga.Options{
+		StepTimeout: cfg.SagaStepTimeout,
+		Retention:   cfg.SagaRetention,
+	})
+
+	switch cfg.AuditSink {
+	case "file":
+		auditSink, err = audit.NewFileSink(cfg.AuditFile)
+	case "postgres":
+		auditSink, err = audit.NewPostgresSink(context.Background(), db)
+	}
+	if err != nil {
+		log.Fatal("audit sink error", logger.Error(err))
+	}
+
+	var webhookStore webhook.Store = webhook.NewMemoryStore()
+	if cfg.WebhookStore == "postgres" {
+		webhookStore, err = webhook.NewPostgresStore(context.Background(), db)
+		if err != nil {
+			log.Fatal("webhook store error", logger.Error(err))
+		}
+	}
+
+	webhooks = webhook.NewDispatcher(webhookStore, log, webhook.Options{
+		Workers:     cfg.WebhookWorkers,
+		QueueSize:   cfg.WebhookQueueSize,
+		Timeout:     cfg.WebhookTimeout,
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		Backoff:     cfg.WebhookBackoff,
+		MaxBackoff:  cfg.WebhookMaxBackoff,
+	})
+
+	eventHub = events.NewHub(cfg.EventBufferSize)
 }
 
 func main() {
 	initDeps()
 
 	server := api.New(api.Config{
-		Logger:     log,
-		GrpcClient: grpcClient,
-		Cfg:        cfg,
+		Logger:      log,
+		GrpcClient:  grpcClient,
+		Cfg:         cfg,
+		Jobs:        jobManager,
+		Idempotency: idemStore,
+		Sagas:       sagas,
+		Audit:       auditSink,
+		Webhooks:    webhooks,
+		Events:      eventHub,
 	})
 
+	// Saga types are registered by api.New, so recovery starts after it.
+	go sagas.RunRecovery(time.Minute)
+	go webhooks.RunRetries(5 * time.Second)
+
 	server.Run(cfg.HTTPPort)
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/cast"
@@ -34,6 +35,78 @@ type Config struct {
 	LogLevel string
 	HTTPPort string
 
+	DefaultPageSize uint64
+	MaxPageSize     uint64
+
+	JSONEmitUnpopulated bool
+	JSONUseProtoNames   bool
+	JSONInt64AsString   bool
+
+	BulkMaxItems    int
+	BulkConcurrency int
+
+	ImportMaxRows int
+
+	JobStore     string // memory, postgres
+	JobWorkers   int
+	JobQueueSize int
+	JobRetention time.Duration
+	JobFileDir   string
+
+	IdempotencyStore string // memory, postgres
+	IdempotencyTTL   time.Duration
+	// IdempotencyMaxBody is the largest request body, in bytes, a request
+	// with an Idempotency-Key may send; the body is hashed and kept in memory.
+	IdempotencyMaxBody int64
+
+	CacheMaxEntries int
+	CacheShopTTL    time.Duration
+	CacheBranchTTL  time.Duration
+
+	CoalesceReads bool
+	// CoalesceTimeout bounds a coalesced read, which outlives the deadlines
+	// of the callers waiting for it; non-positive values disable it.
+	CoalesceTimeout time.Duration
+
+	DefaultTimezone string
+	// BranchScanLimit is how many branches a filter the gateway evaluates
+	// itself, such as open_now, may read; non-positive values disable it.
+	BranchScanLimit int
+
+	ShopDetailTimeout time.Duration
+
+	ShopDeleteCascade bool // default of DeleteShop's cascade parameter
+
+	SagaStore       string // memory, postgres
+	SagaStepTimeout time.Duration
+	SagaRetention   time.Duration
+
+	AuditSink string // file, postgres, none
+	AuditFile string
+
+	WebhookStore       string // memory, postgres
+	WebhookWorkers     int
+	WebhookQueueSize   int
+	WebhookTimeout     time.Duration
+	WebhookMaxAttempts int
+	WebhookBackoff     time.Duration
+	WebhookMaxBackoff  time.Duration
+
+	// EventBufferSize is how many change events /v1/events keeps for
+	// clients resuming with Last-Event-ID.
+	EventBufferSize   int
+	EventHeartbeat    time.Duration
+	EventClientBuffer int
+
+	WSMaxConnections        int
+	WSMaxConnectionsPerUser int
+	WSMaxSubscriptions      int
+
+	// GraphQLMaxDepth and GraphQLMaxComplexity bound the queries /graphql
+	// runs; non-positive values disable the limit.
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
 	PostgresMaxConnections int32
 }
 
@@ -62,6 +135,75 @@ func Load() Config {
 	config.LogLevel = cast.ToString(getOrReturnDefaultValue("LOG_LEVEL", "debug"))
 	config.HTTPPort = cast.ToString(getOrReturnDefaultValue("HTTP_PORT", ":1234"))
 
+	config.DefaultPageSize = cast.ToUint64(getOrReturnDefaultValue("DEFAULT_PAGE_SIZE", 10))
+	config.MaxPageSize = cast.ToUint64(getOrReturnDefaultValue("MAX_PAGE_SIZE", 100))
+	// A zero default would list nothing when limit is not sent; MAX_PAGE_SIZE=0
+	// means no maximum.
+	if config.DefaultPageSize == 0 {
+		config.DefaultPageSize = 10
+	}
+	if config.MaxPageSize > 0 && config.DefaultPageSize > config.MaxPageSize {
+		config.DefaultPageSize = config.MaxPageSize
+	}
+
+	config.JSONEmitUnpopulated = cast.ToBool(getOrReturnDefaultValue("JSON_EMIT_UNPOPULATED", true))
+	config.JSONUseProtoNames = cast.ToBool(getOrReturnDefaultValue("JSON_USE_PROTO_NAMES", true))
+	config.JSONInt64AsString = cast.ToBool(getOrReturnDefaultValue("JSON_INT64_AS_STRING", false))
+
+	config.BulkMaxItems = cast.ToInt(getOrReturnDefaultValue("BULK_MAX_ITEMS", 5000))
+	config.BulkConcurrency = cast.ToInt(getOrReturnDefaultValue("BULK_CONCURRENCY", 8))
+
+	config.ImportMaxRows = cast.ToInt(getOrReturnDefaultValue("IMPORT_MAX_ROWS", 10000))
+
+	config.JobStore = cast.ToString(getOrReturnDefaultValue("JOB_STORE", "memory"))
+	config.JobWorkers = cast.ToInt(getOrReturnDefaultValue("JOB_WORKERS", 4))
+	config.JobQueueSize = cast.ToInt(getOrReturnDefaultValue("JOB_QUEUE_SIZE", 100))
+	config.JobRetention = cast.ToDuration(getOrReturnDefaultValue("JOB_RETENTION", "24h"))
+	config.JobFileDir = cast.ToString(getOrReturnDefaultValue("JOB_FILE_DIR", ""))
+
+	config.IdempotencyStore = cast.ToString(getOrReturnDefaultValue("IDEMPOTENCY_STORE", "memory"))
+	config.IdempotencyTTL = cast.ToDuration(getOrReturnDefaultValue("IDEMPOTENCY_TTL", "24h"))
+	config.IdempotencyMaxBody = cast.ToInt64(getOrReturnDefaultValue("IDEMPOTENCY_MAX_BODY", 10<<20))
+
+	config.CacheMaxEntries = cast.ToInt(getOrReturnDefaultValue("CACHE_MAX_ENTRIES", 10000))
+	config.CacheShopTTL = cast.ToDuration(getOrReturnDefaultValue("CACHE_SHOP_TTL", "5m"))
+	config.CacheBranchTTL = cast.ToDuration(getOrReturnDefaultValue("CACHE_BRANCH_TTL", "1m"))
+
+	config.CoalesceReads = cast.ToBool(getOrReturnDefaultValue("COALESCE_READS", true))
+	config.CoalesceTimeout = cast.ToDuration(getOrReturnDefaultValue("COALESCE_TIMEOUT", "10s"))
+
+	config.DefaultTimezone = cast.ToString(getOrReturnDefaultValue("DEFAULT_TIMEZONE", "Asia/Tashkent"))
+	config.BranchScanLimit = cast.ToInt(getOrReturnDefaultValue("BRANCH_SCAN_LIMIT", 5000))
+
+	config.ShopDetailTimeout = cast.ToDuration(getOrReturnDefaultValue("SHOP_DETAIL_TIMEOUT", "3s"))
+	config.ShopDeleteCascade = cast.ToBool(getOrReturnDefaultValue("SHOP_DELETE_CASCADE", false))
+
+	config.SagaStore = cast.ToString(getOrReturnDefaultValue("SAGA_STORE", "memory"))
+	config.SagaStepTimeout = cast.ToDuration(getOrReturnDefaultValue("SAGA_STEP_TIMEOUT", "30s"))
+	config.SagaRetention = cast.ToDuration(getOrReturnDefaultValue("SAGA_RETENTION", "168h"))
+
+	config.AuditSink = cast.ToString(getOrReturnDefaultValue("AUDIT_SINK", "file"))
+	config.AuditFile = cast.ToString(getOrReturnDefaultValue("AUDIT_FILE", "audit.log"))
+
+	config.WebhookStore = cast.ToString(getOrReturnDefaultValue("WEBHOOK_STORE", "memory"))
+	config.WebhookWorkers = cast.ToInt(getOrReturnDefaultValue("WEBHOOK_WORKERS", 4))
+	config.WebhookQueueSize = cast.ToInt(getOrReturnDefaultValue("WEBHOOK_QUEUE_SIZE", 1000))
+	config.WebhookTimeout = cast.ToDuration(getOrReturnDefaultValue("WEBHOOK_TIMEOUT", "10s"))
+	config.WebhookMaxAttempts = cast.ToInt(getOrReturnDefaultValue("WEBHOOK_MAX_ATTEMPTS", 8))
+	config.WebhookBackoff = cast.ToDuration(getOrReturnDefaultValue("WEBHOOK_BACKOFF", "10s"))
+	config.WebhookMaxBackoff = cast.ToDuration(getOrReturnDefaultValue("WEBHOOK_MAX_BACKOFF", "1h"))
+
+	config.EventBufferSize = cast.ToInt(getOrReturnDefaultValue("EVENT_BUFFER_SIZE", 1000))
+	config.EventHeartbeat = cast.ToDuration(getOrReturnDefaultValue("EVENT_HEARTBEAT", "15s"))
+	config.EventClientBuffer = cast.ToInt(getOrReturnDefaultValue("EVENT_CLIENT_BUFFER", 64))
+
+	config.WSMaxConnections = cast.ToInt(getOrReturnDefaultValue("WS_MAX_CONNECTIONS", 1000))
+	config.WSMaxConnectionsPerUser = cast.ToInt(getOrReturnDefaultValue("WS_MAX_CONNECTIONS_PER_USER", 5))
+	config.WSMaxSubscriptions = cast.ToInt(getOrReturnDefaultValue("WS_MAX_SUBSCRIPTIONS", 50))
+
+	config.GraphQLMaxDepth = cast.ToInt(getOrReturnDefaultValue("GRAPHQL_MAX_DEPTH", 8))
+	config.GraphQLMaxComplexity = cast.ToInt(getOrReturnDefaultValue("GRAPHQL_MAX_COMPLEXITY", 5000))
+
 	return config
 }
 
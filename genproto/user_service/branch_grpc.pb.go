@@ -19,11 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	BranchService_Create_FullMethodName  = "/user_service.BranchService/Create"
-	BranchService_GetByID_FullMethodName = "/user_service.BranchService/GetByID"
-	BranchService_GetList_FullMethodName = "/user_service.BranchService/GetList"
-	BranchService_Update_FullMethodName  = "/user_service.BranchService/Update"
-	BranchService_Delete_FullMethodName  = "/user_service.BranchService/Delete"
+	BranchService_Create_FullMethodName     = "/user_service.BranchService/Create"
+	BranchService_GetByID_FullMethodName    = "/user_service.BranchService/GetByID"
+	BranchService_GetList_FullMethodName    = "/user_service.BranchService/GetList"
+	BranchService_Update_FullMethodName     = "/user_service.BranchService/Update"
+	BranchService_Delete_FullMethodName     = "/user_service.BranchService/Delete"
+	BranchService_Restore_FullMethodName    = "/user_service.BranchService/Restore"
+	BranchService_Purge_FullMethodName      = "/user_service.BranchService/Purge"
+	BranchService_BulkCreate_FullMethodName = "/user_service.BranchService/BulkCreate"
+	BranchService_BulkUpdate_FullMethodName = "/user_service.BranchService/BulkUpdate"
+	BranchService_BulkDelete_FullMethodName = "/user_service.BranchService/BulkDelete"
+	BranchService_GetNearby_FullMethodName  = "/user_service.BranchService/GetNearby"
 )
 
 // BranchServiceClient is the client API for BranchService service.
@@ -35,6 +41,12 @@ type BranchServiceClient interface {
 	GetList(ctx context.Context, in *GetListBranchRequest, opts ...grpc.CallOption) (*GetListBranchResponse, error)
 	Update(ctx context.Context, in *UpdateBranch, opts ...grpc.CallOption) (*Branch, error)
 	Delete(ctx context.Context, in *BranchPrimaryKey, opts ...grpc.CallOption) (*Empty3, error)
+	Restore(ctx context.Context, in *BranchPrimaryKey, opts ...grpc.CallOption) (*Branch, error)
+	Purge(ctx context.Context, in *BranchPrimaryKey, opts ...grpc.CallOption) (*Empty3, error)
+	BulkCreate(ctx context.Context, in *BulkCreateBranchRequest, opts ...grpc.CallOption) (*BulkBranchResponse, error)
+	BulkUpdate(ctx context.Context, in *BulkUpdateBranchRequest, opts ...grpc.CallOption) (*BulkBranchResponse, error)
+	BulkDelete(ctx context.Context, in *BulkDeleteBranchRequest, opts ...grpc.CallOption) (*Empty3, error)
+	GetNearby(ctx context.Context, in *GetNearbyBranchRequest, opts ...grpc.CallOption) (*GetListBranchResponse, error)
 }
 
 type branchServiceClient struct {
@@ -90,6 +102,60 @@ func (c *branchServiceClient) Delete(ctx context.Context, in *BranchPrimaryKey,
 	return out, nil
 }
 
+func (c *branchServiceClient) Restore(ctx context.Context, in *BranchPrimaryKey, opts ...grpc.CallOption) (*Branch, error) {
+	out := new(Branch)
+	err := c.cc.Invoke(ctx, BranchService_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *branchServiceClient) Purge(ctx context.Context, in *BranchPrimaryKey, opts ...grpc.CallOption) (*Empty3, error) {
+	out := new(Empty3)
+	err := c.cc.Invoke(ctx, BranchService_Purge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *branchServiceClient) BulkCreate(ctx context.Context, in *BulkCreateBranchRequest, opts ...grpc.CallOption) (*BulkBranchResponse, error) {
+	out := new(BulkBranchResponse)
+	err := c.cc.Invoke(ctx, BranchService_BulkCreate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *branchServiceClient) BulkUpdate(ctx context.Context, in *BulkUpdateBranchRequest, opts ...grpc.CallOption) (*BulkBranchResponse, error) {
+	out := new(BulkBranchResponse)
+	err := c.cc.Invoke(ctx, BranchService_BulkUpdate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *branchServiceClient) BulkDelete(ctx context.Context, in *BulkDeleteBranchRequest, opts ...grpc.CallOption) (*Empty3, error) {
+	out := new(Empty3)
+	err := c.cc.Invoke(ctx, BranchService_BulkDelete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *branchServiceClient) GetNearby(ctx context.Context, in *GetNearbyBranchRequest, opts ...grpc.CallOption) (*GetListBranchResponse, error) {
+	out := new(GetListBranchResponse)
+	err := c.cc.Invoke(ctx, BranchService_GetNearby_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BranchServiceServer is the server API for BranchService service.
 // All implementations should embed UnimplementedBranchServiceServer
 // for forward compatibility
@@ -99,6 +165,12 @@ type BranchServiceServer interface {
 	GetList(context.Context, *GetListBranchRequest) (*GetListBranchResponse, error)
 	Update(context.Context, *UpdateBranch) (*Branch, error)
 	Delete(context.Context, *BranchPrimaryKey) (*Empty3, error)
+	Restore(context.Context, *BranchPrimaryKey) (*Branch, error)
+	Purge(context.Context, *BranchPrimaryKey) (*Empty3, error)
+	BulkCreate(context.Context, *BulkCreateBranchRequest) (*BulkBranchResponse, error)
+	BulkUpdate(context.Context, *BulkUpdateBranchRequest) (*BulkBranchResponse, error)
+	BulkDelete(context.Context, *BulkDeleteBranchRequest) (*Empty3, error)
+	GetNearby(context.Context, *GetNearbyBranchRequest) (*GetListBranchResponse, error)
 }
 
 // UnimplementedBranchServiceServer should be embedded to have forward compatible implementations.
@@ -120,6 +192,24 @@ func (UnimplementedBranchServiceServer) Update(context.Context, *UpdateBranch) (
 func (UnimplementedBranchServiceServer) Delete(context.Context, *BranchPrimaryKey) (*Empty3, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedBranchServiceServer) Restore(context.Context, *BranchPrimaryKey) (*Branch, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedBranchServiceServer) Purge(context.Context, *BranchPrimaryKey) (*Empty3, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
+func (UnimplementedBranchServiceServer) BulkCreate(context.Context, *BulkCreateBranchRequest) (*BulkBranchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCreate not implemented")
+}
+func (UnimplementedBranchServiceServer) BulkUpdate(context.Context, *BulkUpdateBranchRequest) (*BulkBranchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkUpdate not implemented")
+}
+func (UnimplementedBranchServiceServer) BulkDelete(context.Context, *BulkDeleteBranchRequest) (*Empty3, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkDelete not implemented")
+}
+func (UnimplementedBranchServiceServer) GetNearby(context.Context, *GetNearbyBranchRequest) (*GetListBranchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNearby not implemented")
+}
 
 // UnsafeBranchServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to BranchServiceServer will
@@ -222,6 +312,114 @@ func _BranchService_Delete_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BranchService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BranchPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BranchServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BranchService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BranchServiceServer).Restore(ctx, req.(*BranchPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BranchService_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BranchPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BranchServiceServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BranchService_Purge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BranchServiceServer).Purge(ctx, req.(*BranchPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BranchService_BulkCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCreateBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BranchServiceServer).BulkCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BranchService_BulkCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BranchServiceServer).BulkCreate(ctx, req.(*BulkCreateBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BranchService_BulkUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkUpdateBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BranchServiceServer).BulkUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BranchService_BulkUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BranchServiceServer).BulkUpdate(ctx, req.(*BulkUpdateBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BranchService_BulkDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkDeleteBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BranchServiceServer).BulkDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BranchService_BulkDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BranchServiceServer).BulkDelete(ctx, req.(*BulkDeleteBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BranchService_GetNearby_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNearbyBranchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BranchServiceServer).GetNearby(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BranchService_GetNearby_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BranchServiceServer).GetNearby(ctx, req.(*GetNearbyBranchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BranchService_ServiceDesc is the grpc.ServiceDesc for BranchService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -249,6 +447,30 @@ var BranchService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _BranchService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _BranchService_Restore_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _BranchService_Purge_Handler,
+		},
+		{
+			MethodName: "BulkCreate",
+			Handler:    _BranchService_BulkCreate_Handler,
+		},
+		{
+			MethodName: "BulkUpdate",
+			Handler:    _BranchService_BulkUpdate_Handler,
+		},
+		{
+			MethodName: "BulkDelete",
+			Handler:    _BranchService_BulkDelete_Handler,
+		},
+		{
+			MethodName: "GetNearby",
+			Handler:    _BranchService_GetNearby_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "branch.proto",
@@ -19,11 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	CustomerService_Create_FullMethodName  = "/user_service.CustomerService/Create"
-	CustomerService_GetByID_FullMethodName = "/user_service.CustomerService/GetByID"
-	CustomerService_GetList_FullMethodName = "/user_service.CustomerService/GetList"
-	CustomerService_Update_FullMethodName  = "/user_service.CustomerService/Update"
-	CustomerService_Delete_FullMethodName  = "/user_service.CustomerService/Delete"
+	CustomerService_Create_FullMethodName     = "/user_service.CustomerService/Create"
+	CustomerService_GetByID_FullMethodName    = "/user_service.CustomerService/GetByID"
+	CustomerService_GetList_FullMethodName    = "/user_service.CustomerService/GetList"
+	CustomerService_Update_FullMethodName     = "/user_service.CustomerService/Update"
+	CustomerService_Delete_FullMethodName     = "/user_service.CustomerService/Delete"
+	CustomerService_Restore_FullMethodName    = "/user_service.CustomerService/Restore"
+	CustomerService_Purge_FullMethodName      = "/user_service.CustomerService/Purge"
+	CustomerService_BulkCreate_FullMethodName = "/user_service.CustomerService/BulkCreate"
+	CustomerService_BulkUpdate_FullMethodName = "/user_service.CustomerService/BulkUpdate"
+	CustomerService_BulkDelete_FullMethodName = "/user_service.CustomerService/BulkDelete"
 )
 
 // CustomerServiceClient is the client API for CustomerService service.
@@ -35,6 +40,11 @@ type CustomerServiceClient interface {
 	GetList(ctx context.Context, in *GetListCustomerRequest, opts ...grpc.CallOption) (*GetListCustomerResponse, error)
 	Update(ctx context.Context, in *UpdateCustomer, opts ...grpc.CallOption) (*Customer, error)
 	Delete(ctx context.Context, in *CustomerPrimaryKey, opts ...grpc.CallOption) (*Empty, error)
+	Restore(ctx context.Context, in *CustomerPrimaryKey, opts ...grpc.CallOption) (*Customer, error)
+	Purge(ctx context.Context, in *CustomerPrimaryKey, opts ...grpc.CallOption) (*Empty, error)
+	BulkCreate(ctx context.Context, in *BulkCreateCustomerRequest, opts ...grpc.CallOption) (*BulkCustomerResponse, error)
+	BulkUpdate(ctx context.Context, in *BulkUpdateCustomerRequest, opts ...grpc.CallOption) (*BulkCustomerResponse, error)
+	BulkDelete(ctx context.Context, in *BulkDeleteCustomerRequest, opts ...grpc.CallOption) (*Empty, error)
 }
 
 type customerServiceClient struct {
@@ -90,6 +100,51 @@ func (c *customerServiceClient) Delete(ctx context.Context, in *CustomerPrimaryK
 	return out, nil
 }
 
+func (c *customerServiceClient) Restore(ctx context.Context, in *CustomerPrimaryKey, opts ...grpc.CallOption) (*Customer, error) {
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, CustomerService_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) Purge(ctx context.Context, in *CustomerPrimaryKey, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CustomerService_Purge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) BulkCreate(ctx context.Context, in *BulkCreateCustomerRequest, opts ...grpc.CallOption) (*BulkCustomerResponse, error) {
+	out := new(BulkCustomerResponse)
+	err := c.cc.Invoke(ctx, CustomerService_BulkCreate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) BulkUpdate(ctx context.Context, in *BulkUpdateCustomerRequest, opts ...grpc.CallOption) (*BulkCustomerResponse, error) {
+	out := new(BulkCustomerResponse)
+	err := c.cc.Invoke(ctx, CustomerService_BulkUpdate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customerServiceClient) BulkDelete(ctx context.Context, in *BulkDeleteCustomerRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, CustomerService_BulkDelete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // CustomerServiceServer is the server API for CustomerService service.
 // All implementations should embed UnimplementedCustomerServiceServer
 // for forward compatibility
@@ -99,6 +154,11 @@ type CustomerServiceServer interface {
 	GetList(context.Context, *GetListCustomerRequest) (*GetListCustomerResponse, error)
 	Update(context.Context, *UpdateCustomer) (*Customer, error)
 	Delete(context.Context, *CustomerPrimaryKey) (*Empty, error)
+	Restore(context.Context, *CustomerPrimaryKey) (*Customer, error)
+	Purge(context.Context, *CustomerPrimaryKey) (*Empty, error)
+	BulkCreate(context.Context, *BulkCreateCustomerRequest) (*BulkCustomerResponse, error)
+	BulkUpdate(context.Context, *BulkUpdateCustomerRequest) (*BulkCustomerResponse, error)
+	BulkDelete(context.Context, *BulkDeleteCustomerRequest) (*Empty, error)
 }
 
 // UnimplementedCustomerServiceServer should be embedded to have forward compatible implementations.
@@ -120,6 +180,21 @@ func (UnimplementedCustomerServiceServer) Update(context.Context, *UpdateCustome
 func (UnimplementedCustomerServiceServer) Delete(context.Context, *CustomerPrimaryKey) (*Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedCustomerServiceServer) Restore(context.Context, *CustomerPrimaryKey) (*Customer, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedCustomerServiceServer) Purge(context.Context, *CustomerPrimaryKey) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
+func (UnimplementedCustomerServiceServer) BulkCreate(context.Context, *BulkCreateCustomerRequest) (*BulkCustomerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCreate not implemented")
+}
+func (UnimplementedCustomerServiceServer) BulkUpdate(context.Context, *BulkUpdateCustomerRequest) (*BulkCustomerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkUpdate not implemented")
+}
+func (UnimplementedCustomerServiceServer) BulkDelete(context.Context, *BulkDeleteCustomerRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkDelete not implemented")
+}
 
 // UnsafeCustomerServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to CustomerServiceServer will
@@ -222,6 +297,96 @@ func _CustomerService_Delete_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CustomerService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CustomerPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Restore(ctx, req.(*CustomerPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CustomerPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_Purge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).Purge(ctx, req.(*CustomerPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_BulkCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCreateCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).BulkCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_BulkCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).BulkCreate(ctx, req.(*BulkCreateCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_BulkUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkUpdateCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).BulkUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_BulkUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).BulkUpdate(ctx, req.(*BulkUpdateCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomerService_BulkDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkDeleteCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomerServiceServer).BulkDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CustomerService_BulkDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomerServiceServer).BulkDelete(ctx, req.(*BulkDeleteCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // CustomerService_ServiceDesc is the grpc.ServiceDesc for CustomerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -249,6 +414,26 @@ var CustomerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _CustomerService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _CustomerService_Restore_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _CustomerService_Purge_Handler,
+		},
+		{
+			MethodName: "BulkCreate",
+			Handler:    _CustomerService_BulkCreate_Handler,
+		},
+		{
+			MethodName: "BulkUpdate",
+			Handler:    _CustomerService_BulkUpdate_Handler,
+		},
+		{
+			MethodName: "BulkDelete",
+			Handler:    _CustomerService_BulkDelete_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "customer.proto",
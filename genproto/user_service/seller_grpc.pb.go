@@ -19,11 +19,16 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	SellerService_Create_FullMethodName  = "/user_service.SellerService/Create"
-	SellerService_GetByID_FullMethodName = "/user_service.SellerService/GetByID"
-	SellerService_GetList_FullMethodName = "/user_service.SellerService/GetList"
-	SellerService_Update_FullMethodName  = "/user_service.SellerService/Update"
-	SellerService_Delete_FullMethodName  = "/user_service.SellerService/Delete"
+	SellerService_Create_FullMethodName     = "/user_service.SellerService/Create"
+	SellerService_GetByID_FullMethodName    = "/user_service.SellerService/GetByID"
+	SellerService_GetList_FullMethodName    = "/user_service.SellerService/GetList"
+	SellerService_Update_FullMethodName     = "/user_service.SellerService/Update"
+	SellerService_Delete_FullMethodName     = "/user_service.SellerService/Delete"
+	SellerService_Restore_FullMethodName    = "/user_service.SellerService/Restore"
+	SellerService_Purge_FullMethodName      = "/user_service.SellerService/Purge"
+	SellerService_BulkCreate_FullMethodName = "/user_service.SellerService/BulkCreate"
+	SellerService_BulkUpdate_FullMethodName = "/user_service.SellerService/BulkUpdate"
+	SellerService_BulkDelete_FullMethodName = "/user_service.SellerService/BulkDelete"
 )
 
 // SellerServiceClient is the client API for SellerService service.
@@ -35,6 +40,11 @@ type SellerServiceClient interface {
 	GetList(ctx context.Context, in *GetListSellerRequest, opts ...grpc.CallOption) (*GetListSellerResponse, error)
 	Update(ctx context.Context, in *UpdateSeller, opts ...grpc.CallOption) (*Seller, error)
 	Delete(ctx context.Context, in *SellerPrimaryKey, opts ...grpc.CallOption) (*Empty2, error)
+	Restore(ctx context.Context, in *SellerPrimaryKey, opts ...grpc.CallOption) (*Seller, error)
+	Purge(ctx context.Context, in *SellerPrimaryKey, opts ...grpc.CallOption) (*Empty2, error)
+	BulkCreate(ctx context.Context, in *BulkCreateSellerRequest, opts ...grpc.CallOption) (*BulkSellerResponse, error)
+	BulkUpdate(ctx context.Context, in *BulkUpdateSellerRequest, opts ...grpc.CallOption) (*BulkSellerResponse, error)
+	BulkDelete(ctx context.Context, in *BulkDeleteSellerRequest, opts ...grpc.CallOption) (*Empty2, error)
 }
 
 type sellerServiceClient struct {
@@ -90,6 +100,51 @@ func (c *sellerServiceClient) Delete(ctx context.Context, in *SellerPrimaryKey,
 	return out, nil
 }
 
+func (c *sellerServiceClient) Restore(ctx context.Context, in *SellerPrimaryKey, opts ...grpc.CallOption) (*Seller, error) {
+	out := new(Seller)
+	err := c.cc.Invoke(ctx, SellerService_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellerServiceClient) Purge(ctx context.Context, in *SellerPrimaryKey, opts ...grpc.CallOption) (*Empty2, error) {
+	out := new(Empty2)
+	err := c.cc.Invoke(ctx, SellerService_Purge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellerServiceClient) BulkCreate(ctx context.Context, in *BulkCreateSellerRequest, opts ...grpc.CallOption) (*BulkSellerResponse, error) {
+	out := new(BulkSellerResponse)
+	err := c.cc.Invoke(ctx, SellerService_BulkCreate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellerServiceClient) BulkUpdate(ctx context.Context, in *BulkUpdateSellerRequest, opts ...grpc.CallOption) (*BulkSellerResponse, error) {
+	out := new(BulkSellerResponse)
+	err := c.cc.Invoke(ctx, SellerService_BulkUpdate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sellerServiceClient) BulkDelete(ctx context.Context, in *BulkDeleteSellerRequest, opts ...grpc.CallOption) (*Empty2, error) {
+	out := new(Empty2)
+	err := c.cc.Invoke(ctx, SellerService_BulkDelete_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SellerServiceServer is the server API for SellerService service.
 // All implementations should embed UnimplementedSellerServiceServer
 // for forward compatibility
@@ -99,6 +154,11 @@ type SellerServiceServer interface {
 	GetList(context.Context, *GetListSellerRequest) (*GetListSellerResponse, error)
 	Update(context.Context, *UpdateSeller) (*Seller, error)
 	Delete(context.Context, *SellerPrimaryKey) (*Empty2, error)
+	Restore(context.Context, *SellerPrimaryKey) (*Seller, error)
+	Purge(context.Context, *SellerPrimaryKey) (*Empty2, error)
+	BulkCreate(context.Context, *BulkCreateSellerRequest) (*BulkSellerResponse, error)
+	BulkUpdate(context.Context, *BulkUpdateSellerRequest) (*BulkSellerResponse, error)
+	BulkDelete(context.Context, *BulkDeleteSellerRequest) (*Empty2, error)
 }
 
 // UnimplementedSellerServiceServer should be embedded to have forward compatible implementations.
@@ -120,6 +180,21 @@ func (UnimplementedSellerServiceServer) Update(context.Context, *UpdateSeller) (
 func (UnimplementedSellerServiceServer) Delete(context.Context, *SellerPrimaryKey) (*Empty2, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedSellerServiceServer) Restore(context.Context, *SellerPrimaryKey) (*Seller, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedSellerServiceServer) Purge(context.Context, *SellerPrimaryKey) (*Empty2, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
+func (UnimplementedSellerServiceServer) BulkCreate(context.Context, *BulkCreateSellerRequest) (*BulkSellerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkCreate not implemented")
+}
+func (UnimplementedSellerServiceServer) BulkUpdate(context.Context, *BulkUpdateSellerRequest) (*BulkSellerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkUpdate not implemented")
+}
+func (UnimplementedSellerServiceServer) BulkDelete(context.Context, *BulkDeleteSellerRequest) (*Empty2, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BulkDelete not implemented")
+}
 
 // UnsafeSellerServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to SellerServiceServer will
@@ -222,6 +297,96 @@ func _SellerService_Delete_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SellerService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SellerPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellerServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellerService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellerServiceServer).Restore(ctx, req.(*SellerPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellerService_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SellerPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellerServiceServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellerService_Purge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellerServiceServer).Purge(ctx, req.(*SellerPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellerService_BulkCreate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkCreateSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellerServiceServer).BulkCreate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellerService_BulkCreate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellerServiceServer).BulkCreate(ctx, req.(*BulkCreateSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellerService_BulkUpdate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkUpdateSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellerServiceServer).BulkUpdate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellerService_BulkUpdate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellerServiceServer).BulkUpdate(ctx, req.(*BulkUpdateSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SellerService_BulkDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BulkDeleteSellerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SellerServiceServer).BulkDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SellerService_BulkDelete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SellerServiceServer).BulkDelete(ctx, req.(*BulkDeleteSellerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SellerService_ServiceDesc is the grpc.ServiceDesc for SellerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -249,6 +414,26 @@ var SellerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _SellerService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _SellerService_Restore_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _SellerService_Purge_Handler,
+		},
+		{
+			MethodName: "BulkCreate",
+			Handler:    _SellerService_BulkCreate_Handler,
+		},
+		{
+			MethodName: "BulkUpdate",
+			Handler:    _SellerService_BulkUpdate_Handler,
+		},
+		{
+			MethodName: "BulkDelete",
+			Handler:    _SellerService_BulkDelete_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "seller.proto",
@@ -19,11 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	ShopService_Create_FullMethodName  = "/user_service.ShopService/Create"
-	ShopService_GetByID_FullMethodName = "/user_service.ShopService/GetByID"
-	ShopService_GetList_FullMethodName = "/user_service.ShopService/GetList"
-	ShopService_Update_FullMethodName  = "/user_service.ShopService/Update"
-	ShopService_Delete_FullMethodName  = "/user_service.ShopService/Delete"
+	ShopService_Create_FullMethodName    = "/user_service.ShopService/Create"
+	ShopService_GetByID_FullMethodName   = "/user_service.ShopService/GetByID"
+	ShopService_GetBySlug_FullMethodName = "/user_service.ShopService/GetBySlug"
+	ShopService_GetList_FullMethodName   = "/user_service.ShopService/GetList"
+	ShopService_Update_FullMethodName    = "/user_service.ShopService/Update"
+	ShopService_Delete_FullMethodName    = "/user_service.ShopService/Delete"
+	ShopService_Restore_FullMethodName   = "/user_service.ShopService/Restore"
+	ShopService_Purge_FullMethodName     = "/user_service.ShopService/Purge"
 )
 
 // ShopServiceClient is the client API for ShopService service.
@@ -32,9 +35,12 @@ const (
 type ShopServiceClient interface {
 	Create(ctx context.Context, in *CreateShop, opts ...grpc.CallOption) (*Shop, error)
 	GetByID(ctx context.Context, in *ShopPrimaryKey, opts ...grpc.CallOption) (*Shop, error)
+	GetBySlug(ctx context.Context, in *ShopSlug, opts ...grpc.CallOption) (*Shop, error)
 	GetList(ctx context.Context, in *GetListShopRequest, opts ...grpc.CallOption) (*GetListShopResponse, error)
 	Update(ctx context.Context, in *UpdateShop, opts ...grpc.CallOption) (*Shop, error)
 	Delete(ctx context.Context, in *ShopPrimaryKey, opts ...grpc.CallOption) (*Empty4, error)
+	Restore(ctx context.Context, in *ShopPrimaryKey, opts ...grpc.CallOption) (*Shop, error)
+	Purge(ctx context.Context, in *ShopPrimaryKey, opts ...grpc.CallOption) (*Empty4, error)
 }
 
 type shopServiceClient struct {
@@ -63,6 +69,15 @@ func (c *shopServiceClient) GetByID(ctx context.Context, in *ShopPrimaryKey, opt
 	return out, nil
 }
 
+func (c *shopServiceClient) GetBySlug(ctx context.Context, in *ShopSlug, opts ...grpc.CallOption) (*Shop, error) {
+	out := new(Shop)
+	err := c.cc.Invoke(ctx, ShopService_GetBySlug_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *shopServiceClient) GetList(ctx context.Context, in *GetListShopRequest, opts ...grpc.CallOption) (*GetListShopResponse, error) {
 	out := new(GetListShopResponse)
 	err := c.cc.Invoke(ctx, ShopService_GetList_FullMethodName, in, out, opts...)
@@ -90,15 +105,36 @@ func (c *shopServiceClient) Delete(ctx context.Context, in *ShopPrimaryKey, opts
 	return out, nil
 }
 
+func (c *shopServiceClient) Restore(ctx context.Context, in *ShopPrimaryKey, opts ...grpc.CallOption) (*Shop, error) {
+	out := new(Shop)
+	err := c.cc.Invoke(ctx, ShopService_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *shopServiceClient) Purge(ctx context.Context, in *ShopPrimaryKey, opts ...grpc.CallOption) (*Empty4, error) {
+	out := new(Empty4)
+	err := c.cc.Invoke(ctx, ShopService_Purge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ShopServiceServer is the server API for ShopService service.
 // All implementations should embed UnimplementedShopServiceServer
 // for forward compatibility
 type ShopServiceServer interface {
 	Create(context.Context, *CreateShop) (*Shop, error)
 	GetByID(context.Context, *ShopPrimaryKey) (*Shop, error)
+	GetBySlug(context.Context, *ShopSlug) (*Shop, error)
 	GetList(context.Context, *GetListShopRequest) (*GetListShopResponse, error)
 	Update(context.Context, *UpdateShop) (*Shop, error)
 	Delete(context.Context, *ShopPrimaryKey) (*Empty4, error)
+	Restore(context.Context, *ShopPrimaryKey) (*Shop, error)
+	Purge(context.Context, *ShopPrimaryKey) (*Empty4, error)
 }
 
 // UnimplementedShopServiceServer should be embedded to have forward compatible implementations.
@@ -111,6 +147,9 @@ func (UnimplementedShopServiceServer) Create(context.Context, *CreateShop) (*Sho
 func (UnimplementedShopServiceServer) GetByID(context.Context, *ShopPrimaryKey) (*Shop, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetByID not implemented")
 }
+func (UnimplementedShopServiceServer) GetBySlug(context.Context, *ShopSlug) (*Shop, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBySlug not implemented")
+}
 func (UnimplementedShopServiceServer) GetList(context.Context, *GetListShopRequest) (*GetListShopResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
 }
@@ -120,6 +159,12 @@ func (UnimplementedShopServiceServer) Update(context.Context, *UpdateShop) (*Sho
 func (UnimplementedShopServiceServer) Delete(context.Context, *ShopPrimaryKey) (*Empty4, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedShopServiceServer) Restore(context.Context, *ShopPrimaryKey) (*Shop, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedShopServiceServer) Purge(context.Context, *ShopPrimaryKey) (*Empty4, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
 
 // UnsafeShopServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to ShopServiceServer will
@@ -168,6 +213,24 @@ func _ShopService_GetByID_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShopService_GetBySlug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShopSlug)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShopServiceServer).GetBySlug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShopService_GetBySlug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShopServiceServer).GetBySlug(ctx, req.(*ShopSlug))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ShopService_GetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetListShopRequest)
 	if err := dec(in); err != nil {
@@ -222,6 +285,42 @@ func _ShopService_Delete_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ShopService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShopPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShopServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShopService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShopServiceServer).Restore(ctx, req.(*ShopPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ShopService_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShopPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ShopServiceServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ShopService_Purge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ShopServiceServer).Purge(ctx, req.(*ShopPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ShopService_ServiceDesc is the grpc.ServiceDesc for ShopService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -237,6 +336,10 @@ var ShopService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetByID",
 			Handler:    _ShopService_GetByID_Handler,
 		},
+		{
+			MethodName: "GetBySlug",
+			Handler:    _ShopService_GetBySlug_Handler,
+		},
 		{
 			MethodName: "GetList",
 			Handler:    _ShopService_GetList_Handler,
@@ -249,6 +352,14 @@ var ShopService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _ShopService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _ShopService_Restore_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _ShopService_Purge_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "shop.proto",
@@ -24,6 +24,8 @@ const (
 	UsService_GetList_FullMethodName = "/user_service.UsService/GetList"
 	UsService_Update_FullMethodName  = "/user_service.UsService/Update"
 	UsService_Delete_FullMethodName  = "/user_service.UsService/Delete"
+	UsService_Restore_FullMethodName = "/user_service.UsService/Restore"
+	UsService_Purge_FullMethodName   = "/user_service.UsService/Purge"
 )
 
 // UsServiceClient is the client API for UsService service.
@@ -35,6 +37,8 @@ type UsServiceClient interface {
 	GetList(ctx context.Context, in *GetListUsRequest, opts ...grpc.CallOption) (*GetListUsResponse, error)
 	Update(ctx context.Context, in *UpdateUs, opts ...grpc.CallOption) (*Us, error)
 	Delete(ctx context.Context, in *UsPrimaryKey, opts ...grpc.CallOption) (*Empty1, error)
+	Restore(ctx context.Context, in *UsPrimaryKey, opts ...grpc.CallOption) (*Us, error)
+	Purge(ctx context.Context, in *UsPrimaryKey, opts ...grpc.CallOption) (*Empty1, error)
 }
 
 type usServiceClient struct {
@@ -90,6 +94,24 @@ func (c *usServiceClient) Delete(ctx context.Context, in *UsPrimaryKey, opts ...
 	return out, nil
 }
 
+func (c *usServiceClient) Restore(ctx context.Context, in *UsPrimaryKey, opts ...grpc.CallOption) (*Us, error) {
+	out := new(Us)
+	err := c.cc.Invoke(ctx, UsService_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usServiceClient) Purge(ctx context.Context, in *UsPrimaryKey, opts ...grpc.CallOption) (*Empty1, error) {
+	out := new(Empty1)
+	err := c.cc.Invoke(ctx, UsService_Purge_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UsServiceServer is the server API for UsService service.
 // All implementations should embed UnimplementedUsServiceServer
 // for forward compatibility
@@ -99,6 +121,8 @@ type UsServiceServer interface {
 	GetList(context.Context, *GetListUsRequest) (*GetListUsResponse, error)
 	Update(context.Context, *UpdateUs) (*Us, error)
 	Delete(context.Context, *UsPrimaryKey) (*Empty1, error)
+	Restore(context.Context, *UsPrimaryKey) (*Us, error)
+	Purge(context.Context, *UsPrimaryKey) (*Empty1, error)
 }
 
 // UnimplementedUsServiceServer should be embedded to have forward compatible implementations.
@@ -120,6 +144,12 @@ func (UnimplementedUsServiceServer) Update(context.Context, *UpdateUs) (*Us, err
 func (UnimplementedUsServiceServer) Delete(context.Context, *UsPrimaryKey) (*Empty1, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedUsServiceServer) Restore(context.Context, *UsPrimaryKey) (*Us, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedUsServiceServer) Purge(context.Context, *UsPrimaryKey) (*Empty1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Purge not implemented")
+}
 
 // UnsafeUsServiceServer may be embedded to opt out of forward compatibility for this service.
 // Use of this interface is not recommended, as added methods to UsServiceServer will
@@ -222,6 +252,42 @@ func _UsService_Delete_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UsService_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsServiceServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsService_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsServiceServer).Restore(ctx, req.(*UsPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UsService_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UsPrimaryKey)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsServiceServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UsService_Purge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsServiceServer).Purge(ctx, req.(*UsPrimaryKey))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UsService_ServiceDesc is the grpc.ServiceDesc for UsService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -249,6 +315,14 @@ var UsService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _UsService_Delete_Handler,
 		},
+		{
+			MethodName: "Restore",
+			Handler:    _UsService_Restore_Handler,
+		},
+		{
+			MethodName: "Purge",
+			Handler:    _UsService_Purge_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "system_user.proto",
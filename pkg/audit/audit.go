@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Outcome ...
+type Outcome string
+
+const (
+	// OutcomeSuccess is recorded for responses below 400.
+	OutcomeSuccess Outcome = "success"
+	// OutcomeFailure ...
+	OutcomeFailure Outcome = "failure"
+)
+
+// Entry is one mutating request. Before and After are the resource as
+// returned by GetByID around the request; either is empty when the resource
+// did not exist (a create, a delete) or could not be read.
+type Entry struct {
+	ID           string          `json:"id"`
+	Time         time.Time       `json:"time"`
+	Actor        string          `json:"actor"`
+	Role         string          `json:"role,omitempty"`
+	Method       string          `json:"method"`
+	Route        string          `json:"route"`
+	Path         string          `json:"path"`
+	ResourceType string          `json:"resource_type,omitempty"`
+	ResourceID   string          `json:"resource_id,omitempty"`
+	Before       json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After        json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	ClientIP     string          `json:"client_ip"`
+	RequestID    string          `json:"request_id"`
+	Status       int             `json:"status"`
+	Outcome      Outcome         `json:"outcome"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// Filter selects entries; zero fields match everything. From is inclusive
+// and To exclusive.
+type Filter struct {
+	Actor        string
+	ResourceType string
+	ResourceID   string
+	RequestID    string
+	Outcome      Outcome
+	From         time.Time
+	To           time.Time
+	Limit        int
+	Offset       int
+}
+
+// Match reports whether e passes f.
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case f.Actor != "" && e.Actor != f.Actor,
+		f.ResourceType != "" && e.ResourceType != f.ResourceType,
+		f.ResourceID != "" && e.ResourceID != f.ResourceID,
+		f.RequestID != "" && e.RequestID != f.RequestID,
+		f.Outcome != "" && e.Outcome != f.Outcome,
+		!f.From.IsZero() && e.Time.Before(f.From),
+		!f.To.IsZero() && !e.Time.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// Sink stores audit entries.
+type Sink interface {
+	Write(ctx context.Context, e Entry) error
+	// Query returns the entries matching f, newest first.
+	Query(ctx context.Context, f Filter) ([]Entry, error)
+}
@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// maxLineSize bounds one entry in the file; larger lines are skipped by Query.
+const maxLineSize = 16 << 20
+
+// FileSink appends entries to a file as JSON lines. Query reads the whole
+// file, so it suits single instances and modest volumes; use PostgresSink
+// otherwise.
+type FileSink struct {
+	path string
+
+	mu sync.Mutex
+	f  *os.File
+}
+
+// NewFileSink opens path for appending, creating it if needed.
+func NewFileSink(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{path: path, f: f}, nil
+}
+
+// Write ...
+func (s *FileSink) Write(ctx context.Context, e Entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.f.Write(line)
+	return err
+}
+
+// Query keeps the last Offset+Limit matches while reading the file from the
+// start, since entries are appended in time order.
+func (s *FileSink) Query(ctx context.Context, f Filter) ([]Entry, error) {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	window := f.Offset + f.Limit
+	var matches []Entry
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64<<10), maxLineSize)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || !f.Match(e) {
+			continue
+		}
+
+		matches = append(matches, e)
+		if f.Limit > 0 && len(matches) > 2*window {
+			matches = append(matches[:0], matches[len(matches)-window:]...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(matches)-1; i < j; i, j = i+1, j-1 {
+		matches[i], matches[j] = matches[j], matches[i]
+	}
+
+	if f.Offset >= len(matches) {
+		return []Entry{}, nil
+	}
+	matches = matches[f.Offset:]
+	if f.Limit > 0 && len(matches) > f.Limit {
+		matches = matches[:f.Limit]
+	}
+	return matches, nil
+}
+
+// Close ...
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.f.Close()
+}
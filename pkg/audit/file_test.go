@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterMatch(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := Entry{Actor: "admin-1", ResourceType: "shop", ResourceID: "7", RequestID: "r1", Outcome: OutcomeSuccess, Time: at}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"empty", Filter{}, true},
+		{"all fields", Filter{Actor: "admin-1", ResourceType: "shop", ResourceID: "7", RequestID: "r1", Outcome: OutcomeSuccess}, true},
+		{"other actor", Filter{Actor: "admin-2"}, false},
+		{"other resource", Filter{ResourceType: "seller"}, false},
+		{"other outcome", Filter{Outcome: OutcomeFailure}, false},
+		{"from is inclusive", Filter{From: at}, true},
+		{"to is exclusive", Filter{To: at}, false},
+		{"in range", Filter{From: at.Add(-time.Hour), To: at.Add(time.Hour)}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(e); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileSinkQuery(t *testing.T) {
+	sink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	ctx := context.Background()
+	for i := 0; i < 20; i++ {
+		outcome := OutcomeSuccess
+		if i%2 == 1 {
+			outcome = OutcomeFailure
+		}
+		if err := sink.Write(ctx, Entry{ID: fmt.Sprint(i), Outcome: outcome}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"newest first", Filter{Limit: 3}, []string{"19", "18", "17"}},
+		{"offset", Filter{Limit: 2, Offset: 3}, []string{"16", "15"}},
+		{"filtered", Filter{Outcome: OutcomeFailure, Limit: 3, Offset: 1}, []string{"17", "15", "13"}},
+		{"past the end", Filter{Limit: 5, Offset: 20}, []string{}},
+		{"no limit", Filter{Outcome: OutcomeSuccess, Offset: 8}, []string{"2", "0"}},
+	}
+	for _, tt := range tests {
+		entries, err := sink.Query(ctx, tt.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(entries))
+		for i, e := range entries {
+			ids[i] = e.ID
+		}
+		if !reflect.DeepEqual(ids, tt.want) {
+			t.Errorf("%s: Query = %v, want %v", tt.name, ids, tt.want)
+		}
+	}
+}
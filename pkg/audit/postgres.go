@@ -0,0 +1,144 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS gateway_audit_log (
+	id            VARCHAR(36) PRIMARY KEY,
+	time          TIMESTAMPTZ NOT NULL,
+	actor         VARCHAR NOT NULL,
+	role          VARCHAR NOT NULL DEFAULT '',
+	method        VARCHAR NOT NULL,
+	route         VARCHAR NOT NULL,
+	path          VARCHAR NOT NULL,
+	resource_type VARCHAR NOT NULL DEFAULT '',
+	resource_id   VARCHAR NOT NULL DEFAULT '',
+	before        JSONB,
+	after         JSONB,
+	client_ip     VARCHAR NOT NULL DEFAULT '',
+	request_id    VARCHAR NOT NULL DEFAULT '',
+	status        INTEGER NOT NULL,
+	outcome       VARCHAR NOT NULL,
+	error         TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS gateway_audit_log_time ON gateway_audit_log (time);
+CREATE INDEX IF NOT EXISTS gateway_audit_log_resource ON gateway_audit_log (resource_type, resource_id, time);
+CREATE INDEX IF NOT EXISTS gateway_audit_log_actor ON gateway_audit_log (actor, time)`
+
+// PostgresSink keeps entries in the gateway_audit_log table.
+type PostgresSink struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresSink creates the gateway_audit_log table if it does not exist.
+func NewPostgresSink(ctx context.Context, db *pgxpool.Pool) (*PostgresSink, error) {
+	if _, err := db.Exec(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("create gateway_audit_log table: %w", err)
+	}
+
+	return &PostgresSink{db: db}, nil
+}
+
+// Write ...
+func (p *PostgresSink) Write(ctx context.Context, e Entry) error {
+	_, err := p.db.Exec(ctx, `
+		INSERT INTO gateway_audit_log (id, time, actor, role, method, route, path, resource_type, resource_id,
+			before, after, client_ip, request_id, status, outcome, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`,
+		e.ID, e.Time, e.Actor, e.Role, e.Method, e.Route, e.Path, e.ResourceType, e.ResourceID,
+		jsonb(e.Before), jsonb(e.After), e.ClientIP, e.RequestID, e.Status, e.Outcome, e.Error,
+	)
+	return err
+}
+
+// jsonb passes an empty snapshot as NULL.
+func jsonb(raw []byte) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+// Query ...
+func (p *PostgresSink) Query(ctx context.Context, f Filter) ([]Entry, error) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	add := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.Actor != "" {
+		add("actor = $%d", f.Actor)
+	}
+	if f.ResourceType != "" {
+		add("resource_type = $%d", f.ResourceType)
+	}
+	if f.ResourceID != "" {
+		add("resource_id = $%d", f.ResourceID)
+	}
+	if f.RequestID != "" {
+		add("request_id = $%d", f.RequestID)
+	}
+	if f.Outcome != "" {
+		add("outcome = $%d", f.Outcome)
+	}
+	if !f.From.IsZero() {
+		add("time >= $%d", f.From)
+	}
+	if !f.To.IsZero() {
+		add("time < $%d", f.To)
+	}
+
+	query := `SELECT id, time, actor, role, method, route, path, resource_type, resource_id,
+		COALESCE(before::TEXT, ''), COALESCE(after::TEXT, ''), client_ip, request_id, status, outcome, error
+		FROM gateway_audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY time DESC, id"
+	if f.Limit > 0 {
+		args = append(args, f.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	if f.Offset > 0 {
+		args = append(args, f.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	rows, err := p.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var (
+			e             Entry
+			before, after string
+		)
+		err := rows.Scan(&e.ID, &e.Time, &e.Actor, &e.Role, &e.Method, &e.Route, &e.Path, &e.ResourceType, &e.ResourceID,
+			&before, &after, &e.ClientIP, &e.RequestID, &e.Status, &e.Outcome, &e.Error)
+		if err != nil {
+			return nil, err
+		}
+		if before != "" {
+			e.Before = []byte(before)
+		}
+		if after != "" {
+			e.After = []byte(after)
+		}
+		e.Time = e.Time.UTC()
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"container/list"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// stats publishes the counters of every named cache under "cache" on
+// /debug/vars.
+var stats = expvar.NewMap("cache")
+
+// Stats are the counters of a cache.
+type Stats struct {
+	Entries   int   `json:"entries"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+// LRU is a size-bounded least-recently-used cache whose entries expire after
+// a TTL. It is safe for concurrent use.
+type LRU[V any] struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU returns a cache holding at most maxEntries values for ttl each. Its
+// counters are published as name.
+func NewLRU[V any](name string, maxEntries int, ttl time.Duration) *LRU[V] {
+	c := &LRU[V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+	stats.Set(name, expvar.Func(func() interface{} { return c.Stats() }))
+	return c
+}
+
+// TTL ...
+func (c *LRU[V]) TTL() time.Duration {
+	return c.ttl
+}
+
+// Get returns the value cached for key, if it has not expired.
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		if time.Now().Before(e.expiresAt) {
+			c.order.MoveToFront(el)
+			c.hits.Add(1)
+			return e.value, true
+		}
+		c.remove(el)
+	}
+
+	c.misses.Add(1)
+	var zero V
+	return zero, false
+}
+
+// Set caches value for key, evicting the least recently used entry when the
+// cache is full. A cache with no TTL or size stores nothing.
+func (c *LRU[V]) Set(key string, value V) {
+	if c.ttl <= 0 || c.maxEntries <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		e := el.Value.(*entry[V])
+		e.value, e.expiresAt = value, expiresAt
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+		c.evictions.Add(1)
+	}
+}
+
+// Delete ...
+func (c *LRU[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.remove(el)
+	}
+}
+
+// Stats ...
+func (c *LRU[V]) Stats() Stats {
+	c.mu.Lock()
+	entries := c.order.Len()
+	c.mu.Unlock()
+
+	return Stats{
+		Entries:   entries,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+func (c *LRU[V]) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.items, el.Value.(*entry[V]).key)
+}
@@ -0,0 +1,165 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a change to a resource, numbered in publishing order.
+type Event struct {
+	ID           uint64          `json:"id"`
+	Type         string          `json:"type"`
+	Time         time.Time       `json:"time"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Object       json.RawMessage `json:"object,omitempty" swaggertype:"object"`
+	RequestID    string          `json:"request_id,omitempty"`
+}
+
+// Match reports whether typ, such as shop.updated, matches one of patterns:
+// an event type, "<resource>.*" or "*". No patterns match everything.
+func Match(patterns []string, typ string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	resource, _, _ := strings.Cut(typ, ".")
+	for _, pattern := range patterns {
+		if pattern == "*" || pattern == typ || pattern == resource+".*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Hub fans events out to in-process subscribers and keeps the latest ones
+// in a ring buffer so that reconnecting subscribers can catch up.
+type Hub struct {
+	mu   sync.Mutex
+	ring []Event
+	// head is the index in ring of the oldest event once ring is full.
+	head int
+	next uint64
+	subs map[*Subscription]struct{}
+}
+
+// NewHub keeps the last size events. IDs start at the current time in
+// microseconds, so they keep growing across restarts and IDs from an
+// earlier process are never mistaken for newer events.
+func NewHub(size int) *Hub {
+	if size < 1 {
+		size = 1
+	}
+
+	return &Hub{
+		ring: make([]Event, 0, size),
+		next: uint64(time.Now().UnixMicro()),
+		subs: make(map[*Subscription]struct{}),
+	}
+}
+
+// Publish numbers ev, stores it and sends it to every subscriber. A
+// subscriber whose buffer is full is closed instead of blocking the others.
+func (h *Hub) Publish(ev Event) Event {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.next++
+	ev.ID = h.next
+	if ev.Time.IsZero() {
+		ev.Time = time.Now().UTC()
+	}
+
+	if len(h.ring) < cap(h.ring) {
+		h.ring = append(h.ring, ev)
+	} else {
+		h.ring[h.head] = ev
+		h.head = (h.head + 1) % len(h.ring)
+	}
+
+	for sub := range h.subs {
+		select {
+		case sub.c <- ev:
+		default:
+			sub.lagged = true
+			h.remove(sub)
+		}
+	}
+	return ev
+}
+
+// Subscription receives the events published after it was created.
+type Subscription struct {
+	hub    *Hub
+	c      chan Event
+	lagged bool
+}
+
+// Subscribe returns a subscription buffering up to buffer events and the
+// buffered events after lastID, oldest first. complete is false when events
+// after lastID have already left the ring buffer. A zero lastID replays
+// nothing.
+func (h *Hub) Subscribe(lastID uint64, buffer int) (sub *Subscription, backlog []Event, complete bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub = &Subscription{hub: h, c: make(chan Event, buffer)}
+	h.subs[sub] = struct{}{}
+
+	if lastID == 0 || lastID >= h.next {
+		return sub, nil, true
+	}
+
+	oldest := h.next + 1
+	if len(h.ring) > 0 {
+		oldest = h.ring[h.head].ID
+	}
+	complete = oldest <= lastID+1
+	for i := 0; i < len(h.ring); i++ {
+		ev := h.ring[(h.head+i)%len(h.ring)]
+		if ev.ID > lastID {
+			backlog = append(backlog, ev)
+		}
+	}
+	return sub, backlog, complete
+}
+
+// C is closed when the subscription is closed, by Close or because it fell
+// behind.
+func (s *Subscription) C() <-chan Event {
+	return s.c
+}
+
+// Lagged reports whether the hub closed the subscription because its
+// buffer was full.
+func (s *Subscription) Lagged() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	return s.lagged
+}
+
+// Close stops the subscription. It may be called more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	if _, ok := h.subs[sub]; ok {
+		delete(h.subs, sub)
+		close(sub.c)
+	}
+}
+
+// Subscribers returns the number of open subscriptions.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subs)
+}
@@ -0,0 +1,92 @@
+package events
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		typ      string
+		want     bool
+	}{
+		{nil, "shop.created", true},
+		{[]string{"*"}, "shop.created", true},
+		{[]string{"shop.*"}, "shop.deleted", true},
+		{[]string{"seller.created", "shop.updated"}, "shop.updated", true},
+		{[]string{"shop.*"}, "seller.created", false},
+		{[]string{"shop.created"}, "shop.updated", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.patterns, tt.typ); got != tt.want {
+			t.Errorf("Match(%v, %q) = %v, want %v", tt.patterns, tt.typ, got, tt.want)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	hub := NewHub(3)
+	var ids []uint64
+	for i := 0; i < 5; i++ {
+		ids = append(ids, hub.Publish(Event{Type: "shop.created"}).ID)
+	}
+	for i := 1; i < len(ids); i++ {
+		if ids[i] != ids[i-1]+1 {
+			t.Fatalf("IDs %v are not consecutive", ids)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		lastID   uint64
+		backlog  []uint64
+		complete bool
+	}{
+		{"new subscriber", 0, nil, true},
+		{"up to date", ids[4], nil, true},
+		{"in the buffer", ids[2], ids[3:], true},
+		{"oldest buffered", ids[1], ids[2:], true},
+		{"fell out of the buffer", ids[0], ids[2:], false},
+		{"from an earlier process", 1, ids[2:], false},
+	}
+	for _, tt := range tests {
+		sub, backlog, complete := hub.Subscribe(tt.lastID, 1)
+		sub.Close()
+
+		var got []uint64
+		for _, ev := range backlog {
+			got = append(got, ev.ID)
+		}
+		if complete != tt.complete || len(got) != len(tt.backlog) || len(got) > 0 && got[0] != tt.backlog[0] {
+			t.Errorf("%s: Subscribe = %v, %v, want %v, %v", tt.name, got, complete, tt.backlog, tt.complete)
+		}
+	}
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	hub := NewHub(10)
+	slow, _, _ := hub.Subscribe(0, 1)
+	fast, _, _ := hub.Subscribe(0, 10)
+
+	hub.Publish(Event{Type: "shop.created"})
+	hub.Publish(Event{Type: "shop.updated"})
+
+	// The slow subscriber is dropped, not waited for.
+	if ev := <-slow.C(); ev.Type != "shop.created" {
+		t.Errorf("slow subscriber got %q first, want shop.created", ev.Type)
+	}
+	if _, ok := <-slow.C(); ok || !slow.Lagged() {
+		t.Error("slow subscriber is still open")
+	}
+	if len(fast.C()) != 2 || fast.Lagged() {
+		t.Errorf("fast subscriber has %d events, want 2", len(fast.C()))
+	}
+	if n := hub.Subscribers(); n != 1 {
+		t.Errorf("%d subscribers, want 1", n)
+	}
+
+	fast.Close()
+	fast.Close()
+	if n := hub.Subscribers(); n != 0 {
+		t.Errorf("%d subscribers after Close, want 0", n)
+	}
+}
@@ -0,0 +1,75 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// utf8BOM makes spreadsheet applications detect UTF-8, which matters for
+// Cyrillic and Uzbek values.
+const utf8BOM = "\xEF\xBB\xBF"
+
+// formulaPrefixes start cells that spreadsheet applications evaluate as
+// formulas.
+const formulaPrefixes = "=+-@\t\r"
+
+// numberRegex matches phone numbers and signed numbers, which start with + or
+// - but are not formulas.
+var numberRegex = regexp.MustCompile(`^[+-]?[0-9 ().-]+$`)
+
+// EscapeCell prefixes values that would be evaluated as formulas with a
+// quote, which spreadsheets show as text. Phone numbers and negative amounts
+// are left as they are.
+func EscapeCell(value string) string {
+	if value != "" && strings.ContainsRune(formulaPrefixes, rune(value[0])) && !numberRegex.MatchString(value) {
+		return "'" + value
+	}
+	return value
+}
+
+// UnescapeCell reverses EscapeCell, so exported files can be imported again.
+func UnescapeCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(formulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	if _, err := io.WriteString(w, utf8BOM); err != nil {
+		return nil, err
+	}
+
+	return &csvWriter{w: csv.NewWriter(w)}, nil
+}
+
+func (c *csvWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = EscapeCell(value)
+	}
+	return c.w.Write(escaped)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	return c.Flush()
+}
+
+func (c *csvWriter) ContentType() string {
+	return "text/csv; charset=utf-8"
+}
+
+func (c *csvWriter) Extension() string {
+	return FormatCSV
+}
@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEscapeCell(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"", ""},
+		{"Shop", "Shop"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+998901234567", "+998901234567"},
+		{"+998 (90) 123-45-67", "+998 (90) 123-45-67"},
+		{"-1", "-1"},
+		{"-1250.50", "-1250.50"},
+		{"+1+cmd", "'+1+cmd"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\tcmd", "'\tcmd"},
+		{"a=b", "a=b"},
+		{"'quoted", "'quoted"},
+	}
+
+	for _, tt := range tests {
+		if got := EscapeCell(tt.value); got != tt.want {
+			t.Errorf("EscapeCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if got := UnescapeCell(EscapeCell(tt.value)); got != tt.value {
+			t.Errorf("UnescapeCell(EscapeCell(%q)) = %q", tt.value, got)
+		}
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, "shops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"name", "phone"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow([]string{"=1+1", "+998901234567"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), utf8BOM) {
+		t.Error("csv does not start with a BOM")
+	}
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), utf8BOM))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"name", "phone"}, {"'=1+1", "+998901234567"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("rows = %q, want %q", rows, want)
+	}
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"fmt"
+	"io"
+)
+
+const (
+	// FormatCSV ...
+	FormatCSV = "csv"
+	// FormatXLSX ...
+	FormatXLSX = "xlsx"
+)
+
+// Writer writes a table row by row to an underlying stream without keeping
+// earlier rows in memory.
+type Writer interface {
+	WriteRow(values []string) error
+	// Flush pushes buffered rows to the underlying writer.
+	Flush() error
+	// Close finishes the document; the Writer must not be used afterwards.
+	Close() error
+	ContentType() string
+	Extension() string
+}
+
+// NewWriter ...
+func NewWriter(format string, w io.Writer, sheet string) (Writer, error) {
+	switch format {
+	case FormatCSV, "":
+		return newCSVWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w, sheet)
+	default:
+		return nil, fmt.Errorf("export format %s is not supported, use csv or xlsx", format)
+	}
+}
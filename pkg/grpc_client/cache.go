@@ -0,0 +1,198 @@
+package grpc_client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	pc "microservice/genproto/user_service"
+	"microservice/pkg/cache"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type noCacheKey struct{}
+
+// WithoutCache makes reads made with ctx skip cached responses. The fresh
+// response still replaces the cached one.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// readCache caches the read responses of one service. Writes through the
+// gateway bump the generation, which drops every cached list page and slug
+// lookup at once and keeps reads that were in flight during the write from
+// storing stale data.
+type readCache struct {
+	lru        *cache.LRU[proto.Message]
+	generation atomic.Uint64
+}
+
+func newReadCache(name string, maxEntries int, ttl time.Duration) *readCache {
+	return &readCache{lru: cache.NewLRU[proto.Message](name, maxEntries, ttl)}
+}
+
+func (r *readCache) idKey(id string) string {
+	return "id:" + id
+}
+
+// slugKey includes the generation because writes only name the ID.
+func (r *readCache) slugKey(slug string) string {
+	return "slug:" + strconv.FormatUint(r.generation.Load(), 10) + ":" + slug
+}
+
+// listKey includes the request type because two RPCs can share a cache and
+// their requests can encode to the same bytes.
+func (r *readCache) listKey(req proto.Message) string {
+	body, _ := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	sum := sha256.Sum256(body)
+	return "list:" + strconv.FormatUint(r.generation.Load(), 10) + ":" +
+		string(req.ProtoReflect().Descriptor().FullName()) + ":" + hex.EncodeToString(sum[:])
+}
+
+func (r *readCache) invalidate(ids ...string) {
+	r.generation.Add(1)
+	for _, id := range ids {
+		r.lru.Delete(r.idKey(id))
+	}
+}
+
+// cachedRead returns a copy of the response cached under key or calls the
+// service and caches its response.
+func cachedRead[T proto.Message](ctx context.Context, r *readCache, key string, call func() (T, error)) (T, error) {
+	if skip, _ := ctx.Value(noCacheKey{}).(bool); !skip {
+		if v, ok := r.lru.Get(key); ok {
+			return proto.Clone(v).(T), nil
+		}
+	}
+
+	generation := r.generation.Load()
+	resp, err := call()
+	if err != nil {
+		return resp, err
+	}
+
+	if r.generation.Load() == generation {
+		r.lru.Set(key, proto.Clone(resp))
+	}
+	return resp, nil
+}
+
+type cachedShopClient struct {
+	pc.ShopServiceClient
+	cache *readCache
+}
+
+func (c *cachedShopClient) GetByID(ctx context.Context, in *pc.ShopPrimaryKey, opts ...grpc.CallOption) (*pc.Shop, error) {
+	return cachedRead(ctx, c.cache, c.cache.idKey(in.GetId()), func() (*pc.Shop, error) {
+		return c.ShopServiceClient.GetByID(ctx, in, opts...)
+	})
+}
+
+func (c *cachedShopClient) GetBySlug(ctx context.Context, in *pc.ShopSlug, opts ...grpc.CallOption) (*pc.Shop, error) {
+	return cachedRead(ctx, c.cache, c.cache.slugKey(in.GetSlug()), func() (*pc.Shop, error) {
+		return c.ShopServiceClient.GetBySlug(ctx, in, opts...)
+	})
+}
+
+func (c *cachedShopClient) GetList(ctx context.Context, in *pc.GetListShopRequest, opts ...grpc.CallOption) (*pc.GetListShopResponse, error) {
+	return cachedRead(ctx, c.cache, c.cache.listKey(in), func() (*pc.GetListShopResponse, error) {
+		return c.ShopServiceClient.GetList(ctx, in, opts...)
+	})
+}
+
+func (c *cachedShopClient) Create(ctx context.Context, in *pc.CreateShop, opts ...grpc.CallOption) (*pc.Shop, error) {
+	defer c.cache.invalidate()
+	return c.ShopServiceClient.Create(ctx, in, opts...)
+}
+
+func (c *cachedShopClient) Update(ctx context.Context, in *pc.UpdateShop, opts ...grpc.CallOption) (*pc.Shop, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.ShopServiceClient.Update(ctx, in, opts...)
+}
+
+func (c *cachedShopClient) Delete(ctx context.Context, in *pc.ShopPrimaryKey, opts ...grpc.CallOption) (*pc.Empty4, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.ShopServiceClient.Delete(ctx, in, opts...)
+}
+
+func (c *cachedShopClient) Restore(ctx context.Context, in *pc.ShopPrimaryKey, opts ...grpc.CallOption) (*pc.Shop, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.ShopServiceClient.Restore(ctx, in, opts...)
+}
+
+func (c *cachedShopClient) Purge(ctx context.Context, in *pc.ShopPrimaryKey, opts ...grpc.CallOption) (*pc.Empty4, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.ShopServiceClient.Purge(ctx, in, opts...)
+}
+
+type cachedBranchClient struct {
+	pc.BranchServiceClient
+	cache *readCache
+}
+
+func (c *cachedBranchClient) GetByID(ctx context.Context, in *pc.BranchPrimaryKey, opts ...grpc.CallOption) (*pc.Branch, error) {
+	return cachedRead(ctx, c.cache, c.cache.idKey(in.GetId()), func() (*pc.Branch, error) {
+		return c.BranchServiceClient.GetByID(ctx, in, opts...)
+	})
+}
+
+func (c *cachedBranchClient) GetList(ctx context.Context, in *pc.GetListBranchRequest, opts ...grpc.CallOption) (*pc.GetListBranchResponse, error) {
+	return cachedRead(ctx, c.cache, c.cache.listKey(in), func() (*pc.GetListBranchResponse, error) {
+		return c.BranchServiceClient.GetList(ctx, in, opts...)
+	})
+}
+
+func (c *cachedBranchClient) GetNearby(ctx context.Context, in *pc.GetNearbyBranchRequest, opts ...grpc.CallOption) (*pc.GetListBranchResponse, error) {
+	return cachedRead(ctx, c.cache, c.cache.listKey(in), func() (*pc.GetListBranchResponse, error) {
+		return c.BranchServiceClient.GetNearby(ctx, in, opts...)
+	})
+}
+
+func (c *cachedBranchClient) Create(ctx context.Context, in *pc.CreateBranch, opts ...grpc.CallOption) (*pc.Branch, error) {
+	defer c.cache.invalidate()
+	return c.BranchServiceClient.Create(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) Update(ctx context.Context, in *pc.UpdateBranch, opts ...grpc.CallOption) (*pc.Branch, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.BranchServiceClient.Update(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) Delete(ctx context.Context, in *pc.BranchPrimaryKey, opts ...grpc.CallOption) (*pc.Empty3, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.BranchServiceClient.Delete(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) Restore(ctx context.Context, in *pc.BranchPrimaryKey, opts ...grpc.CallOption) (*pc.Branch, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.BranchServiceClient.Restore(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) Purge(ctx context.Context, in *pc.BranchPrimaryKey, opts ...grpc.CallOption) (*pc.Empty3, error) {
+	defer c.cache.invalidate(in.GetId())
+	return c.BranchServiceClient.Purge(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) BulkCreate(ctx context.Context, in *pc.BulkCreateBranchRequest, opts ...grpc.CallOption) (*pc.BulkBranchResponse, error) {
+	defer c.cache.invalidate()
+	return c.BranchServiceClient.BulkCreate(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) BulkUpdate(ctx context.Context, in *pc.BulkUpdateBranchRequest, opts ...grpc.CallOption) (*pc.BulkBranchResponse, error) {
+	ids := make([]string, 0, len(in.GetBranches()))
+	for _, branch := range in.GetBranches() {
+		ids = append(ids, branch.GetId())
+	}
+
+	defer c.cache.invalidate(ids...)
+	return c.BranchServiceClient.BulkUpdate(ctx, in, opts...)
+}
+
+func (c *cachedBranchClient) BulkDelete(ctx context.Context, in *pc.BulkDeleteBranchRequest, opts ...grpc.CallOption) (*pc.Empty3, error) {
+	defer c.cache.invalidate(in.GetIds()...)
+	return c.BranchServiceClient.BulkDelete(ctx, in, opts...)
+}
@@ -23,15 +23,21 @@ type GrpcClientI interface {
 // GrpcClient ...
 type GrpcClient struct {
 	cfg         config.Config
+	conn        *grpc.ClientConn
 	connections map[string]interface{}
 }
 
 // New ...
 func New(cfg config.Config) (*GrpcClient, error) {
 
+	dialOptions := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	if cfg.CoalesceReads {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(coalesceReads(cfg.CoalesceTimeout)))
+	}
+
 	connUser, err := grpc.Dial(
 		fmt.Sprintf("%s:%s", cfg.UserServiceHost, cfg.UserServicePort),
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+		dialOptions...)
 
 	if err != nil {
 		return nil, fmt.Errorf("user service dial host: %s port:%s err: %s",
@@ -39,13 +45,20 @@ func New(cfg config.Config) (*GrpcClient, error) {
 	}
 
 	return &GrpcClient{
-		cfg: cfg,
+		cfg:  cfg,
+		conn: connUser,
 		connections: map[string]interface{}{
 			"user_service": pc.NewCustomerServiceClient(connUser),
 			"system_user":  pc.NewUsServiceClient(connUser),
 			"seller":       pc.NewSellerServiceClient(connUser),
-			"branch":       pc.NewBranchServiceClient(connUser),
-			"shop":			pc.NewShopServiceClient(connUser),
+			"branch": &cachedBranchClient{
+				BranchServiceClient: pc.NewBranchServiceClient(connUser),
+				cache:               newReadCache("branch", cfg.CacheMaxEntries, cfg.CacheBranchTTL),
+			},
+			"shop": &cachedShopClient{
+				ShopServiceClient: pc.NewShopServiceClient(connUser),
+				cache:             newReadCache("shop", cfg.CacheMaxEntries, cfg.CacheShopTTL),
+			},
 		},
 	}, nil
 }
@@ -0,0 +1,98 @@
+package grpc_client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// coalesceReads is a unary client interceptor that collapses concurrent
+// identical read calls (Get* methods with the same request message and
+// outgoing metadata) into one upstream call whose response every caller
+// receives a copy of. The shared call has neither the deadline nor the
+// cancellation of the caller that started it, so a caller that gives up early
+// does not fail the others; it runs for at most timeout, if positive, and each
+// caller stops waiting for it when its own context ends.
+func coalesceReads(timeout time.Duration) grpc.UnaryClientInterceptor {
+	var group singleflight.Group
+
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		in, ok := req.(proto.Message)
+		out, replyOK := reply.(proto.Message)
+		if !ok || !replyOK || !isReadMethod(method) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		key, err := coalesceKey(ctx, method, in)
+		if err != nil {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ch := group.DoChan(key, func() (interface{}, error) {
+			shared := context.WithoutCancel(ctx)
+			if timeout > 0 {
+				var cancel context.CancelFunc
+				shared, cancel = context.WithTimeout(shared, timeout)
+				defer cancel()
+			}
+
+			resp := out.ProtoReflect().New().Interface()
+			if err := invoker(shared, method, req, resp, cc, opts...); err != nil {
+				return nil, err
+			}
+			return resp, nil
+		})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case result := <-ch:
+			if result.Err != nil {
+				return result.Err
+			}
+			proto.Reset(out)
+			proto.Merge(out, result.Val.(proto.Message))
+			return nil
+		}
+	}
+}
+
+// isReadMethod reports whether a full method name such as
+// /ShopService/GetByID names a read.
+func isReadMethod(method string) bool {
+	return strings.HasPrefix(method[strings.LastIndex(method, "/")+1:], "Get")
+}
+
+func coalesceKey(ctx context.Context, method string, req proto.Message) (string, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		keys := make([]string, 0, len(md))
+		for k := range md {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			h.Write([]byte{0})
+			h.Write([]byte(k + "=" + strings.Join(md[k], ",")))
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,210 @@
+package grpc_client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pc "microservice/genproto/user_service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestIsReadMethod(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{"/user_service.ShopService/GetByID", true},
+		{"/user_service.ShopService/GetList", true},
+		{"/user_service.ShopService/Update", false},
+		{"/user_service.ShopService/Purge", false},
+	}
+	for _, tt := range tests {
+		if got := isReadMethod(tt.method); got != tt.want {
+			t.Errorf("isReadMethod(%q) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestCoalesceKey(t *testing.T) {
+	key := func(ctx context.Context, method, id string) string {
+		k, err := coalesceKey(ctx, method, &pc.ShopPrimaryKey{Id: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+	var (
+		ctx   = context.Background()
+		alice = metadata.AppendToOutgoingContext(ctx, "authorization", "alice")
+		base  = key(ctx, "/user_service.ShopService/GetByID", "1")
+	)
+
+	if key(ctx, "/user_service.ShopService/GetByID", "1") != base {
+		t.Error("identical calls have different keys")
+	}
+	for name, other := range map[string]string{
+		"other request":  key(ctx, "/user_service.ShopService/GetByID", "2"),
+		"other method":   key(ctx, "/user_service.SellerService/GetByID", "1"),
+		"other metadata": key(alice, "/user_service.ShopService/GetByID", "1"),
+	} {
+		if other == base {
+			t.Errorf("%s has the same key", name)
+		}
+	}
+}
+
+// blockingInvoker answers with a shop named after the request once release
+// is closed, and counts its calls.
+type blockingInvoker struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (b *blockingInvoker) invoke(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+	atomic.AddInt32(&b.calls, 1)
+	select {
+	case <-b.release:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	reply.(*pc.Shop).Id = req.(*pc.ShopPrimaryKey).Id
+	return nil
+}
+
+func TestCoalesceReads(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		ids    []string
+		calls  int32
+	}{
+		{"identical reads", "/user_service.ShopService/GetByID", []string{"1", "1", "1", "1"}, 1},
+		{"different reads", "/user_service.ShopService/GetByID", []string{"1", "2", "1", "2"}, 2},
+		{"writes", "/user_service.ShopService/Delete", []string{"1", "1", "1"}, 3},
+	}
+
+	for _, tt := range tests {
+		var (
+			intercept = coalesceReads(time.Minute)
+			invoker   = &blockingInvoker{release: make(chan struct{})}
+			replies   = make([]*pc.Shop, len(tt.ids))
+			errs      = make([]error, len(tt.ids))
+			wg        sync.WaitGroup
+		)
+		for i, id := range tt.ids {
+			wg.Add(1)
+			go func(i int, id string) {
+				defer wg.Done()
+				replies[i] = &pc.Shop{}
+				errs[i] = intercept(context.Background(), tt.method, &pc.ShopPrimaryKey{Id: id}, replies[i], nil, invoker.invoke)
+			}(i, id)
+		}
+		waitFor(t, func() bool { return atomic.LoadInt32(&invoker.calls) == tt.calls })
+		time.Sleep(10 * time.Millisecond)
+		close(invoker.release)
+		wg.Wait()
+
+		if n := atomic.LoadInt32(&invoker.calls); n != tt.calls {
+			t.Errorf("%s: %d upstream calls, want %d", tt.name, n, tt.calls)
+		}
+		for i, id := range tt.ids {
+			if errs[i] != nil || replies[i].Id != id {
+				t.Errorf("%s: caller %d got %v, %v, want shop %s", tt.name, i, replies[i], errs[i], id)
+			}
+		}
+		if tt.calls == 1 && replies[0] == replies[1] {
+			t.Errorf("%s: callers share one reply", tt.name)
+		}
+	}
+}
+
+func TestCoalesceReadsCancel(t *testing.T) {
+	var (
+		intercept = coalesceReads(time.Minute)
+		invoker   = &blockingInvoker{release: make(chan struct{})}
+		method    = "/user_service.ShopService/GetByID"
+		done      = make(chan error, 1)
+	)
+
+	// The first caller leaves; the call it started still serves the second.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- intercept(ctx, method, &pc.ShopPrimaryKey{Id: "1"}, &pc.Shop{}, nil, invoker.invoke) }()
+	waitFor(t, func() bool { return atomic.LoadInt32(&invoker.calls) == 1 })
+
+	second := make(chan error, 1)
+	reply := &pc.Shop{}
+	go func() {
+		second <- intercept(context.Background(), method, &pc.ShopPrimaryKey{Id: "1"}, reply, nil, invoker.invoke)
+	}()
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("cancelled caller got %v, want context.Canceled", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(invoker.release)
+	if err := <-second; err != nil || reply.Id != "1" {
+		t.Errorf("second caller got %v, %v, want shop 1", reply, err)
+	}
+	if n := atomic.LoadInt32(&invoker.calls); n != 1 {
+		t.Errorf("%d upstream calls, want 1", n)
+	}
+}
+
+func TestCoalesceReadsDeadlines(t *testing.T) {
+	var (
+		intercept = coalesceReads(time.Minute)
+		invoker   = &blockingInvoker{release: make(chan struct{})}
+		method    = "/user_service.ShopService/GetByID"
+	)
+
+	// The first caller's deadline passes while the call it started still
+	// serves the second, whose deadline is later.
+	short, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	first := make(chan error, 1)
+	go func() {
+		first <- intercept(short, method, &pc.ShopPrimaryKey{Id: "1"}, &pc.Shop{}, nil, invoker.invoke)
+	}()
+	waitFor(t, func() bool { return atomic.LoadInt32(&invoker.calls) == 1 })
+
+	long, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	second := make(chan error, 1)
+	reply := &pc.Shop{}
+	go func() { second <- intercept(long, method, &pc.ShopPrimaryKey{Id: "1"}, reply, nil, invoker.invoke) }()
+
+	if err := <-first; err != context.DeadlineExceeded {
+		t.Errorf("caller with the short deadline got %v, want context.DeadlineExceeded", err)
+	}
+	close(invoker.release)
+	if err := <-second; err != nil || reply.Id != "1" {
+		t.Errorf("caller with the long deadline got %v, %v, want shop 1", reply, err)
+	}
+	if n := atomic.LoadInt32(&invoker.calls); n != 1 {
+		t.Errorf("%d upstream calls, want 1", n)
+	}
+
+	// The shared call gives up after its own timeout even when its callers
+	// wait longer.
+	intercept = coalesceReads(20 * time.Millisecond)
+	invoker = &blockingInvoker{release: make(chan struct{})}
+	if err := intercept(context.Background(), method, &pc.ShopPrimaryKey{Id: "1"}, &pc.Shop{}, nil, invoker.invoke); err != context.DeadlineExceeded {
+		t.Errorf("call past the coalesce timeout got %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+	}
+}
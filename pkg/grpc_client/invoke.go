@@ -0,0 +1,69 @@
+package grpc_client
+
+import (
+	"context"
+	"fmt"
+	pc "microservice/genproto/user_service"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Invoke calls the method md with req for callers that only have its
+// descriptor. A method of one of the service clients is called through that
+// client, so its reads are cached and its writes drop the cached responses
+// as when it is called by name. Any other method is invoked on the user
+// service connection: the method name and the response message come from
+// md, so it needs no generated client. The interceptors of the connection
+// apply either way.
+func (g *GrpcClient) Invoke(ctx context.Context, md protoreflect.MethodDescriptor, req proto.Message) (proto.Message, error) {
+	if method, ok := g.clientMethod(md, req); ok {
+		out := method.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(req)})
+		if err, _ := out[1].Interface().(error); err != nil {
+			return nil, err
+		}
+		return out[0].Interface().(proto.Message), nil
+	}
+
+	var resp proto.Message
+	if mt, err := protoregistry.GlobalTypes.FindMessageByName(md.Output().FullName()); err == nil {
+		resp = mt.New().Interface()
+	} else {
+		resp = dynamicpb.NewMessage(md.Output())
+	}
+
+	method := fmt.Sprintf("/%s/%s", md.Parent().FullName(), md.Name())
+	if err := g.conn.Invoke(ctx, method, req, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// clientMethod returns the method of the service client that calls md, if
+// there is one and it takes req.
+func (g *GrpcClient) clientMethod(md protoreflect.MethodDescriptor, req proto.Message) (reflect.Value, bool) {
+	var client interface{}
+	switch md.Parent().FullName() {
+	case protoreflect.FullName(pc.CustomerService_ServiceDesc.ServiceName):
+		client = g.UserService()
+	case protoreflect.FullName(pc.UsService_ServiceDesc.ServiceName):
+		client = g.SystemUserService()
+	case protoreflect.FullName(pc.SellerService_ServiceDesc.ServiceName):
+		client = g.SellerService()
+	case protoreflect.FullName(pc.BranchService_ServiceDesc.ServiceName):
+		client = g.BranchService()
+	case protoreflect.FullName(pc.ShopService_ServiceDesc.ServiceName):
+		client = g.ShopService()
+	default:
+		return reflect.Value{}, false
+	}
+
+	method := reflect.ValueOf(client).MethodByName(string(md.Name()))
+	if !method.IsValid() || method.Type().NumIn() < 2 || method.Type().In(1) != reflect.TypeOf(req) {
+		return reflect.Value{}, false
+	}
+	return method, true
+}
@@ -0,0 +1,57 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"microservice/pkg/logger"
+	"net/http"
+	"time"
+)
+
+// ErrNotFound is returned by Complete for keys without a record.
+var ErrNotFound = errors.New("idempotency key not found")
+
+// Record is the stored outcome of the first request made with a key. Status
+// is zero while that request is still being handled. Header holds the
+// response headers a replay repeats.
+type Record struct {
+	Key         string
+	Fingerprint string
+	Status      int
+	Header      http.Header
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// Completed reports whether the first request has finished.
+func (r Record) Completed() bool {
+	return r.Status != 0
+}
+
+// Store keeps idempotency records. Expired records behave as if they did not
+// exist.
+type Store interface {
+	// Reserve saves rec unless an unexpired record with the same key exists,
+	// in which case that record is returned with false.
+	Reserve(ctx context.Context, rec Record) (Record, bool, error)
+	// Complete stores the response of a reserved key.
+	Complete(ctx context.Context, rec Record) error
+	// Release deletes a reserved key so that the request can be retried.
+	Release(ctx context.Context, key string) error
+	// DeleteExpired removes records that expired before now.
+	DeleteExpired(ctx context.Context, now time.Time) error
+}
+
+// RunCleanup deletes expired records every interval. It does not return.
+func RunCleanup(store Store, interval time.Duration, log logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		if err := store.DeleteExpired(ctx, time.Now()); err != nil {
+			log.Error("failed to delete expired idempotency keys", logger.Error(err))
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,66 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps records in process memory; retries must reach the same
+// gateway instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+// Reserve ...
+func (s *MemoryStore) Reserve(ctx context.Context, rec Record) (Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[rec.Key]; ok && time.Now().Before(existing.ExpiresAt) {
+		return existing, false, nil
+	}
+
+	s.records[rec.Key] = rec
+	return rec, true, nil
+}
+
+// Complete ...
+func (s *MemoryStore) Complete(ctx context.Context, rec Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.records[rec.Key]; !ok {
+		return ErrNotFound
+	}
+	s.records[rec.Key] = rec
+	return nil
+}
+
+// Release ...
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// DeleteExpired ...
+func (s *MemoryStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, rec := range s.records {
+		if !now.Before(rec.ExpiresAt) {
+			delete(s.records, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS gateway_idempotency_keys (
+	key          VARCHAR PRIMARY KEY,
+	fingerprint  VARCHAR NOT NULL,
+	status       INTEGER NOT NULL DEFAULT 0,
+	header       JSONB,
+	body         BYTEA,
+	expires_at   TIMESTAMPTZ NOT NULL
+)`
+
+// PostgresStore keeps records in the gateway_idempotency_keys table, shared
+// by every gateway instance.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore creates the gateway_idempotency_keys table if it does not
+// exist.
+func NewPostgresStore(ctx context.Context, db *pgxpool.Pool) (*PostgresStore, error) {
+	if _, err := db.Exec(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("create gateway_idempotency_keys table: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// Reserve ...
+func (s *PostgresStore) Reserve(ctx context.Context, rec Record) (Record, bool, error) {
+	// The upsert only replaces expired records, so exactly one of concurrent
+	// requests with the same key gets a row back.
+	tag, err := s.db.Exec(ctx, `
+		INSERT INTO gateway_idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status = 0, header = NULL, body = NULL, expires_at = EXCLUDED.expires_at
+		WHERE gateway_idempotency_keys.expires_at <= NOW()`,
+		rec.Key, rec.Fingerprint, rec.ExpiresAt,
+	)
+	if err != nil {
+		return Record{}, false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return rec, true, nil
+	}
+
+	var (
+		existing Record
+		header   []byte
+	)
+	err = s.db.QueryRow(ctx, `
+		SELECT key, fingerprint, status, header, body, expires_at
+		FROM gateway_idempotency_keys
+		WHERE key = $1`, rec.Key,
+	).Scan(
+		&existing.Key, &existing.Fingerprint, &existing.Status, &header,
+		&existing.Body, &existing.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Released in the meantime.
+		return s.Reserve(ctx, rec)
+	}
+	if err != nil {
+		return Record{}, false, err
+	}
+	if header != nil {
+		if err := json.Unmarshal(header, &existing.Header); err != nil {
+			return Record{}, false, err
+		}
+	}
+
+	return existing, false, nil
+}
+
+// Complete ...
+func (s *PostgresStore) Complete(ctx context.Context, rec Record) error {
+	header, err := json.Marshal(rec.Header)
+	if err != nil {
+		return err
+	}
+
+	tag, err := s.db.Exec(ctx, `
+		UPDATE gateway_idempotency_keys
+		SET status = $2, header = $3, body = $4, expires_at = $5
+		WHERE key = $1`,
+		rec.Key, rec.Status, header, rec.Body, rec.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Release ...
+func (s *PostgresStore) Release(ctx context.Context, key string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM gateway_idempotency_keys WHERE key = $1`, key)
+	return err
+}
+
+// DeleteExpired ...
+func (s *PostgresStore) DeleteExpired(ctx context.Context, now time.Time) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM gateway_idempotency_keys WHERE expires_at <= $1`, now)
+	return err
+}
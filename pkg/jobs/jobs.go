@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// Status ...
+type Status string
+
+const (
+	// StatusPending ...
+	StatusPending Status = "pending"
+	// StatusRunning ...
+	StatusRunning Status = "running"
+	// StatusSucceeded ...
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed ...
+	StatusFailed Status = "failed"
+	// StatusCancelled ...
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether a job in status s will not change anymore.
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+var (
+	// ErrNotFound is returned for unknown job IDs.
+	ErrNotFound = errors.New("job not found")
+	// ErrNoFile is returned when a job has no downloadable result.
+	ErrNoFile = errors.New("job has no file")
+	// ErrQueueFull is returned by Submit when every worker is busy and the
+	// queue has no room left.
+	ErrQueueFull = errors.New("job queue is full")
+	// ErrFinished is returned when cancelling a job that already finished.
+	ErrFinished = errors.New("job already finished")
+)
+
+// Job is the state of a long-running gateway operation as reported to
+// clients.
+type Job struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Status    Status          `json:"status"`
+	Total     int             `json:"total"`
+	Processed int             `json:"processed"`
+	Failed    int             `json:"failed"`
+	Result    json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error     string          `json:"error,omitempty"`
+	File      string          `json:"file,omitempty"`
+	// Owner is the principal that submitted the job.
+	Owner     string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// File is a downloadable job result such as an export. Its content is kept
+// on disk at Path rather than in the store.
+type File struct {
+	Name        string
+	ContentType string
+	Path        string
+}
+
+// Open opens the content of f; it returns ErrNoFile if it was removed.
+func (f File) Open() (*os.File, error) {
+	file, err := os.Open(f.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoFile
+	}
+	return file, err
+}
+
+// Func is the body of a job. It reports progress through t and returns the
+// job's result, which is stored as JSON. ctx is cancelled when the job is.
+type Func func(ctx context.Context, t *Task) (interface{}, error)
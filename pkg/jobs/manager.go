@@ -0,0 +1,434 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"microservice/pkg/logger"
+	"microservice/pkg/uuid"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// progressInterval limits how often progress is written to the store;
+	// Get reads the live counters of jobs running on this instance.
+	progressInterval = time.Second
+	storeTimeout     = 5 * time.Second
+	// Unfinished jobs are saved every heartbeatInterval; one that was not
+	// saved for staleAfter belonged to an instance that stopped, and is
+	// marked as failed.
+	heartbeatInterval = 30 * time.Second
+	staleAfter        = 3 * heartbeatInterval
+)
+
+// errInterrupted is the error of jobs whose instance stopped.
+const errInterrupted = "the gateway stopped while the job was unfinished"
+
+// Options ...
+type Options struct {
+	// Workers is the number of jobs run at the same time.
+	Workers int
+	// QueueSize is the number of submitted jobs that may wait for a worker.
+	QueueSize int
+	// Retention is how long finished jobs are kept; zero keeps them forever.
+	Retention time.Duration
+	// FileDir is where job files are written, the system temp directory by
+	// default. Instances sharing a Postgres store need it on shared storage
+	// to serve each other's files.
+	FileDir string
+}
+
+// Manager runs jobs on a fixed pool of workers and keeps their state in a
+// Store. Cancellation reaches jobs running on this instance.
+type Manager struct {
+	store   Store
+	log     logger.Logger
+	queue   chan *Task
+	fileDir string
+
+	mu    sync.Mutex
+	tasks map[string]*Task
+}
+
+// NewManager starts opts.Workers workers, a heartbeat that fails jobs left
+// unfinished by stopped instances and, with a retention, a janitor that
+// deletes old finished jobs.
+func NewManager(store Store, log logger.Logger, opts Options) *Manager {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize < 0 {
+		opts.QueueSize = 0
+	}
+	if opts.FileDir == "" {
+		opts.FileDir = os.TempDir()
+	}
+
+	m := &Manager{
+		store:   store,
+		log:     log,
+		queue:   make(chan *Task, opts.QueueSize),
+		fileDir: opts.FileDir,
+		tasks:   make(map[string]*Task),
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		go m.work()
+	}
+	go m.heartbeat()
+	if opts.Retention > 0 {
+		go m.cleanup(opts.Retention)
+	}
+
+	return m
+}
+
+// Submit stores a pending job of typ with total items, submitted by owner,
+// and queues fn. It returns ErrQueueFull instead of blocking when the queue
+// is full.
+func (m *Manager) Submit(ctx context.Context, typ, owner string, total int, fn Func) (Job, error) {
+	id, err := uuid.New()
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now().UTC()
+	t := &Task{
+		m:  m,
+		fn: fn,
+		job: Job{
+			ID:        id,
+			Type:      typ,
+			Status:    StatusPending,
+			Total:     total,
+			Owner:     owner,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+
+	if err := m.store.Create(ctx, t.job); err != nil {
+		t.cancel()
+		return Job{}, err
+	}
+
+	m.mu.Lock()
+	m.tasks[t.job.ID] = t
+	m.mu.Unlock()
+
+	select {
+	case m.queue <- t:
+		return t.snapshot(), nil
+	default:
+		t.finish(nil, ErrQueueFull)
+		m.done(t)
+		return Job{}, ErrQueueFull
+	}
+}
+
+// Get returns the job with its live progress if it runs on this instance.
+func (m *Manager) Get(ctx context.Context, id string) (Job, error) {
+	if t := m.task(id); t != nil {
+		return t.snapshot(), nil
+	}
+	return m.store.Get(ctx, id)
+}
+
+// File returns the downloadable result of a job.
+func (m *Manager) File(ctx context.Context, id string) (File, error) {
+	return m.store.File(ctx, id)
+}
+
+// Cancel stops a pending or running job. A job that is not known to this
+// instance but is not finished either (e.g. left over from a restart) is
+// marked as cancelled in the store.
+func (m *Manager) Cancel(ctx context.Context, id string) (Job, error) {
+	if t := m.task(id); t != nil {
+		if pending := t.requestCancel(); pending {
+			// Workers skip it; save it now so clients see it.
+			m.done(t)
+		}
+		return t.snapshot(), nil
+	}
+
+	job, err := m.store.Get(ctx, id)
+	if err != nil {
+		return Job{}, err
+	}
+	if job.Status.Finished() {
+		return job, ErrFinished
+	}
+
+	job.Status = StatusCancelled
+	job.UpdatedAt = time.Now().UTC()
+	if err := m.store.Update(ctx, job); err != nil {
+		return Job{}, err
+	}
+	return job, nil
+}
+
+func (m *Manager) work() {
+	for t := range m.queue {
+		m.run(t)
+	}
+}
+
+func (m *Manager) run(t *Task) {
+	if !t.start() {
+		return
+	}
+	m.save(t)
+
+	var (
+		result interface{}
+		err    error
+	)
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("job panicked: %v", r)
+			}
+		}()
+		result, err = t.fn(t.ctx, t)
+	}()
+
+	t.finish(result, err)
+	m.done(t)
+}
+
+// done saves the final state of t and forgets it.
+func (m *Manager) done(t *Task) {
+	m.save(t)
+	t.cancel()
+
+	m.mu.Lock()
+	delete(m.tasks, t.job.ID)
+	m.mu.Unlock()
+}
+
+func (m *Manager) save(t *Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+
+	// Saves from the heartbeat race with those of the job; the snapshot is
+	// taken under saveMu so an older state never overwrites a newer one.
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
+
+	job := t.snapshot()
+	if err := m.store.Update(ctx, job); err != nil {
+		m.log.Error("failed to save job "+job.ID, logger.Error(err))
+	}
+}
+
+func (m *Manager) task(id string) *Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.tasks[id]
+}
+
+// heartbeat saves the jobs of this instance and fails the stale ones of
+// others, including those this instance ran before a restart.
+func (m *Manager) heartbeat() {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		m.mu.Lock()
+		tasks := make([]*Task, 0, len(m.tasks))
+		for _, t := range m.tasks {
+			tasks = append(tasks, t)
+		}
+		m.mu.Unlock()
+
+		for _, t := range tasks {
+			t.touch()
+			m.save(t)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		if err := m.store.FailStale(ctx, time.Now().Add(-staleAfter), errInterrupted); err != nil {
+			m.log.Error("failed to fail stale jobs", logger.Error(err))
+		}
+		cancel()
+
+		<-ticker.C
+	}
+}
+
+func (m *Manager) cleanup(retention time.Duration) {
+	ticker := time.NewTicker(retention / 10)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		before := time.Now().Add(-retention)
+		if err := m.store.DeleteFinished(ctx, before); err != nil {
+			m.log.Error("failed to delete finished jobs", logger.Error(err))
+		}
+		cancel()
+		m.deleteFiles(before)
+	}
+}
+
+// deleteFiles removes job files last written before before; their jobs
+// finished no later than that and are deleted from the store with them.
+func (m *Manager) deleteFiles(before time.Time) {
+	paths, err := filepath.Glob(filepath.Join(m.fileDir, "job-*"))
+	if err != nil {
+		m.log.Error("failed to list job files", logger.Error(err))
+		return
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Before(before) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			m.log.Error("failed to delete job file "+path, logger.Error(err))
+		}
+	}
+}
+
+// Task is a submitted job as seen by its Func.
+type Task struct {
+	m      *Manager
+	fn     Func
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	job       Job
+	cancelled bool
+	saved     time.Time
+
+	saveMu sync.Mutex
+}
+
+// Add records processed items, failed of which did not succeed.
+func (t *Task) Add(processed, failed int) {
+	t.mu.Lock()
+	t.job.Processed += processed
+	t.job.Failed += failed
+	t.job.UpdatedAt = time.Now().UTC()
+	save := t.job.UpdatedAt.Sub(t.saved) >= progressInterval
+	if save {
+		t.saved = t.job.UpdatedAt
+	}
+	t.mu.Unlock()
+
+	if save {
+		t.m.save(t)
+	}
+}
+
+// SetTotal sets the number of items when it is only known once the job runs.
+func (t *Task) SetTotal(total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.job.Total = total
+}
+
+// CreateFile creates a file for the job's result in Options.FileDir. Its
+// name goes in File.Path when it is attached; the caller removes it if the
+// job fails instead.
+func (t *Task) CreateFile() (*os.File, error) {
+	return os.CreateTemp(t.m.fileDir, "job-"+t.job.ID+"-*")
+}
+
+// Attach stores file as the job's downloadable result.
+func (t *Task) Attach(file File) error {
+	ctx, cancel := context.WithTimeout(t.ctx, storeTimeout)
+	defer cancel()
+
+	if err := t.m.store.SaveFile(ctx, t.job.ID, file); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.job.File = file.Name
+	return nil
+}
+
+func (t *Task) snapshot() Job {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.job
+}
+
+// touch marks an unfinished task as updated now, so other instances do not
+// take it for stale.
+func (t *Task) touch() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.job.Status.Finished() {
+		t.job.UpdatedAt = time.Now().UTC()
+	}
+}
+
+// start moves a pending task to running; it returns false if the task was
+// cancelled while it waited for a worker.
+func (t *Task) start() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.job.Status != StatusPending {
+		return false
+	}
+	t.job.Status = StatusRunning
+	t.job.UpdatedAt = time.Now().UTC()
+	t.saved = t.job.UpdatedAt
+	return true
+}
+
+// requestCancel cancels the task's context. A pending task is marked as
+// cancelled right away, which is reported by the return value.
+func (t *Task) requestCancel() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cancelled = true
+	t.cancel()
+
+	if t.job.Status != StatusPending {
+		return false
+	}
+	t.job.Status = StatusCancelled
+	t.job.UpdatedAt = time.Now().UTC()
+	return true
+}
+
+func (t *Task) finish(result interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if result != nil {
+		body, merr := json.Marshal(result)
+		if merr != nil && err == nil {
+			err = merr
+		}
+		t.job.Result = body
+	}
+
+	switch {
+	case t.cancelled:
+		t.job.Status = StatusCancelled
+	case err != nil:
+		t.job.Status = StatusFailed
+		t.job.Error = err.Error()
+	default:
+		t.job.Status = StatusSucceeded
+	}
+	t.job.UpdatedAt = time.Now().UTC()
+}
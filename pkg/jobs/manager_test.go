@@ -0,0 +1,136 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"microservice/pkg/logger"
+)
+
+func newTestManager(t *testing.T, store Store) *Manager {
+	t.Helper()
+	return NewManager(store, logger.New(logger.LevelPanic, "jobs"), Options{Workers: 1, QueueSize: 4, FileDir: t.TempDir()})
+}
+
+// wait polls id until the job finishes.
+func wait(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := m.Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status.Finished() {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return Job{}
+}
+
+func TestManagerFile(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, NewMemoryStore())
+
+	job, err := m.Submit(ctx, "export", "user:1", 0, func(ctx context.Context, t *Task) (interface{}, error) {
+		f, err := t.CreateFile()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if _, err := io.WriteString(f, "id\n1\n"); err != nil {
+			return nil, err
+		}
+		return nil, t.Attach(File{Name: "shops.csv", ContentType: "text/csv", Path: f.Name()})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job = wait(t, m, job.ID); job.Status != StatusSucceeded || job.File != "shops.csv" {
+		t.Fatalf("job = %+v, want succeeded with shops.csv", job)
+	}
+
+	file, err := m.File(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := file.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(content)
+	content.Close()
+	if string(body) != "id\n1\n" {
+		t.Errorf("file = %q", body)
+	}
+
+	m.deleteFiles(time.Now().Add(time.Minute))
+	if _, err := file.Open(); !errors.Is(err, ErrNoFile) {
+		t.Errorf("Open after deleteFiles: err = %v, want ErrNoFile", err)
+	}
+	if _, err := os.Stat(file.Path); !os.IsNotExist(err) {
+		t.Errorf("file %s was not removed", file.Path)
+	}
+}
+
+func TestManagerCancel(t *testing.T) {
+	ctx := context.Background()
+	m := newTestManager(t, NewMemoryStore())
+
+	started := make(chan struct{})
+	job, err := m.Submit(ctx, "bulk", "user:1", 1, func(ctx context.Context, t *Task) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	if _, err := m.Cancel(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if job = wait(t, m, job.ID); job.Status != StatusCancelled {
+		t.Errorf("status = %s, want cancelled", job.Status)
+	}
+	if _, err := m.Cancel(ctx, job.ID); !errors.Is(err, ErrFinished) {
+		t.Errorf("second Cancel: err = %v, want ErrFinished", err)
+	}
+	if _, err := m.File(ctx, job.ID); !errors.Is(err, ErrNoFile) {
+		t.Errorf("File: err = %v, want ErrNoFile", err)
+	}
+}
+
+func TestManagerFailsStaleJobs(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	old := time.Now().Add(-time.Hour).UTC()
+	for _, job := range []Job{
+		{ID: "running", Status: StatusRunning, UpdatedAt: old},
+		{ID: "pending", Status: StatusPending, UpdatedAt: old},
+		{ID: "done", Status: StatusSucceeded, UpdatedAt: old},
+		{ID: "live", Status: StatusRunning, UpdatedAt: time.Now().UTC()},
+	} {
+		store.Create(ctx, job)
+	}
+
+	m := newTestManager(t, store)
+	for _, id := range []string{"running", "pending"} {
+		if job := wait(t, m, id); job.Status != StatusFailed || job.Error != errInterrupted {
+			t.Errorf("job %s = %+v, want failed as interrupted", id, job)
+		}
+	}
+	for id, want := range map[string]Status{"done": StatusSucceeded, "live": StatusRunning} {
+		if job, _ := m.Get(ctx, id); job.Status != want {
+			t.Errorf("job %s status = %s, want %s", id, job.Status, want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps jobs in process memory. State is lost on restart and
+// not shared between gateway instances.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	jobs  map[string]Job
+	files map[string]File
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		jobs:  make(map[string]Job),
+		files: make(map[string]File),
+	}
+}
+
+// Create ...
+func (s *MemoryStore) Create(ctx context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Update ...
+func (s *MemoryStore) Update(ctx context.Context, job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[job.ID]; !ok {
+		return ErrNotFound
+	}
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Get ...
+func (s *MemoryStore) Get(ctx context.Context, id string) (Job, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// SaveFile ...
+func (s *MemoryStore) SaveFile(ctx context.Context, id string, file File) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return ErrNotFound
+	}
+	s.files[id] = file
+	return nil
+}
+
+// File ...
+func (s *MemoryStore) File(ctx context.Context, id string) (File, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, ok := s.jobs[id]; !ok {
+		return File{}, ErrNotFound
+	}
+	file, ok := s.files[id]
+	if !ok {
+		return File{}, ErrNoFile
+	}
+	return file, nil
+}
+
+// DeleteFinished ...
+func (s *MemoryStore) DeleteFinished(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, job := range s.jobs {
+		if job.Status.Finished() && job.UpdatedAt.Before(before) {
+			delete(s.jobs, id)
+			delete(s.files, id)
+		}
+	}
+	return nil
+}
+
+// FailStale ...
+func (s *MemoryStore) FailStale(ctx context.Context, before time.Time, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for id, job := range s.jobs {
+		if !job.Status.Finished() && job.UpdatedAt.Before(before) {
+			job.Status, job.Error, job.UpdatedAt = StatusFailed, reason, now
+			s.jobs[id] = job
+		}
+	}
+	return nil
+}
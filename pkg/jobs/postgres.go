@@ -0,0 +1,169 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS gateway_jobs (
+	id                VARCHAR(36) PRIMARY KEY,
+	type              VARCHAR NOT NULL,
+	status            VARCHAR NOT NULL,
+	total             INTEGER NOT NULL DEFAULT 0,
+	processed         INTEGER NOT NULL DEFAULT 0,
+	failed            INTEGER NOT NULL DEFAULT 0,
+	result            JSONB,
+	error             TEXT NOT NULL DEFAULT '',
+	file_name         VARCHAR NOT NULL DEFAULT '',
+	file_content_type VARCHAR NOT NULL DEFAULT '',
+	file_path         VARCHAR NOT NULL DEFAULT '',
+	owner             VARCHAR NOT NULL DEFAULT '',
+	created_at        TIMESTAMPTZ NOT NULL,
+	updated_at        TIMESTAMPTZ NOT NULL
+)`
+
+// PostgresStore keeps jobs in the gateway_jobs table, so their state
+// survives restarts and is visible to every gateway instance.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore creates the gateway_jobs table if it does not exist.
+func NewPostgresStore(ctx context.Context, db *pgxpool.Pool) (*PostgresStore, error) {
+	if _, err := db.Exec(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("create gateway_jobs table: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// Create ...
+func (s *PostgresStore) Create(ctx context.Context, job Job) error {
+	_, err := s.db.Exec(ctx, `
+		INSERT INTO gateway_jobs (id, type, status, total, processed, failed, result, error, owner, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		job.ID, job.Type, job.Status, job.Total, job.Processed, job.Failed,
+		nullJSON(job.Result), job.Error, job.Owner, job.CreatedAt, job.UpdatedAt,
+	)
+	return err
+}
+
+// Update ...
+func (s *PostgresStore) Update(ctx context.Context, job Job) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE gateway_jobs
+		SET status = $2, total = $3, processed = $4, failed = $5, result = $6, error = $7, updated_at = $8
+		WHERE id = $1`,
+		job.ID, job.Status, job.Total, job.Processed, job.Failed,
+		nullJSON(job.Result), job.Error, job.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Get ...
+func (s *PostgresStore) Get(ctx context.Context, id string) (Job, error) {
+	var (
+		job    Job
+		result []byte
+	)
+
+	err := s.db.QueryRow(ctx, `
+		SELECT id, type, status, total, processed, failed, result, error, file_name, owner, created_at, updated_at
+		FROM gateway_jobs
+		WHERE id = $1`, id,
+	).Scan(
+		&job.ID, &job.Type, &job.Status, &job.Total, &job.Processed, &job.Failed,
+		&result, &job.Error, &job.File, &job.Owner, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Job{}, ErrNotFound
+	}
+	if err != nil {
+		return Job{}, err
+	}
+
+	job.Result = result
+	job.CreatedAt = job.CreatedAt.UTC()
+	job.UpdatedAt = job.UpdatedAt.UTC()
+	return job, nil
+}
+
+// SaveFile ...
+func (s *PostgresStore) SaveFile(ctx context.Context, id string, file File) error {
+	tag, err := s.db.Exec(ctx, `
+		UPDATE gateway_jobs
+		SET file_name = $2, file_content_type = $3, file_path = $4
+		WHERE id = $1`,
+		id, file.Name, file.ContentType, file.Path,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// File ...
+func (s *PostgresStore) File(ctx context.Context, id string) (File, error) {
+	var file File
+
+	err := s.db.QueryRow(ctx, `
+		SELECT file_name, file_content_type, file_path
+		FROM gateway_jobs
+		WHERE id = $1`, id,
+	).Scan(&file.Name, &file.ContentType, &file.Path)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return File{}, ErrNotFound
+	}
+	if err != nil {
+		return File{}, err
+	}
+	if file.Name == "" {
+		return File{}, ErrNoFile
+	}
+
+	return file, nil
+}
+
+// DeleteFinished ...
+func (s *PostgresStore) DeleteFinished(ctx context.Context, before time.Time) error {
+	_, err := s.db.Exec(ctx, `
+		DELETE FROM gateway_jobs
+		WHERE status IN ($1, $2, $3) AND updated_at < $4`,
+		StatusSucceeded, StatusFailed, StatusCancelled, before,
+	)
+	return err
+}
+
+// FailStale ...
+func (s *PostgresStore) FailStale(ctx context.Context, before time.Time, reason string) error {
+	_, err := s.db.Exec(ctx, `
+		UPDATE gateway_jobs
+		SET status = $1, error = $2, updated_at = $3
+		WHERE status IN ($4, $5) AND updated_at < $6`,
+		StatusFailed, reason, time.Now().UTC(), StatusPending, StatusRunning, before,
+	)
+	return err
+}
+
+// nullJSON stores an empty result as NULL.
+func nullJSON(v []byte) interface{} {
+	if len(v) == 0 {
+		return nil
+	}
+	return string(v)
+}
@@ -0,0 +1,21 @@
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps job state. Get and File return ErrNotFound for unknown IDs;
+// File returns ErrNoFile when the job has no file.
+type Store interface {
+	Create(ctx context.Context, job Job) error
+	Update(ctx context.Context, job Job) error
+	Get(ctx context.Context, id string) (Job, error)
+	SaveFile(ctx context.Context, id string, file File) error
+	File(ctx context.Context, id string) (File, error)
+	// DeleteFinished removes finished jobs last updated before t.
+	DeleteFinished(ctx context.Context, before time.Time) error
+	// FailStale marks pending and running jobs last updated before t as
+	// failed with reason; the instance running them is gone.
+	FailStale(ctx context.Context, before time.Time, reason string) error
+}
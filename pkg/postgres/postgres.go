@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"microservice/config"
+	"net/url"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// New connects to the database described by the Postgres* config.
+func New(ctx context.Context, cfg config.Config) (*pgxpool.Pool, error) {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.PostgresUser, cfg.PostgresPassword),
+		Host:     fmt.Sprintf("%s:%d", cfg.PostgresHost, cfg.PostgresPort),
+		Path:     cfg.PostgresDatabase,
+		RawQuery: "sslmode=disable",
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	if cfg.PostgresMaxConnections > 0 {
+		poolConfig.MaxConns = cfg.PostgresMaxConnections
+	}
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Ping(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
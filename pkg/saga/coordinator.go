@@ -0,0 +1,300 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"microservice/pkg/logger"
+	"microservice/pkg/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	storeTimeout = 5 * time.Second
+	// undoAttempts is how many times a compensation is tried before the
+	// saga is marked failed, pausing undoPause longer after every failure.
+	undoAttempts = 3
+	undoPause    = 200 * time.Millisecond
+)
+
+// Options ...
+type Options struct {
+	// StepTimeout bounds each Do and Undo call.
+	StepTimeout time.Duration
+	// StaleAfter is how long an unfinished saga may go without an update
+	// before it is considered abandoned and resumed. It has to be longer
+	// than StepTimeout plus the longest pause between compensation
+	// attempts, which are saved one by one.
+	StaleAfter time.Duration
+	// Retention is how long finished sagas are kept; zero keeps them forever.
+	Retention time.Duration
+}
+
+// Coordinator runs sagas step by step, persisting their state after every
+// step, and undoes the completed steps in reverse order when one fails.
+// Sagas abandoned by a stopped gateway are picked up by Recover.
+type Coordinator struct {
+	store Store
+	log   logger.Logger
+	opts  Options
+
+	mu   sync.RWMutex
+	defs map[string]Definition
+}
+
+// NewCoordinator ...
+func NewCoordinator(store Store, log logger.Logger, opts Options) *Coordinator {
+	if opts.StepTimeout <= 0 {
+		opts.StepTimeout = 30 * time.Second
+	}
+	if opts.StaleAfter <= opts.StepTimeout+undoAttempts*undoPause {
+		opts.StaleAfter = 2*opts.StepTimeout + undoAttempts*undoPause
+	}
+
+	return &Coordinator{
+		store: store,
+		log:   log,
+		opts:  opts,
+		defs:  make(map[string]Definition),
+	}
+}
+
+// Register makes sagas of def.Type runnable.
+func (c *Coordinator) Register(def Definition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.defs[def.Type] = def
+}
+
+func (c *Coordinator) definition(typ string) (Definition, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	def, ok := c.defs[typ]
+	return def, ok
+}
+
+// Start stores a new saga of typ with input and runs it to the end. The
+// saga keeps running if ctx is cancelled; the returned state is final
+// unless the store failed.
+func (c *Coordinator) Start(ctx context.Context, typ string, input interface{}) (Saga, error) {
+	def, ok := c.definition(typ)
+	if !ok {
+		return Saga{}, ErrUnknownType
+	}
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		return Saga{}, err
+	}
+
+	id, err := uuid.New()
+	if err != nil {
+		return Saga{}, err
+	}
+
+	t := now()
+	s := Saga{
+		ID:        id,
+		Type:      typ,
+		Status:    StatusRunning,
+		Input:     body,
+		Steps:     make([]StepState, len(def.Steps)),
+		CreatedAt: t,
+		UpdatedAt: t,
+	}
+	for i, step := range def.Steps {
+		s.Steps[i] = StepState{Name: step.Name, Status: StepPending, UpdatedAt: t}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	if err := withTimeout(ctx, storeTimeout, func(ctx context.Context) error { return c.store.Create(ctx, s) }); err != nil {
+		return Saga{}, err
+	}
+
+	return c.run(ctx, def, s)
+}
+
+// Get ...
+func (c *Coordinator) Get(ctx context.Context, id string) (Saga, error) {
+	return c.store.Get(ctx, id)
+}
+
+// run drives s forward and, once a step failed, backward until it finishes.
+// It stops early with ErrConflict when another instance took the saga over.
+func (c *Coordinator) run(ctx context.Context, def Definition, s Saga) (Saga, error) {
+	var err error
+
+	for s.Status == StatusRunning {
+		i := 0
+		for i < len(s.Steps) && s.Steps[i].Status == StepDone {
+			i++
+		}
+		if i == len(s.Steps) {
+			s.Status = StatusSucceeded
+			return c.save(ctx, s)
+		}
+
+		s.setStep(i, StepRunning, "")
+		s.Steps[i].Attempts++
+		if s, err = c.save(ctx, s); err != nil {
+			return s, err
+		}
+
+		var id string
+		stepErr := withTimeout(ctx, c.opts.StepTimeout, func(ctx context.Context) error {
+			id, err = def.Steps[i].Do(ctx, &s)
+			return err
+		})
+
+		if stepErr != nil {
+			s.setStep(i, StepFailed, stepErr.Error())
+			s.Status = StatusCompensating
+			s.Error = fmt.Sprintf("%s: %s", s.Steps[i].Name, stepErr)
+		} else {
+			s.setStep(i, StepDone, "")
+			s.Steps[i].ResourceID = id
+		}
+		if s, err = c.save(ctx, s); err != nil {
+			return s, err
+		}
+	}
+
+	if s.Status != StatusCompensating {
+		return s, nil
+	}
+
+	failed := false
+	for i := len(s.Steps) - 1; i >= 0; i-- {
+		if s.Steps[i].Status != StepDone && s.Steps[i].Status != StepCompensationFailed {
+			continue
+		}
+
+		undoErr, err := c.undo(ctx, def.Steps[i], &s, i)
+		if err != nil {
+			return s, err
+		}
+		if undoErr != nil {
+			failed = true
+			s.setStep(i, StepCompensationFailed, undoErr.Error())
+			c.log.Error("saga compensation failed", logger.String("saga", s.ID), logger.String("step", s.Steps[i].Name), logger.Error(undoErr))
+		} else {
+			s.setStep(i, StepCompensated, "")
+		}
+		if s, err = c.save(ctx, s); err != nil {
+			return s, err
+		}
+	}
+
+	s.Status = StatusCompensated
+	if failed {
+		s.Status = StatusFailed
+	}
+	return c.save(ctx, s)
+}
+
+// undo runs the compensation of step i, retrying with a growing pause. Each
+// failed attempt is saved, so that a saga whose compensation takes several
+// tries does not look abandoned to Recover. It returns the compensation's
+// error and, separately, the error saving s.
+func (c *Coordinator) undo(ctx context.Context, step Step, s *Saga, i int) (undoErr, err error) {
+	if step.Undo == nil || s.Steps[i].ResourceID == "" {
+		return nil, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		undoErr = withTimeout(ctx, c.opts.StepTimeout, func(ctx context.Context) error {
+			return step.Undo(ctx, s, s.Steps[i].ResourceID)
+		})
+		if undoErr == nil || attempt == undoAttempts {
+			return undoErr, nil
+		}
+
+		s.setStep(i, s.Steps[i].Status, undoErr.Error())
+		if *s, err = c.save(ctx, *s); err != nil {
+			return undoErr, err
+		}
+		time.Sleep(time.Duration(attempt) * undoPause)
+	}
+}
+
+// save stores s with a new UpdatedAt, expecting the stored copy to still be
+// the one this instance wrote last.
+func (c *Coordinator) save(ctx context.Context, s Saga) (Saga, error) {
+	expected := s.UpdatedAt
+	s.UpdatedAt = now()
+
+	err := withTimeout(ctx, storeTimeout, func(ctx context.Context) error { return c.store.Update(ctx, s, expected) })
+	if err != nil {
+		c.log.Error("failed to save saga", logger.String("saga", s.ID), logger.Error(err))
+		return s, err
+	}
+	return s, nil
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return fn(ctx)
+}
+
+// Recover resumes the unfinished sagas nobody has updated for StaleAfter.
+// A saga is only resumed by the instance whose first save succeeds.
+func (c *Coordinator) Recover(ctx context.Context) error {
+	var stale []Saga
+	err := withTimeout(ctx, storeTimeout, func(ctx context.Context) (err error) {
+		stale, err = c.store.ListStale(ctx, now().Add(-c.opts.StaleAfter))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stale {
+		def, ok := c.definition(s.Type)
+		if !ok {
+			c.log.Warn("cannot resume saga of unknown type", logger.String("saga", s.ID), logger.String("type", s.Type))
+			continue
+		}
+
+		if s, err = c.save(ctx, s); err != nil {
+			if errors.Is(err, ErrConflict) {
+				continue
+			}
+			return err
+		}
+
+		c.log.Info("resuming saga", logger.String("saga", s.ID), logger.String("status", string(s.Status)))
+		if _, err := c.run(ctx, def, s); err != nil && !errors.Is(err, ErrConflict) {
+			c.log.Error("failed to resume saga", logger.String("saga", s.ID), logger.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// RunRecovery calls Recover and deletes old finished sagas every interval.
+// It does not return.
+func (c *Coordinator) RunRecovery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := c.Recover(context.Background()); err != nil {
+			c.log.Error("failed to recover sagas", logger.Error(err))
+		}
+
+		if c.opts.Retention <= 0 {
+			continue
+		}
+		err := withTimeout(context.Background(), storeTimeout, func(ctx context.Context) error {
+			return c.store.DeleteFinished(ctx, now().Add(-c.opts.Retention))
+		})
+		if err != nil {
+			c.log.Error("failed to delete finished sagas", logger.Error(err))
+		}
+	}
+}
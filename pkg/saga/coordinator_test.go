@@ -0,0 +1,136 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"microservice/pkg/logger"
+)
+
+func newTestCoordinator(store Store, steps ...Step) *Coordinator {
+	c := NewCoordinator(store, logger.New(logger.LevelPanic, "test"), Options{StepTimeout: time.Second})
+	c.Register(Definition{Type: "test", Steps: steps})
+	return c
+}
+
+// recorder is a step that creates resource name and logs what it does.
+func recorder(name string, log *[]string, fail error) Step {
+	return Step{
+		Name: name,
+		Do: func(ctx context.Context, s *Saga) (string, error) {
+			*log = append(*log, "do "+name)
+			return name, fail
+		},
+		Undo: func(ctx context.Context, s *Saga, id string) error {
+			*log = append(*log, "undo "+id)
+			return nil
+		},
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		fail   error
+		status Status
+		log    []string
+	}{
+		{"success", nil, StatusSucceeded, []string{"do a", "do b", "do c"}},
+		{"compensated", errors.New("boom"), StatusCompensated, []string{"do a", "do b", "do c", "undo b", "undo a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log []string
+			store := NewMemoryStore()
+			c := newTestCoordinator(store, recorder("a", &log, nil), recorder("b", &log, nil), recorder("c", &log, tt.fail))
+
+			s, err := c.Start(context.Background(), "test", map[string]string{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Status != tt.status || !reflect.DeepEqual(log, tt.log) {
+				t.Errorf("saga %s ran %v, want %s after %v", s.Status, log, tt.status, tt.log)
+			}
+			if stored, _ := store.Get(context.Background(), s.ID); stored.Status != tt.status {
+				t.Errorf("stored status = %s, want %s", stored.Status, tt.status)
+			}
+		})
+	}
+}
+
+func TestUndoSavesEachAttempt(t *testing.T) {
+	var (
+		store = NewMemoryStore()
+		seen  []time.Time
+		c     *Coordinator
+	)
+	c = newTestCoordinator(store, Step{
+		Name: "create",
+		Do:   func(ctx context.Context, s *Saga) (string, error) { return "1", nil },
+		Undo: func(ctx context.Context, s *Saga, id string) error {
+			stored, _ := store.Get(ctx, s.ID)
+			seen = append(seen, stored.UpdatedAt)
+			if len(seen) < undoAttempts {
+				return errors.New("unavailable")
+			}
+			return nil
+		},
+	}, Step{
+		Name: "fail",
+		Do:   func(ctx context.Context, s *Saga) (string, error) { return "", errors.New("boom") },
+	})
+
+	s, err := c.Start(context.Background(), "test", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != StatusCompensated || len(seen) != undoAttempts {
+		t.Fatalf("saga %s after %d undo attempts, want compensated after %d", s.Status, len(seen), undoAttempts)
+	}
+	for i := 1; i < len(seen); i++ {
+		if !seen[i].After(seen[i-1]) {
+			t.Errorf("undo attempt %d saw the saga saved at %v, not after attempt %d", i+1, seen[i], i)
+		}
+	}
+
+	if budget := c.opts.StepTimeout + undoAttempts*undoPause; c.opts.StaleAfter <= budget {
+		t.Errorf("StaleAfter = %v, want more than %v", c.opts.StaleAfter, budget)
+	}
+}
+
+func TestRecoverRerunsInterruptedStep(t *testing.T) {
+	var attempts []int
+	store := NewMemoryStore()
+	c := newTestCoordinator(store, Step{
+		Name: "create",
+		Do: func(ctx context.Context, s *Saga) (string, error) {
+			attempts = append(attempts, s.Attempts("create"))
+			return "1", nil
+		},
+	})
+
+	old := now().Add(-time.Hour)
+	err := store.Create(context.Background(), Saga{
+		ID:        "interrupted",
+		Type:      "test",
+		Status:    StatusRunning,
+		Steps:     []StepState{{Name: "create", Status: StepRunning, Attempts: 1, UpdatedAt: old}},
+		CreatedAt: old,
+		UpdatedAt: old,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := store.Get(context.Background(), "interrupted")
+	if s.Status != StatusSucceeded || s.ResourceID("create") != "1" || !reflect.DeepEqual(attempts, []int{2}) {
+		t.Errorf("recovered saga %s with resource %q after attempts %v, want succeeded with 1 after [2]", s.Status, s.ResourceID("create"), attempts)
+	}
+}
@@ -0,0 +1,89 @@
+package saga
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps sagas in process memory. State is lost on restart, so
+// sagas interrupted by a restart are neither resumed nor compensated.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	sagas map[string]Saga
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sagas: make(map[string]Saga)}
+}
+
+// Create ...
+func (m *MemoryStore) Create(ctx context.Context, s Saga) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sagas[s.ID] = copySaga(s)
+	return nil
+}
+
+// Update ...
+func (m *MemoryStore) Update(ctx context.Context, s Saga, expected time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.sagas[s.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if !stored.UpdatedAt.Equal(expected) {
+		return ErrConflict
+	}
+	m.sagas[s.ID] = copySaga(s)
+	return nil
+}
+
+// Get ...
+func (m *MemoryStore) Get(ctx context.Context, id string) (Saga, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	s, ok := m.sagas[id]
+	if !ok {
+		return Saga{}, ErrNotFound
+	}
+	return copySaga(s), nil
+}
+
+// ListStale ...
+func (m *MemoryStore) ListStale(ctx context.Context, before time.Time) ([]Saga, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stale []Saga
+	for _, s := range m.sagas {
+		if !s.Status.Finished() && s.UpdatedAt.Before(before) {
+			stale = append(stale, copySaga(s))
+		}
+	}
+	return stale, nil
+}
+
+// DeleteFinished ...
+func (m *MemoryStore) DeleteFinished(ctx context.Context, before time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sagas {
+		if s.Status.Finished() && s.UpdatedAt.Before(before) {
+			delete(m.sagas, id)
+		}
+	}
+	return nil
+}
+
+// copySaga keeps callers from sharing the stored steps.
+func copySaga(s Saga) Saga {
+	s.Steps = append([]StepState(nil), s.Steps...)
+	return s
+}
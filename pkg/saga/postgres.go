@@ -0,0 +1,142 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS gateway_sagas (
+	id         VARCHAR(36) PRIMARY KEY,
+	type       VARCHAR NOT NULL,
+	status     VARCHAR NOT NULL,
+	input      JSONB NOT NULL,
+	steps      JSONB NOT NULL,
+	error      TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS gateway_sagas_status_updated_at ON gateway_sagas (status, updated_at)`
+
+// PostgresStore keeps sagas in the gateway_sagas table, so that they can be
+// resumed after a restart by any gateway instance.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore creates the gateway_sagas table if it does not exist.
+func NewPostgresStore(ctx context.Context, db *pgxpool.Pool) (*PostgresStore, error) {
+	if _, err := db.Exec(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("create gateway_sagas table: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// Create ...
+func (p *PostgresStore) Create(ctx context.Context, s Saga) error {
+	steps, err := json.Marshal(s.Steps)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec(ctx, `
+		INSERT INTO gateway_sagas (id, type, status, input, steps, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		s.ID, s.Type, s.Status, string(s.Input), string(steps), s.Error, s.CreatedAt, s.UpdatedAt,
+	)
+	return err
+}
+
+// Update ...
+func (p *PostgresStore) Update(ctx context.Context, s Saga, expected time.Time) error {
+	steps, err := json.Marshal(s.Steps)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.db.Exec(ctx, `
+		UPDATE gateway_sagas
+		SET status = $2, steps = $3, error = $4, updated_at = $5
+		WHERE id = $1 AND updated_at = $6`,
+		s.ID, s.Status, string(steps), s.Error, s.UpdatedAt, expected,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		if _, err := p.Get(ctx, s.ID); err != nil {
+			return err
+		}
+		return ErrConflict
+	}
+	return nil
+}
+
+const selectSaga = `SELECT id, type, status, input, steps, error, created_at, updated_at FROM gateway_sagas`
+
+func scanSaga(row pgx.Row) (Saga, error) {
+	var (
+		s            Saga
+		input, steps []byte
+	)
+
+	err := row.Scan(&s.ID, &s.Type, &s.Status, &input, &steps, &s.Error, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return Saga{}, err
+	}
+	if err := json.Unmarshal(steps, &s.Steps); err != nil {
+		return Saga{}, err
+	}
+
+	s.Input = input
+	s.CreatedAt = s.CreatedAt.UTC()
+	s.UpdatedAt = s.UpdatedAt.UTC()
+	return s, nil
+}
+
+// Get ...
+func (p *PostgresStore) Get(ctx context.Context, id string) (Saga, error) {
+	s, err := scanSaga(p.db.QueryRow(ctx, selectSaga+` WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Saga{}, ErrNotFound
+	}
+	return s, err
+}
+
+// ListStale ...
+func (p *PostgresStore) ListStale(ctx context.Context, before time.Time) ([]Saga, error) {
+	rows, err := p.db.Query(ctx, selectSaga+` WHERE status IN ($1, $2) AND updated_at < $3 ORDER BY updated_at`,
+		StatusRunning, StatusCompensating, before,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stale []Saga
+	for rows.Next() {
+		s, err := scanSaga(rows)
+		if err != nil {
+			return nil, err
+		}
+		stale = append(stale, s)
+	}
+	return stale, rows.Err()
+}
+
+// DeleteFinished ...
+func (p *PostgresStore) DeleteFinished(ctx context.Context, before time.Time) error {
+	_, err := p.db.Exec(ctx, `
+		DELETE FROM gateway_sagas
+		WHERE status IN ($1, $2, $3) AND updated_at < $4`,
+		StatusSucceeded, StatusCompensated, StatusFailed, before,
+	)
+	return err
+}
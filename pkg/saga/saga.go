@@ -0,0 +1,136 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Status ...
+type Status string
+
+const (
+	// StatusRunning ...
+	StatusRunning Status = "running"
+	// StatusCompensating means a step failed and the completed steps are
+	// being undone.
+	StatusCompensating Status = "compensating"
+	// StatusSucceeded ...
+	StatusSucceeded Status = "succeeded"
+	// StatusCompensated means a step failed and every completed step was
+	// undone.
+	StatusCompensated Status = "compensated"
+	// StatusFailed means a step failed and some completed step could not be
+	// undone; its resource has to be cleaned up by hand.
+	StatusFailed Status = "failed"
+)
+
+// Finished reports whether a saga in status s will not change anymore.
+func (s Status) Finished() bool {
+	return s == StatusSucceeded || s == StatusCompensated || s == StatusFailed
+}
+
+// StepStatus ...
+type StepStatus string
+
+const (
+	// StepPending ...
+	StepPending StepStatus = "pending"
+	// StepRunning ...
+	StepRunning StepStatus = "running"
+	// StepDone ...
+	StepDone StepStatus = "done"
+	// StepFailed ...
+	StepFailed StepStatus = "failed"
+	// StepCompensated ...
+	StepCompensated StepStatus = "compensated"
+	// StepCompensationFailed ...
+	StepCompensationFailed StepStatus = "compensation_failed"
+)
+
+var (
+	// ErrNotFound is returned for unknown saga IDs.
+	ErrNotFound = errors.New("saga not found")
+	// ErrConflict is returned by Store.Update when the saga was changed
+	// since it was read, which means another gateway instance runs it.
+	ErrConflict = errors.New("saga was updated concurrently")
+	// ErrUnknownType is returned by Start for unregistered saga types.
+	ErrUnknownType = errors.New("unknown saga type")
+)
+
+// StepState is the progress of one step as reported to clients.
+// ResourceID is what the step created, which its compensation deletes.
+type StepState struct {
+	Name       string     `json:"name"`
+	Status     StepStatus `json:"status"`
+	ResourceID string     `json:"resource_id,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	Attempts   int        `json:"attempts"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Saga is the persisted state of a multi-step operation. Input is the JSON
+// request the steps are built from.
+type Saga struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	Status    Status          `json:"status"`
+	Input     json.RawMessage `json:"input" swaggertype:"object"`
+	Steps     []StepState     `json:"steps"`
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// ResourceID returns what the step named step created, or "".
+func (s *Saga) ResourceID(step string) string {
+	for _, st := range s.Steps {
+		if st.Name == step {
+			return st.ResourceID
+		}
+	}
+	return ""
+}
+
+// Attempts returns how many times the step named step was started. More
+// than one means an earlier run may have done it already.
+func (s *Saga) Attempts(step string) int {
+	for _, st := range s.Steps {
+		if st.Name == step {
+			return st.Attempts
+		}
+	}
+	return 0
+}
+
+func (s *Saga) setStep(i int, status StepStatus, err string) {
+	s.Steps[i].Status = status
+	s.Steps[i].Error = err
+	s.Steps[i].UpdatedAt = now()
+}
+
+// Step is one action of a saga and the action that reverses it.
+type Step struct {
+	Name string
+	// Do performs the step and returns the ID of the resource it created.
+	// A step that was running when the gateway stopped is run again, so a
+	// Do that already succeeded may be called twice; it has to find and
+	// return what the earlier call created rather than create it again.
+	Do func(ctx context.Context, s *Saga) (string, error)
+	// Undo deletes the resource created by Do. Steps without side effects
+	// leave it nil.
+	Undo func(ctx context.Context, s *Saga, resourceID string) error
+}
+
+// Definition is a named sequence of steps.
+type Definition struct {
+	Type  string
+	Steps []Step
+}
+
+// now is truncated to what Postgres stores, so that the UpdatedAt read back
+// compares equal in Store.Update.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,19 @@
+package saga
+
+import (
+	"context"
+	"time"
+)
+
+// Store keeps saga state. Get returns ErrNotFound for unknown IDs.
+type Store interface {
+	Create(ctx context.Context, s Saga) error
+	// Update replaces the saga if its stored UpdatedAt still equals
+	// expected and returns ErrConflict otherwise.
+	Update(ctx context.Context, s Saga, expected time.Time) error
+	Get(ctx context.Context, id string) (Saga, error)
+	// ListStale returns unfinished sagas last updated before t.
+	ListStale(ctx context.Context, before time.Time) ([]Saga, error)
+	// DeleteFinished removes finished sagas last updated before t.
+	DeleteFinished(ctx context.Context, before time.Time) error
+}
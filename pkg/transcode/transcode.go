@@ -0,0 +1,349 @@
+// Package transcode maps gRPC methods to HTTP routes with the
+// google.api.http annotations of their descriptors, and fills their request
+// messages from the path and query of HTTP requests.
+package transcode
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Route is an HTTP binding of a gRPC method.
+type Route struct {
+	// Method is the HTTP method and Path the gin path, e.g. /v1/shops/:id,
+	// whose parameters are named after the request fields they set.
+	Method string
+	Path   string
+	RPC    protoreflect.MethodDescriptor
+
+	params       []string
+	body         string
+	responseBody string
+}
+
+// Routes returns the routes of every annotated method of the services in
+// package pkg, sorted by path and method. Methods without an annotation are
+// not exposed.
+func Routes(pkg protoreflect.FullName) ([]Route, error) {
+	var (
+		routes []Route
+		err    error
+	)
+	protoregistry.GlobalFiles.RangeFilesByPackage(pkg, func(fd protoreflect.FileDescriptor) bool {
+		for i := 0; i < fd.Services().Len() && err == nil; i++ {
+			sd := fd.Services().Get(i)
+			for j := 0; j < sd.Methods().Len() && err == nil; j++ {
+				var rs []Route
+				if rs, err = methodRoutes(sd.Methods().Get(j)); err == nil {
+					routes = append(routes, rs...)
+				}
+			}
+		}
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
+
+func methodRoutes(md protoreflect.MethodDescriptor) ([]Route, error) {
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, nil
+	}
+	rule, _ := proto.GetExtension(md.Options(), annotations.E_Http).(*annotations.HttpRule)
+	if rule == nil {
+		return nil, nil
+	}
+
+	var routes []Route
+	for _, r := range append([]*annotations.HttpRule{rule}, rule.AdditionalBindings...) {
+		route, err := newRoute(md, r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", md.FullName(), err)
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+func newRoute(md protoreflect.MethodDescriptor, rule *annotations.HttpRule) (Route, error) {
+	route := Route{RPC: md, body: rule.Body, responseBody: rule.ResponseBody}
+
+	var template string
+	switch p := rule.Pattern.(type) {
+	case *annotations.HttpRule_Get:
+		route.Method, template = "GET", p.Get
+	case *annotations.HttpRule_Put:
+		route.Method, template = "PUT", p.Put
+	case *annotations.HttpRule_Post:
+		route.Method, template = "POST", p.Post
+	case *annotations.HttpRule_Delete:
+		route.Method, template = "DELETE", p.Delete
+	case *annotations.HttpRule_Patch:
+		route.Method, template = "PATCH", p.Patch
+	case *annotations.HttpRule_Custom:
+		route.Method, template = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return route, errors.New("the http rule has no pattern")
+	}
+
+	path, params, err := parseTemplate(template)
+	if err != nil {
+		return route, err
+	}
+	route.Path, route.params = path, params
+
+	for _, param := range params {
+		fd, err := fieldByPath(md.Input(), param)
+		if err != nil {
+			return route, err
+		}
+		if fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+			return route, fmt.Errorf("path parameter %s must be a singular scalar field", param)
+		}
+	}
+	if route.body != "" && route.body != "*" {
+		fd := md.Input().Fields().ByName(protoreflect.Name(route.body))
+		if fd == nil || fd.IsList() || fd.Kind() != protoreflect.MessageKind {
+			return route, fmt.Errorf("body %s must be a message field of %s", route.body, md.Input().FullName())
+		}
+	}
+	if route.responseBody != "" {
+		fd := md.Output().Fields().ByName(protoreflect.Name(route.responseBody))
+		if fd == nil || fd.IsList() || fd.Kind() != protoreflect.MessageKind {
+			return route, fmt.Errorf("response_body %s must be a message field of %s", route.responseBody, md.Output().FullName())
+		}
+	}
+	return route, nil
+}
+
+// parseTemplate converts a path template to a gin path. Variables match one
+// segment, {field} or {field=*}, or, as the last segment, the rest of the
+// path, {field=**}; custom verbs are not supported.
+func parseTemplate(template string) (string, []string, error) {
+	if !strings.HasPrefix(template, "/") {
+		return "", nil, fmt.Errorf("path %q must start with /", template)
+	}
+
+	var (
+		segments = strings.Split(template[1:], "/")
+		params   []string
+	)
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "{") {
+			if strings.ContainsAny(seg, "{}:*") {
+				return "", nil, fmt.Errorf("path %q: segment %q is not supported", template, seg)
+			}
+			continue
+		}
+		if !strings.HasSuffix(seg, "}") {
+			return "", nil, fmt.Errorf("path %q: custom verbs and partial segments are not supported", template)
+		}
+
+		field, pattern, _ := strings.Cut(seg[1:len(seg)-1], "=")
+		switch {
+		case pattern == "" || pattern == "*":
+			segments[i] = ":" + field
+		case pattern == "**" && i == len(segments)-1:
+			segments[i] = "*" + field
+		default:
+			return "", nil, fmt.Errorf("path %q: variable pattern %q is not supported", template, pattern)
+		}
+		params = append(params, field)
+	}
+	return "/" + strings.Join(segments, "/"), params, nil
+}
+
+// NewRequest returns an empty request message of the method.
+func (r Route) NewRequest() (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(r.RPC.Input().FullName())
+	if err != nil {
+		return nil, err
+	}
+	return mt.New().Interface(), nil
+}
+
+// Body returns the message of req the request body is decoded into: req
+// itself, one of its fields, or nil when the route takes no body.
+func (r Route) Body(req proto.Message) proto.Message {
+	switch r.body {
+	case "":
+		return nil
+	case "*":
+		return req
+	}
+	m := req.ProtoReflect()
+	return m.Mutable(m.Descriptor().Fields().ByName(protoreflect.Name(r.body))).Message().Interface()
+}
+
+// Decode sets the fields of req bound by the path, whose values param
+// returns by field, and, unless the body holds the whole request, those
+// named by query parameters. Query parameters that name no field are
+// ignored, as are those naming fields the path sets.
+func (r Route) Decode(req proto.Message, param func(string) string, query url.Values) error {
+	for _, name := range r.params {
+		value := strings.TrimPrefix(param(name), "/")
+		if err := setField(req.ProtoReflect(), name, []string{value}); err != nil {
+			return err
+		}
+	}
+
+	if r.body == "*" {
+		return nil
+	}
+	for key, values := range query {
+		if contains(r.params, key) || r.body != "" && (key == r.body || strings.HasPrefix(key, r.body+".")) {
+			continue
+		}
+		if _, err := fieldByPath(req.ProtoReflect().Descriptor(), key); err != nil {
+			continue
+		}
+		if err := setField(req.ProtoReflect(), key, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Response returns the part of resp that is the response body.
+func (r Route) Response(resp proto.Message) proto.Message {
+	if r.responseBody == "" {
+		return resp
+	}
+	m := resp.ProtoReflect()
+	return m.Get(m.Descriptor().Fields().ByName(protoreflect.Name(r.responseBody))).Message().Interface()
+}
+
+// fieldByPath finds the field a dotted path of proto or JSON names refers to.
+func fieldByPath(md protoreflect.MessageDescriptor, path string) (protoreflect.FieldDescriptor, error) {
+	var fd protoreflect.FieldDescriptor
+	for i, name := range strings.Split(path, ".") {
+		if i > 0 {
+			if fd.Kind() != protoreflect.MessageKind || fd.IsList() || fd.IsMap() {
+				return nil, fmt.Errorf("%s is not a field of %s", path, md.FullName())
+			}
+			md = fd.Message()
+		}
+		if fd = md.Fields().ByName(protoreflect.Name(name)); fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("%s is not a field of %s", path, md.FullName())
+		}
+	}
+	return fd, nil
+}
+
+func setField(m protoreflect.Message, path string, values []string) error {
+	names := strings.Split(path, ".")
+	for _, name := range names[:len(names)-1] {
+		fd, _ := fieldByPath(m.Descriptor(), name)
+		m = m.Mutable(fd).Message()
+	}
+	fd, err := fieldByPath(m.Descriptor(), names[len(names)-1])
+	if err != nil {
+		return err
+	}
+
+	if fd.IsList() {
+		list := m.Mutable(fd).List()
+		for _, value := range values {
+			v, err := parseValue(fd, value)
+			if err != nil {
+				return err
+			}
+			list.Append(v)
+		}
+		return nil
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	v, err := parseValue(fd, values[len(values)-1])
+	if err != nil {
+		return err
+	}
+	m.Set(fd, v)
+	return nil
+}
+
+func parseValue(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	var (
+		v   protoreflect.Value
+		err error
+	)
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.BytesKind:
+		return protoreflect.ValueOfBytes([]byte(value)), nil
+	case protoreflect.BoolKind:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		var n int64
+		n, err = strconv.ParseInt(value, 10, 32)
+		v = protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		var n int64
+		n, err = strconv.ParseInt(value, 10, 64)
+		v = protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		var n uint64
+		n, err = strconv.ParseUint(value, 10, 32)
+		v = protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		var n uint64
+		n, err = strconv.ParseUint(value, 10, 64)
+		v = protoreflect.ValueOfUint64(n)
+	case protoreflect.FloatKind:
+		var f float64
+		f, err = strconv.ParseFloat(value, 32)
+		v = protoreflect.ValueOfFloat32(float32(f))
+	case protoreflect.DoubleKind:
+		var f float64
+		f, err = strconv.ParseFloat(value, 64)
+		v = protoreflect.ValueOfFloat64(f)
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		var n int64
+		n, err = strconv.ParseInt(value, 10, 32)
+		v = protoreflect.ValueOfEnum(protoreflect.EnumNumber(n))
+	default:
+		return v, fmt.Errorf("%s cannot be set from the url", fd.Name())
+	}
+	if err != nil {
+		return v, fmt.Errorf("invalid %s %q", fd.Name(), value)
+	}
+	return v, nil
+}
+
+func contains(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package transcode
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+
+	pb "microservice/genproto/user_service"
+
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		params   []string
+		wantErr  bool
+	}{
+		{"/v1/shops", "/v1/shops", nil, false},
+		{"/v1/shops/{id}", "/v1/shops/:id", []string{"id"}, false},
+		{"/v1/shops/{id=*}/full", "/v1/shops/:id/full", []string{"id"}, false},
+		{"/v1/files/{name=**}", "/v1/files/*name", []string{"name"}, false},
+		{"v1/shops", "", nil, true},
+		{"/v1/files/{name=**}/meta", "", nil, true},
+		{"/v1/shops/{id=shops/*}", "", nil, true},
+		{"/v1/shops/{id}:restore", "", nil, true},
+		{"/v1/shops/x{id}", "", nil, true},
+	}
+	for _, tt := range tests {
+		path, params, err := parseTemplate(tt.template)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTemplate(%q) error = %v, want error %v", tt.template, err, tt.wantErr)
+			continue
+		}
+		if path != tt.path || !reflect.DeepEqual(params, tt.params) {
+			t.Errorf("parseTemplate(%q) = %q, %q, want %q, %q", tt.template, path, params, tt.path, tt.params)
+		}
+	}
+}
+
+func TestRoutes(t *testing.T) {
+	routes, err := Routes("user_service")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	for _, r := range routes {
+		got[r.Method+" "+r.Path] = string(r.RPC.FullName())
+	}
+	for route, rpc := range map[string]string{
+		"GET /v1/shops/:id":        "user_service.ShopService.GetByID",
+		"GET /v1/shops/slug/:slug": "user_service.ShopService.GetBySlug",
+		"PUT /v1/branches/:id":     "user_service.BranchService.Update",
+		"DELETE /v1/customers/:id": "user_service.CustomerService.Delete",
+		"POST /v1/users":           "user_service.UsService.Create",
+		"GET /v1/sellers":          "user_service.SellerService.GetList",
+	} {
+		if got[route] != rpc {
+			t.Errorf("%s routes to %q, want %s", route, got[route], rpc)
+		}
+	}
+	for route, rpc := range got {
+		if rpc == "user_service.ShopService.Restore" || rpc == "user_service.BranchService.GetNearby" {
+			t.Errorf("%s routes to unannotated %s", route, rpc)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	routes, err := Routes("user_service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	route := func(method, path string) Route {
+		for _, r := range routes {
+			if r.Method == method && r.Path == path {
+				return r
+			}
+		}
+		t.Fatalf("no route %s %s", method, path)
+		return Route{}
+	}
+	params := func(values map[string]string) func(string) string {
+		return func(name string) string { return values[name] }
+	}
+
+	tests := []struct {
+		name    string
+		route   Route
+		params  map[string]string
+		query   string
+		want    proto.Message
+		wantErr bool
+	}{
+		{
+			name:   "path parameter",
+			route:  route("GET", "/v1/shops/:id"),
+			params: map[string]string{"id": "7"},
+			query:  "id=8&unknown=1",
+			want:   &pb.ShopPrimaryKey{Id: "7"},
+		},
+		{
+			name:  "query parameters",
+			route: route("GET", "/v1/shops"),
+			query: "limit=10&search=tea&include_deleted=true&onlyDeleted=1",
+			want:  &pb.GetListShopRequest{Limit: 10, Search: "tea", IncludeDeleted: true, OnlyDeleted: true},
+		},
+		{
+			name:    "invalid number",
+			route:   route("GET", "/v1/shops"),
+			query:   "limit=-1",
+			wantErr: true,
+		},
+		{
+			name:    "invalid bool",
+			route:   route("GET", "/v1/shops"),
+			query:   "include_deleted=maybe",
+			wantErr: true,
+		},
+		{
+			name:   "whole body",
+			route:  route("PUT", "/v1/shops/:id"),
+			params: map[string]string{"id": "7"},
+			query:  "slug=ignored",
+			want:   &pb.UpdateShop{Id: "7"},
+		},
+	}
+	for _, tt := range tests {
+		req, err := tt.route.NewRequest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		query, _ := url.ParseQuery(tt.query)
+		err = tt.route.Decode(req, params(tt.params), query)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Decode error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && !proto.Equal(req, tt.want) {
+			t.Errorf("%s: Decode = %v, want %v", tt.name, req, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package uuid
+
+import (
+	"crypto/rand"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+var v4 = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+func TestNew(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id, err := New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !v4.MatchString(id) {
+			t.Errorf("New() = %q, want a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Errorf("New() returned %q twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+func TestNewFails(t *testing.T) {
+	reader := rand.Reader
+	rand.Reader = failingReader{}
+	defer func() { rand.Reader = reader }()
+
+	if id, err := New(); err == nil {
+		t.Errorf("New() = %q with a failing random source, want an error", id)
+	}
+}
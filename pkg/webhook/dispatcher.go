@@ -0,0 +1,299 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"microservice/pkg/logger"
+	"microservice/pkg/uuid"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	storeTimeout = 5 * time.Second
+	// maxResponseBody is how much of an endpoint's response is read.
+	maxResponseBody = 64 << 10
+
+	// Headers sent with every delivery.
+	HeaderID        = "X-Webhook-ID"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Options ...
+type Options struct {
+	// Workers is the number of deliveries sent at the same time.
+	Workers int
+	// QueueSize is the number of deliveries that may wait for a worker;
+	// deliveries that do not fit are sent by the retry loop.
+	QueueSize int
+	// Timeout bounds one attempt.
+	Timeout time.Duration
+	// MaxAttempts is how many times a delivery is tried before it fails.
+	MaxAttempts int
+	// Backoff is the pause after the first failed attempt; it doubles after
+	// every further failure up to MaxBackoff.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// Dispatcher sends events to the endpoints that subscribed to them, signing
+// each request, and retries failed deliveries with exponential backoff.
+// Deliveries are persisted before they are sent, so RunRetries picks up
+// those interrupted by a restart.
+type Dispatcher struct {
+	store  Store
+	log    logger.Logger
+	opts   Options
+	client *http.Client
+	queue  chan Delivery
+}
+
+// NewDispatcher starts opts.Workers workers.
+func NewDispatcher(store Store, log logger.Logger, opts Options) *Dispatcher {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize < 0 {
+		opts.QueueSize = 0
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxAttempts < 1 {
+		opts.MaxAttempts = 1
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = 10 * time.Second
+	}
+	if opts.MaxBackoff < opts.Backoff {
+		opts.MaxBackoff = opts.Backoff
+	}
+
+	d := &Dispatcher{
+		store:  store,
+		log:    log,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		queue:  make(chan Delivery, opts.QueueSize),
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		go d.work()
+	}
+
+	return d
+}
+
+// Store ...
+func (d *Dispatcher) Store() Store {
+	return d.store
+}
+
+// lease is how long a queued or claimed delivery is left alone by the retry
+// loops of all instances.
+func (d *Dispatcher) lease() time.Duration {
+	return 2*d.opts.Timeout + time.Minute
+}
+
+// NewEvent returns an event of typ, such as shop.updated, about a resource.
+func NewEvent(typ, resourceType, resourceID string, object json.RawMessage, requestID string) (Event, error) {
+	id, err := uuid.New()
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:           id,
+		Type:         typ,
+		CreatedAt:    now(),
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Object:       object,
+		RequestID:    requestID,
+	}, nil
+}
+
+// Publish creates a delivery of ev for every active endpoint subscribed to
+// its type and queues them.
+func (d *Dispatcher) Publish(ctx context.Context, ev Event) error {
+	endpoints, err := d.store.ListEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range endpoints {
+		if !e.Active || !e.Matches(ev.Type) {
+			continue
+		}
+		if _, err := d.enqueue(ctx, e.ID, ev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redeliver sends the event of delivery id again as a new delivery.
+func (d *Dispatcher) Redeliver(ctx context.Context, id string) (Delivery, error) {
+	old, err := d.store.GetDelivery(ctx, id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if _, err := d.store.GetEndpoint(ctx, old.EndpointID); err != nil {
+		return Delivery{}, err
+	}
+
+	return d.enqueue(ctx, old.EndpointID, old.Event)
+}
+
+func (d *Dispatcher) enqueue(ctx context.Context, endpointID string, ev Event) (Delivery, error) {
+	id, err := uuid.New()
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	t := now()
+	delivery := Delivery{
+		ID:            id,
+		EndpointID:    endpointID,
+		Event:         ev,
+		Status:        DeliveryPending,
+		Attempts:      []Attempt{},
+		NextAttemptAt: t.Add(d.lease()),
+		CreatedAt:     t,
+		UpdatedAt:     t,
+	}
+	if err := d.store.CreateDelivery(ctx, delivery); err != nil {
+		return Delivery{}, err
+	}
+
+	select {
+	case d.queue <- delivery:
+	default:
+		// The retry loop sends it once the lease runs out.
+	}
+	return delivery, nil
+}
+
+func (d *Dispatcher) work() {
+	for delivery := range d.queue {
+		d.deliver(delivery)
+	}
+}
+
+// deliver makes one attempt and stores its outcome.
+func (d *Dispatcher) deliver(delivery Delivery) {
+	ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+	endpoint, err := d.store.GetEndpoint(ctx, delivery.EndpointID)
+	cancel()
+	if errors.Is(err, ErrNotFound) {
+		return
+	}
+	if err != nil {
+		d.log.Error("failed to load webhook endpoint", logger.String("delivery", delivery.ID), logger.Error(err))
+		return
+	}
+
+	attempt := Attempt{At: now()}
+	if endpoint.Active {
+		attempt.StatusCode, err = d.send(endpoint, delivery)
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+	} else {
+		attempt.Error = "endpoint is disabled"
+	}
+	attempt.DurationMS = time.Since(attempt.At).Milliseconds()
+
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.UpdatedAt = now()
+	switch {
+	case err == nil && endpoint.Active:
+		delivery.Status = DeliverySucceeded
+	case len(delivery.Attempts) >= d.opts.MaxAttempts || !endpoint.Active:
+		delivery.Status = DeliveryFailed
+		d.log.Warn("webhook delivery failed", logger.String("delivery", delivery.ID), logger.String("url", endpoint.URL), logger.String("error", attempt.Error))
+	default:
+		delivery.NextAttemptAt = delivery.UpdatedAt.Add(d.backoff(len(delivery.Attempts)))
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), storeTimeout)
+	defer cancel()
+	if err := d.store.UpdateDelivery(ctx, delivery); err != nil && !errors.Is(err, ErrNotFound) {
+		d.log.Error("failed to save webhook delivery", logger.String("delivery", delivery.ID), logger.Error(err))
+	}
+}
+
+// send POSTs the event and returns the response status; non-2xx statuses
+// are errors.
+func (d *Dispatcher) send(endpoint Endpoint, delivery Delivery) (int, error) {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, delivery.ID)
+	req.Header.Set(HeaderEvent, delivery.Event.Type)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxResponseBody))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff returns the pause after the n-th failed attempt, with 20% jitter
+// so that deliveries failing together do not retry together.
+func (d *Dispatcher) backoff(n int) time.Duration {
+	pause := d.opts.Backoff
+	for i := 1; i < n && pause < d.opts.MaxBackoff; i++ {
+		pause *= 2
+	}
+	if pause > d.opts.MaxBackoff {
+		pause = d.opts.MaxBackoff
+	}
+	return time.Duration(float64(pause) * (0.8 + 0.4*rand.Float64()))
+}
+
+// RunRetries queues the deliveries that are due every interval. It does not
+// return.
+func (d *Dispatcher) RunRetries(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		t := now()
+		due, err := d.store.ClaimDue(ctx, t, t.Add(d.lease()), cap(d.queue)+d.opts.Workers)
+		cancel()
+		if err != nil {
+			d.log.Error("failed to claim webhook deliveries", logger.Error(err))
+			continue
+		}
+
+		for _, delivery := range due {
+			d.queue <- delivery
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"microservice/pkg/logger"
+)
+
+func TestSign(t *testing.T) {
+	at := time.Unix(1767225600, 0)
+	got := Sign("whsec_test", at, []byte(`{"id":"1"}`))
+	if got != Sign("whsec_test", at, []byte(`{"id":"1"}`)) || len(got) != len("sha256=")+64 {
+		t.Fatalf("Sign = %q, want a stable sha256=<hex>", got)
+	}
+
+	for name, other := range map[string]string{
+		"secret":    Sign("whsec_other", at, []byte(`{"id":"1"}`)),
+		"timestamp": Sign("whsec_test", at.Add(time.Second), []byte(`{"id":"1"}`)),
+		"body":      Sign("whsec_test", at, []byte(`{"id":"2"}`)),
+	} {
+		if other == got {
+			t.Errorf("changing the %s does not change the signature", name)
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		events []string
+		typ    string
+		want   bool
+	}{
+		{[]string{"shop.created"}, "shop.created", true},
+		{[]string{"shop.created"}, "shop.updated", false},
+		{[]string{"shop.*"}, "shop.deleted", true},
+		{[]string{"shop.*"}, "seller.deleted", false},
+		{[]string{"seller.created", "*"}, "branch.purged", true},
+		{nil, "shop.created", false},
+	}
+
+	for _, tt := range tests {
+		if got := (Endpoint{Events: tt.events}).Matches(tt.typ); got != tt.want {
+			t.Errorf("%v matches %s = %v, want %v", tt.events, tt.typ, got, tt.want)
+		}
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) { return 0, errors.New("no entropy") }
+
+func TestRandomSourceFails(t *testing.T) {
+	reader := rand.Reader
+	rand.Reader = failingReader{}
+	defer func() { rand.Reader = reader }()
+
+	if _, err := NewEndpoint("http://example.com", []string{"*"}, ""); err == nil {
+		t.Error("NewEndpoint without a secret succeeded with a failing random source")
+	}
+	if _, err := NewEndpoint("http://example.com", []string{"*"}, "whsec_test"); err == nil {
+		t.Error("NewEndpoint succeeded with a failing random source")
+	}
+	if _, err := NewEvent("shop.created", "shop", "1", nil, ""); err == nil {
+		t.Error("NewEvent succeeded with a failing random source")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	d := &Dispatcher{opts: Options{Backoff: time.Second, MaxBackoff: 5 * time.Second}}
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 5 * time.Second},
+		{10, 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 20; i++ {
+			got := d.backoff(tt.attempts)
+			if got < tt.want*8/10 || got > tt.want*12/10 {
+				t.Errorf("backoff(%d) = %v, want %v ± 20%%", tt.attempts, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+// receiver is a webhook endpoint that verifies signatures and answers with
+// the next of its codes, then 200.
+type receiver struct {
+	*httptest.Server
+	secret string
+
+	mu       sync.Mutex
+	codes    []int
+	received []Event
+	ids      []string
+	invalid  int
+}
+
+func newReceiver(t *testing.T, secret string, codes ...int) *receiver {
+	r := &receiver{secret: secret, codes: codes}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) serve(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	unix, _ := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	want := Sign(r.secret, time.Unix(unix, 0), body)
+	if !hmac.Equal([]byte(req.Header.Get(HeaderSignature)), []byte(want)) {
+		r.invalid++
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var ev Event
+	json.Unmarshal(body, &ev)
+	r.received = append(r.received, ev)
+	r.ids = append(r.ids, req.Header.Get(HeaderID))
+
+	code := http.StatusOK
+	if len(r.codes) > 0 {
+		code, r.codes = r.codes[0], r.codes[1:]
+	}
+	w.WriteHeader(code)
+}
+
+func (r *receiver) count() int {
+	received, _ := r.stats()
+	return received
+}
+
+// stats returns how many deliveries were accepted and how many rejected.
+func (r *receiver) stats() (received, invalid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.received), r.invalid
+}
+
+func newTestDispatcher(t *testing.T, maxAttempts int) *Dispatcher {
+	d := NewDispatcher(NewMemoryStore(), logger.New(logger.LevelPanic, "test"), Options{
+		Workers:     1,
+		QueueSize:   10,
+		Timeout:     time.Second,
+		MaxAttempts: maxAttempts,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+	})
+	go d.RunRetries(5 * time.Millisecond)
+	return d
+}
+
+func newEndpoint(t *testing.T, url string, events []string, secret string) Endpoint {
+	t.Helper()
+	endpoint, err := NewEndpoint(url, events, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return endpoint
+}
+
+func newEvent(t *testing.T, typ, resourceType, resourceID string, object json.RawMessage, requestID string) Event {
+	t.Helper()
+	ev, err := NewEvent(typ, resourceType, resourceID, object, requestID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	tests := []struct {
+		name        string
+		codes       []int
+		maxAttempts int
+		status      DeliveryStatus
+		attempts    int
+	}{
+		{"first try", nil, 3, DeliverySucceeded, 1},
+		{"after retries", []int{500, 503}, 3, DeliverySucceeded, 3},
+		{"gives up", []int{500, 500, 500}, 2, DeliveryFailed, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			r := newReceiver(t, "whsec_test", tt.codes...)
+			d := newTestDispatcher(t, tt.maxAttempts)
+
+			endpoint := newEndpoint(t, r.URL, []string{"shop.*"}, "whsec_test")
+			if err := d.Store().CreateEndpoint(ctx, endpoint); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Publish(ctx, newEvent(t, "shop.created", "shop", "1", json.RawMessage(`{"id":"1"}`), "req-1")); err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Publish(ctx, newEvent(t, "seller.created", "seller", "1", nil, "req-2")); err != nil {
+				t.Fatal(err)
+			}
+
+			var delivery Delivery
+			waitFor(t, func() bool {
+				list, _ := d.Store().ListDeliveries(ctx, endpoint.ID, 10, 0)
+				if len(list) == 1 {
+					delivery = list[0]
+				}
+				return delivery.Status == tt.status
+			})
+			received, invalid := r.stats()
+			if len(delivery.Attempts) != tt.attempts || received != tt.attempts || invalid != 0 {
+				t.Errorf("%d attempts, %d received, %d badly signed; want %d, %d, 0", len(delivery.Attempts), received, invalid, tt.attempts, tt.attempts)
+			}
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			if ev := r.received[0]; ev.Type != "shop.created" || ev.ResourceID != "1" || ev.RequestID != "req-1" {
+				t.Errorf("received %+v, want the shop.created event", ev)
+			}
+		})
+	}
+}
+
+func TestWrongSecret(t *testing.T) {
+	ctx := context.Background()
+	r := newReceiver(t, "whsec_receiver")
+	d := newTestDispatcher(t, 1)
+
+	endpoint := newEndpoint(t, r.URL, []string{"*"}, "whsec_sender")
+	d.Store().CreateEndpoint(ctx, endpoint)
+	d.Publish(ctx, newEvent(t, "shop.created", "shop", "1", nil, ""))
+
+	waitFor(t, func() bool {
+		list, _ := d.Store().ListDeliveries(ctx, endpoint.ID, 10, 0)
+		return len(list) == 1 && list[0].Status == DeliveryFailed
+	})
+	if _, invalid := r.stats(); invalid != 1 {
+		t.Errorf("receiver rejected %d deliveries, want 1", invalid)
+	}
+}
+
+func TestRedeliver(t *testing.T) {
+	ctx := context.Background()
+	r := newReceiver(t, "whsec_test")
+	d := newTestDispatcher(t, 1)
+
+	endpoint := newEndpoint(t, r.URL, []string{"*"}, "whsec_test")
+	d.Store().CreateEndpoint(ctx, endpoint)
+	d.Publish(ctx, newEvent(t, "shop.updated", "shop", "1", nil, ""))
+	waitFor(t, func() bool { return r.count() == 1 })
+
+	list, _ := d.Store().ListDeliveries(ctx, endpoint.ID, 10, 0)
+	again, err := d.Redeliver(ctx, list[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return r.count() == 2 })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if again.ID == list[0].ID || r.ids[1] != again.ID || r.received[1].ID != r.received[0].ID {
+		t.Errorf("redelivery %s of %s sent as %s with event %s, want a new delivery of event %s", again.ID, list[0].ID, r.ids[1], r.received[1].ID, r.received[0].ID)
+	}
+
+	if _, err := d.Redeliver(ctx, "missing"); err != ErrNotFound {
+		t.Errorf("Redeliver(missing) = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps endpoints and deliveries in process memory; both are
+// lost on restart.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	endpoints  map[string]Endpoint
+	deliveries map[string]Delivery
+}
+
+// NewMemoryStore ...
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		endpoints:  make(map[string]Endpoint),
+		deliveries: make(map[string]Delivery),
+	}
+}
+
+// CreateEndpoint ...
+func (m *MemoryStore) CreateEndpoint(ctx context.Context, e Endpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoints[e.ID] = copyEndpoint(e)
+	return nil
+}
+
+// UpdateEndpoint ...
+func (m *MemoryStore) UpdateEndpoint(ctx context.Context, e Endpoint) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.endpoints[e.ID]; !ok {
+		return ErrNotFound
+	}
+	m.endpoints[e.ID] = copyEndpoint(e)
+	return nil
+}
+
+// DeleteEndpoint also deletes the endpoint's deliveries.
+func (m *MemoryStore) DeleteEndpoint(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.endpoints[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.endpoints, id)
+	for key, d := range m.deliveries {
+		if d.EndpointID == id {
+			delete(m.deliveries, key)
+		}
+	}
+	return nil
+}
+
+// GetEndpoint ...
+func (m *MemoryStore) GetEndpoint(ctx context.Context, id string) (Endpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	e, ok := m.endpoints[id]
+	if !ok {
+		return Endpoint{}, ErrNotFound
+	}
+	return copyEndpoint(e), nil
+}
+
+// ListEndpoints returns the endpoints oldest first.
+func (m *MemoryStore) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := make([]Endpoint, 0, len(m.endpoints))
+	for _, e := range m.endpoints {
+		list = append(list, copyEndpoint(e))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+// CreateDelivery ...
+func (m *MemoryStore) CreateDelivery(ctx context.Context, d Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.deliveries[d.ID] = copyDelivery(d)
+	return nil
+}
+
+// UpdateDelivery ...
+func (m *MemoryStore) UpdateDelivery(ctx context.Context, d Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.deliveries[d.ID]; !ok {
+		return ErrNotFound
+	}
+	m.deliveries[d.ID] = copyDelivery(d)
+	return nil
+}
+
+// GetDelivery ...
+func (m *MemoryStore) GetDelivery(ctx context.Context, id string) (Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	d, ok := m.deliveries[id]
+	if !ok {
+		return Delivery{}, ErrNotFound
+	}
+	return copyDelivery(d), nil
+}
+
+// ListDeliveries ...
+func (m *MemoryStore) ListDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	list := []Delivery{}
+	for _, d := range m.deliveries {
+		if d.EndpointID == endpointID {
+			list = append(list, d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+
+	if offset >= len(list) {
+		return []Delivery{}, nil
+	}
+	list = list[offset:]
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	for i := range list {
+		list[i] = copyDelivery(list[i])
+	}
+	return list, nil
+}
+
+// ClaimDue ...
+func (m *MemoryStore) ClaimDue(ctx context.Context, now, until time.Time, limit int) ([]Delivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []Delivery
+	for id, d := range m.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if d.Status != DeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.NextAttemptAt = until
+		m.deliveries[id] = d
+		due = append(due, copyDelivery(d))
+	}
+	return due, nil
+}
+
+func copyEndpoint(e Endpoint) Endpoint {
+	e.Events = append([]string(nil), e.Events...)
+	return e
+}
+
+func copyDelivery(d Delivery) Delivery {
+	d.Attempts = append([]Attempt(nil), d.Attempts...)
+	return d
+}
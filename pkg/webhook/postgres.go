@@ -0,0 +1,282 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS gateway_webhook_endpoints (
+	id          VARCHAR(36) PRIMARY KEY,
+	url         VARCHAR NOT NULL,
+	events      JSONB NOT NULL,
+	secret      VARCHAR NOT NULL,
+	description VARCHAR NOT NULL DEFAULT '',
+	active      BOOLEAN NOT NULL,
+	created_at  TIMESTAMPTZ NOT NULL,
+	updated_at  TIMESTAMPTZ NOT NULL
+);
+CREATE TABLE IF NOT EXISTS gateway_webhook_deliveries (
+	id              VARCHAR(36) PRIMARY KEY,
+	endpoint_id     VARCHAR(36) NOT NULL REFERENCES gateway_webhook_endpoints (id) ON DELETE CASCADE,
+	event           JSONB NOT NULL,
+	status          VARCHAR NOT NULL,
+	attempts        JSONB NOT NULL,
+	next_attempt_at TIMESTAMPTZ NOT NULL,
+	created_at      TIMESTAMPTZ NOT NULL,
+	updated_at      TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS gateway_webhook_deliveries_endpoint ON gateway_webhook_deliveries (endpoint_id, created_at);
+CREATE INDEX IF NOT EXISTS gateway_webhook_deliveries_due ON gateway_webhook_deliveries (status, next_attempt_at)`
+
+// PostgresStore keeps endpoints and deliveries in the gateway_webhook_*
+// tables, shared by every gateway instance.
+type PostgresStore struct {
+	db *pgxpool.Pool
+}
+
+// NewPostgresStore creates the gateway_webhook_* tables if they do not
+// exist.
+func NewPostgresStore(ctx context.Context, db *pgxpool.Pool) (*PostgresStore, error) {
+	if _, err := db.Exec(ctx, postgresSchema); err != nil {
+		return nil, fmt.Errorf("create gateway_webhook tables: %w", err)
+	}
+
+	return &PostgresStore{db: db}, nil
+}
+
+// CreateEndpoint ...
+func (p *PostgresStore) CreateEndpoint(ctx context.Context, e Endpoint) error {
+	events, err := json.Marshal(e.Events)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec(ctx, `
+		INSERT INTO gateway_webhook_endpoints (id, url, events, secret, description, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		e.ID, e.URL, string(events), e.Secret, e.Description, e.Active, e.CreatedAt, e.UpdatedAt,
+	)
+	return err
+}
+
+// UpdateEndpoint ...
+func (p *PostgresStore) UpdateEndpoint(ctx context.Context, e Endpoint) error {
+	events, err := json.Marshal(e.Events)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.db.Exec(ctx, `
+		UPDATE gateway_webhook_endpoints
+		SET url = $2, events = $3, secret = $4, description = $5, active = $6, updated_at = $7
+		WHERE id = $1`,
+		e.ID, e.URL, string(events), e.Secret, e.Description, e.Active, e.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteEndpoint also deletes the endpoint's deliveries.
+func (p *PostgresStore) DeleteEndpoint(ctx context.Context, id string) error {
+	tag, err := p.db.Exec(ctx, `DELETE FROM gateway_webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+const selectEndpoint = `SELECT id, url, events, secret, description, active, created_at, updated_at FROM gateway_webhook_endpoints`
+
+func scanEndpoint(row pgx.Row) (Endpoint, error) {
+	var (
+		e      Endpoint
+		events []byte
+	)
+
+	err := row.Scan(&e.ID, &e.URL, &events, &e.Secret, &e.Description, &e.Active, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	if err := json.Unmarshal(events, &e.Events); err != nil {
+		return Endpoint{}, err
+	}
+
+	e.CreatedAt = e.CreatedAt.UTC()
+	e.UpdatedAt = e.UpdatedAt.UTC()
+	return e, nil
+}
+
+// GetEndpoint ...
+func (p *PostgresStore) GetEndpoint(ctx context.Context, id string) (Endpoint, error) {
+	e, err := scanEndpoint(p.db.QueryRow(ctx, selectEndpoint+` WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Endpoint{}, ErrNotFound
+	}
+	return e, err
+}
+
+// ListEndpoints returns the endpoints oldest first.
+func (p *PostgresStore) ListEndpoints(ctx context.Context) ([]Endpoint, error) {
+	rows, err := p.db.Query(ctx, selectEndpoint+` ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Endpoint{}
+	for rows.Next() {
+		e, err := scanEndpoint(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// CreateDelivery ...
+func (p *PostgresStore) CreateDelivery(ctx context.Context, d Delivery) error {
+	event, attempts, err := marshalDelivery(d)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.db.Exec(ctx, `
+		INSERT INTO gateway_webhook_deliveries (id, endpoint_id, event, status, attempts, next_attempt_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		d.ID, d.EndpointID, event, d.Status, attempts, d.NextAttemptAt, d.CreatedAt, d.UpdatedAt,
+	)
+	return err
+}
+
+// UpdateDelivery ...
+func (p *PostgresStore) UpdateDelivery(ctx context.Context, d Delivery) error {
+	_, attempts, err := marshalDelivery(d)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.db.Exec(ctx, `
+		UPDATE gateway_webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, updated_at = $5
+		WHERE id = $1`,
+		d.ID, d.Status, attempts, d.NextAttemptAt, d.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func marshalDelivery(d Delivery) (string, string, error) {
+	event, err := json.Marshal(d.Event)
+	if err != nil {
+		return "", "", err
+	}
+	if d.Attempts == nil {
+		d.Attempts = []Attempt{}
+	}
+	attempts, err := json.Marshal(d.Attempts)
+	if err != nil {
+		return "", "", err
+	}
+	return string(event), string(attempts), nil
+}
+
+const selectDelivery = `SELECT id, endpoint_id, event, status, attempts, next_attempt_at, created_at, updated_at FROM gateway_webhook_deliveries`
+
+func scanDelivery(row pgx.Row) (Delivery, error) {
+	var (
+		d               Delivery
+		event, attempts []byte
+	)
+
+	err := row.Scan(&d.ID, &d.EndpointID, &event, &d.Status, &attempts, &d.NextAttemptAt, &d.CreatedAt, &d.UpdatedAt)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if err := json.Unmarshal(event, &d.Event); err != nil {
+		return Delivery{}, err
+	}
+	if err := json.Unmarshal(attempts, &d.Attempts); err != nil {
+		return Delivery{}, err
+	}
+
+	d.NextAttemptAt = d.NextAttemptAt.UTC()
+	d.CreatedAt = d.CreatedAt.UTC()
+	d.UpdatedAt = d.UpdatedAt.UTC()
+	return d, nil
+}
+
+func scanDeliveries(rows pgx.Rows) ([]Delivery, error) {
+	defer rows.Close()
+
+	list := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+// GetDelivery ...
+func (p *PostgresStore) GetDelivery(ctx context.Context, id string) (Delivery, error) {
+	d, err := scanDelivery(p.db.QueryRow(ctx, selectDelivery+` WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Delivery{}, ErrNotFound
+	}
+	return d, err
+}
+
+// ListDeliveries ...
+func (p *PostgresStore) ListDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]Delivery, error) {
+	rows, err := p.db.Query(ctx, selectDelivery+` WHERE endpoint_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3`,
+		endpointID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
+
+// ClaimDue ...
+func (p *PostgresStore) ClaimDue(ctx context.Context, now, until time.Time, limit int) ([]Delivery, error) {
+	rows, err := p.db.Query(ctx, `
+		UPDATE gateway_webhook_deliveries
+		SET next_attempt_at = $3
+		WHERE id IN (
+			SELECT id FROM gateway_webhook_deliveries
+			WHERE status = $1 AND next_attempt_at <= $2
+			ORDER BY next_attempt_at
+			LIMIT $4
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, endpoint_id, event, status, attempts, next_attempt_at, created_at, updated_at`,
+		DeliveryPending, now, until, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanDeliveries(rows)
+}
@@ -0,0 +1,153 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"microservice/pkg/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for unknown endpoint and delivery IDs.
+	ErrNotFound = errors.New("webhook not found")
+)
+
+// Endpoint is a URL registered to receive events. Events holds event types
+// such as customer.created; "shop.*" matches every shop event and "*" every
+// event. Secret signs the deliveries and is only returned on creation.
+type Endpoint struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Matches reports whether the endpoint wants events of type typ.
+func (e Endpoint) Matches(typ string) bool {
+	resource, _, _ := strings.Cut(typ, ".")
+	for _, pattern := range e.Events {
+		if pattern == "*" || pattern == typ || pattern == resource+".*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a change to a resource. Object is the resource after the change,
+// or before it for deletions; it is empty when the resource could not be
+// read.
+type Event struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Object       json.RawMessage `json:"object,omitempty" swaggertype:"object"`
+	RequestID    string          `json:"request_id,omitempty"`
+}
+
+// DeliveryStatus ...
+type DeliveryStatus string
+
+const (
+	// DeliveryPending means the event is still to be sent, possibly again.
+	DeliveryPending DeliveryStatus = "pending"
+	// DeliverySucceeded means the endpoint answered 2xx.
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	// DeliveryFailed means every attempt failed.
+	DeliveryFailed DeliveryStatus = "failed"
+)
+
+// Attempt is one POST of a delivery. StatusCode is zero when no response
+// was received.
+type Attempt struct {
+	At         time.Time `json:"at"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+}
+
+// Delivery is the sending of one event to one endpoint.
+type Delivery struct {
+	ID            string         `json:"id"`
+	EndpointID    string         `json:"endpoint_id"`
+	Event         Event          `json:"event"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      []Attempt      `json:"attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// Store keeps endpoints and deliveries.
+type Store interface {
+	CreateEndpoint(ctx context.Context, e Endpoint) error
+	UpdateEndpoint(ctx context.Context, e Endpoint) error
+	DeleteEndpoint(ctx context.Context, id string) error
+	GetEndpoint(ctx context.Context, id string) (Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]Endpoint, error)
+
+	CreateDelivery(ctx context.Context, d Delivery) error
+	UpdateDelivery(ctx context.Context, d Delivery) error
+	GetDelivery(ctx context.Context, id string) (Delivery, error)
+	// ListDeliveries returns the deliveries of an endpoint, newest first.
+	ListDeliveries(ctx context.Context, endpointID string, limit, offset int) ([]Delivery, error)
+	// ClaimDue returns up to limit pending deliveries whose NextAttemptAt
+	// has passed, moving their NextAttemptAt to until so that no other
+	// instance picks them up meanwhile.
+	ClaimDue(ctx context.Context, now, until time.Time, limit int) ([]Delivery, error)
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp:
+// the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with secret.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	var b [32]byte
+	if _, err := io.ReadFull(rand.Reader, b[:]); err != nil {
+		return "", fmt.Errorf("webhook: reading random bytes: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b[:]), nil
+}
+
+// NewEndpoint returns an endpoint with a new ID and, when secret is empty, a
+// generated secret.
+func NewEndpoint(url string, events []string, secret string) (Endpoint, error) {
+	var err error
+	if secret == "" {
+		if secret, err = NewSecret(); err != nil {
+			return Endpoint{}, err
+		}
+	}
+	id, err := uuid.New()
+	if err != nil {
+		return Endpoint{}, err
+	}
+	t := now()
+	return Endpoint{ID: id, URL: url, Events: events, Secret: secret, Active: true, CreatedAt: t, UpdatedAt: t}, nil
+}
+
+// now is truncated to what Postgres stores.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}